@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	_ "time/tzdata" // fusos embutidos para imagens sem /usr/share/zoneinfo
+
+	"github.com/casarotto/binance-bot/internal/report"
+	traderbot "github.com/casarotto/binance-bot/internal/trader-bot"
+)
+
+// runExport implementa o subcomando "export": trades ou baixas por lote (FIFO/custo médio)
+// em CSV ou JSON, com datas no fuso escolhido e valores convertidos pela cotação armazenada
+func runExport(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	historyFile := fs.String("history", filepath.Join("history", "trade_history.json"), "Arquivo de histórico de trades")
+	format := fs.String("format", "csv", "Formato da saída: csv ou json")
+	output := fs.String("out", "", "Arquivo de saída (padrão: stdout)")
+	since := fs.String("since", "", "Início do período (AAAA-MM-DD, RFC3339 ou timestamp Unix)")
+	until := fs.String("until", "", "Fim do período (AAAA-MM-DD, RFC3339 ou timestamp Unix)")
+	tz := fs.String("tz", "America/Sao_Paulo", "Fuso das datas exportadas e do período")
+	feeRate := fs.Float64("fee", report.DefaultOptions().FeeRate, "Taxa por execução (0.001 = 0.1%)")
+	lots := fs.String("lots", "", "Relatório de baixas por lote: fifo ou average (vazio = exporta os trades)")
+	currency := fs.String("currency", "", "Moeda de apuração das baixas, ex.: BRL (requer -rates)")
+	ratesFile := fs.String("rates", "", "CSV de cotações USDT -> moeda (AAAA-MM-DD,cotação); padrão history/rates_<moeda>.csv")
+	fs.Parse(args)
+
+	if *format != "csv" && *format != "json" {
+		log.Fatalf("❌ -format inválido: %s (use csv ou json)", *format)
+	}
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		log.Fatalf("❌ -tz inválido: %v", err)
+	}
+	from, err := parseReportTime(*since, false, loc)
+	if err != nil {
+		log.Fatalf("❌ -since inválido: %v", err)
+	}
+	to, err := parseReportTime(*until, true, loc)
+	if err != nil {
+		log.Fatalf("❌ -until inválido: %v", err)
+	}
+
+	trades, err := traderbot.LoadTradeHistory(*historyFile)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("❌ Erro ao criar %s: %v", *output, err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if *lots == "" {
+		exported := report.ExportTrades(trades, from, to, loc, *feeRate)
+		if *format == "json" {
+			err = report.WriteJSON(out, exported)
+		} else {
+			err = report.WriteTradesCSV(out, exported)
+		}
+		if err != nil {
+			log.Fatalf("❌ Erro ao exportar: %v", err)
+		}
+		return
+	}
+
+	opts := report.LotOptions{
+		Method:   *lots,
+		FeeRate:  *feeRate,
+		Since:    from,
+		Until:    to,
+		Location: loc,
+		Currency: strings.ToUpper(*currency),
+	}
+	if opts.Currency != "" {
+		path := *ratesFile
+		if path == "" {
+			path = filepath.Join("history", "rates_"+opts.Currency+".csv")
+		}
+		if opts.Rates, err = report.LoadRates(path); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+	}
+
+	disposals, err := report.MatchLots(trades, opts)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if *format == "json" {
+		err = report.WriteJSON(out, disposals)
+	} else {
+		err = report.WriteLotsCSV(out, disposals, opts.Currency)
+	}
+	if err != nil {
+		log.Fatalf("❌ Erro ao exportar: %v", err)
+	}
+}
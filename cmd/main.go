@@ -1,23 +1,64 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/casarotto/binance-bot/internal/api"
 	"github.com/casarotto/binance-bot/internal/config"
+	"github.com/casarotto/binance-bot/internal/notify"
+	"github.com/casarotto/binance-bot/internal/remote"
 	traderbot "github.com/casarotto/binance-bot/internal/trader-bot"
 	"github.com/casarotto/binance-bot/internal/tui"
+	"github.com/casarotto/binance-bot/internal/webhook"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 func main() {
+	// Subcomandos
+	if len(os.Args) > 1 && os.Args[1] == "report" {
+		runReport(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		runExport(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		runReplay(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "optimize" {
+		runOptimize(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "montecarlo" {
+		runMonteCarlo(os.Args[2:])
+		return
+	}
+
 	// Flags de linha de comando
 	envPath := flag.String("env", ".env", "Caminho para o arquivo .env")
+	headless := flag.Bool("headless", false, "Executar sem a TUI (systemd, containers)")
+	connect := flag.String("connect", "", "Abrir a TUI conectada a um bot em execução (unix:/caminho.sock ou host:porta)")
+	token := flag.String("token", os.Getenv("REMOTE_TOKEN"), "Token da TUI remota (padrão: REMOTE_TOKEN)")
+	notifyTest := flag.Bool("notify-test", false, "Enviar um aviso de teste para os canais configurados e sair")
 	flag.Parse()
 
+	if *connect != "" {
+		runRemoteTUI(*connect, *token)
+		return
+	}
+
 	// Carregar configurações
 	cfg, err := config.LoadFromEnv(*envPath)
 	if err != nil {
@@ -37,6 +78,16 @@ Você pode:
 		os.Exit(1)
 	}
 
+	// Canais de aviso (webhook, e-mail, Telegram)
+	notifier, err := notify.New(cfg.Notify)
+	if err != nil {
+		log.Fatalf("❌ Erro na configuração dos avisos: %v", err)
+	}
+	if *notifyTest {
+		runNotifyTest(notifier)
+		return
+	}
+
 	// Criar diretório para histórico e logs
 	historyDir := "history"
 	if err := os.MkdirAll(historyDir, 0755); err != nil {
@@ -44,7 +95,7 @@ Você pode:
 	}
 
 	// Configurar logger
-	logger, err := traderbot.NewLogger(historyDir)
+	logger, err := traderbot.NewLogger(historyDir, cfg.Log)
 	if err != nil {
 		log.Fatal("Erro ao criar logger:", err)
 	}
@@ -59,34 +110,79 @@ Você pode:
 		log.Fatalf("Erro ao converter RISK_PER_TRADE: %v", err)
 	}
 
+	// Compilar as regras antes de conectar, para que erros apareçam na inicialização
+	var ruleStrategy *traderbot.RuleStrategy
+	if cfg.Strategy == "rules" {
+		ruleStrategy, err = traderbot.CompileRuleStrategy(cfg.Rules.Entry, cfg.Rules.Exit)
+		if err != nil {
+			log.Fatalf("❌ Erro nas regras da estratégia: %v", err)
+		}
+		if err := ruleStrategy.CheckCandleCapacity(cfg.SignalInterval, cfg.CandleCapacity); err != nil {
+			log.Fatalf("❌ Erro nas regras da estratégia: %v", err)
+		}
+	}
+
 	// Criar o trader
 	trader := traderbot.NewBTCTrader(
-		os.Getenv("BINANCE_API_KEY"),
-		os.Getenv("BINANCE_API_SECRET"),
-		os.Getenv("USE_TESTNET") == "true",
+		cfg.ApiKey,
+		cfg.ApiSecret,
+		cfg.Testnet,
 		historyFile,
 		riskPerTrade,
+		cfg.CandleCapacity,
 	)
 
 	// Configurar o logger do trader
 	trader.SetLogger(logger)
 
+	// Marcações periódicas do patrimônio (EQUITY_SNAPSHOT_INTERVAL)
+	trader.SetEquityTracking(filepath.Join(historyDir, "equity.jsonl"), cfg.EquityInterval)
+
+	// Configurar os avisos; falhas de entrega só vão para o log
+	notifier.SetErrorHandler(func(err error) { logger.Warnf("⚠️ %v", err) })
+	trader.SetNotifier(notifier)
+	defer closeNotifier(notifier)
+
+	// Configurar DCA, grid, timeframes e regras da estratégia
+	configureTrader(trader, cfg, ruleStrategy)
+
+	// Gravação dos streams de mercado para replay (RECORD_DIR)
+	if recorder := startRecorder(cfg, trader, logger); recorder != nil {
+		defer recorder.Close()
+	}
+
+	if *headless {
+		runHeadless(trader, logger, cfg)
+		return
+	}
+
 	// Criar e iniciar o TUI para configuração inicial
 	configModel := tui.NewConfigModel(trader)
 	configProgram := tea.NewProgram(configModel)
 	if err := configProgram.Start(); err != nil {
-		logger.Fatal("Erro ao iniciar configuração:", err)
+		logger.Fatal("Erro ao iniciar configuração", "erro", err)
 	}
 
-	// Iniciar o trader em uma goroutine separada
-	go func() {
-		if err := trader.Start(); err != nil {
-			logger.Fatal(err)
-		}
-	}()
+	// Iniciar o trader em uma goroutine separada, reiniciando-o se cair
+	go trader.Run(context.Background())
+	go trader.RecordEquity(context.Background())
+	go trader.SyncClock(context.Background())
+
+	// API HTTP de controle e status (API_ADDR)
+	startAPI(cfg, trader, logger)
+
+	// Servidor da TUI remota (REMOTE_ADDR)
+	startRemote(context.Background(), cfg, trader, logger)
+
+	// Métricas do Prometheus (METRICS_ADDR)
+	startMetrics(cfg, trader, logger)
+
+	// Webhook de sinais externos (SIGNAL_WEBHOOK_ADDR)
+	startWebhook(cfg, trader, logger)
 
 	// Criar e iniciar o TUI principal
 	model := tui.New(trader)
+	model.SetLogLevel(cfg.Log.PanelLevel)
 	p := tea.NewProgram(
 		model,
 		tea.WithAltScreen(),       // Usar tela alternativa
@@ -94,6 +190,191 @@ Você pode:
 	)
 	
 	if err := p.Start(); err != nil {
-		logger.Fatal("Erro ao iniciar TUI:", err)
+		logger.Fatal("Erro ao iniciar TUI", "erro", err)
+	}
+}
+
+// configureTrader aplica ao trader a configuração da estratégia
+func configureTrader(trader *traderbot.BTCTrader, cfg *config.Config, ruleStrategy *traderbot.RuleStrategy) {
+	// Parâmetros da estratégia rsi_ma e do stop loss
+	trader.SetSignalParams(cfg.Signal)
+
+	// Configurar o modo DCA (preço médio com ordens de segurança)
+	trader.SetDCAConfig(cfg.DCA)
+
+	// Configurar a estratégia de grid (STRATEGY=grid)
+	trader.SetGridConfig(cfg.Grid)
+
+	// Configurar o intervalo dos sinais e a confirmação em timeframes maiores
+	trader.SetTimeframes(cfg.SignalInterval, cfg.Confirmations)
+
+	// Limites de requisições REST da Binance (peso por minuto e ordens a cada 10s)
+	trader.SetRateLimits(cfg.RateLimit)
+
+	// Sincronizar o relógio com a Binance e definir o recvWindow das requisições assinadas
+	trader.SetClockConfig(cfg.Clock)
+
+	// Novas tentativas das ordens sem resposta definitiva (timeout, erro de rede)
+	trader.SetOrderRetry(cfg.Retry)
+
+	// Circuit breaker que suspende as compras após falhas seguidas no envio de ordens
+	trader.SetBreakerConfig(cfg.Breaker)
+
+	// Configurar o livro de ofertas e o filtro de liquidez das entradas
+	trader.SetDepthConfig(cfg.Depth)
+
+	// Configurar os indicadores de fluxo de ordens (aggTrade)
+	trader.SetOrderFlowConfig(cfg.Flow)
+
+	// Configurar a estratégia por regras (STRATEGY=rules)
+	if ruleStrategy != nil {
+		trader.SetStrategy(ruleStrategy)
+	}
+}
+
+// runHeadless executa o trader sem terminal: a posição inicial vem da configuração
+// (ou da reconciliação com a conta), os logs vão para o stdout em JSON e o processo
+// termina de forma limpa com SIGINT/SIGTERM
+func runHeadless(trader *traderbot.BTCTrader, logger *traderbot.Logger, cfg *config.Config) {
+	logger.EnableStdout()
+
+	if err := trader.ApplyInitialPosition(cfg.InitialPosition, cfg.InitialEntryPrice, cfg.InitialQuantity); err != nil {
+		logger.Errorf("❌ Erro ao definir a posição inicial: %v", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	server := startAPI(cfg, trader, logger)
+	startRemote(ctx, cfg, trader, logger)
+	startMetrics(cfg, trader, logger)
+	startWebhook(cfg, trader, logger)
+
+	logger.Infof("🚀 Iniciando em modo headless (estratégia %s, intervalo %s)", cfg.Strategy, cfg.SignalInterval)
+	go trader.RecordEquity(ctx)
+	go trader.SyncClock(ctx)
+	trader.Run(ctx)
+
+	if server != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}
+}
+
+// runNotifyTest envia um aviso de teste para cada canal configurado
+func runNotifyTest(notifier *notify.Notifier) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := notifier.Test(ctx); err != nil {
+		log.Fatalf("❌ Falha no envio do aviso de teste:\n%v", err)
+	}
+	log.Printf("✅ Aviso de teste enviado para: %s", strings.Join(notifier.Sinks(), ", "))
+}
+
+// closeNotifier espera a entrega dos avisos pendentes antes de sair
+func closeNotifier(notifier *notify.Notifier) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	notifier.Close(ctx)
+}
+
+// startAPI inicia a API HTTP de controle se API_ADDR estiver definido
+func startAPI(cfg *config.Config, trader *traderbot.BTCTrader, logger *traderbot.Logger) *api.Server {
+	if cfg.API.Addr == "" {
+		return nil
+	}
+
+	server := api.NewServer(cfg.API.Addr, cfg.API.Token, trader, logger)
+	go func() {
+		if err := server.ListenAndServe(); err != nil {
+			logger.Errorf("❌ Erro na API HTTP: %v", err)
+		}
+	}()
+	logger.Infof("🌐 API HTTP escutando em %s", cfg.API.Addr)
+	return server
+}
+
+// startRemote inicia o servidor da TUI remota se REMOTE_ADDR estiver definido
+func startRemote(ctx context.Context, cfg *config.Config, trader *traderbot.BTCTrader, logger *traderbot.Logger) {
+	if cfg.Remote.Addr == "" {
+		return
+	}
+
+	server := remote.NewServer(cfg.Remote.Addr, cfg.Remote.Token, trader, logger)
+	go func() {
+		if err := server.Serve(ctx); err != nil {
+			logger.Errorf("❌ Erro no servidor da TUI remota: %v", err)
+		}
+	}()
+	logger.Infof("🖥️ TUI remota disponível em %s", cfg.Remote.Addr)
+}
+
+// startMetrics expõe /metrics para o Prometheus se METRICS_ADDR estiver definido
+func startMetrics(cfg *config.Config, trader *traderbot.BTCTrader, logger *traderbot.Logger) {
+	if cfg.MetricsAddr == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", trader.GetMetrics().Handler())
+	go func() {
+		if err := http.ListenAndServe(cfg.MetricsAddr, mux); err != nil {
+			logger.Errorf("❌ Erro no endpoint de métricas: %v", err)
+		}
+	}()
+	logger.Infof("📈 Métricas disponíveis em http://%s/metrics", cfg.MetricsAddr)
+}
+
+// startRecorder grava os klines do trader e os streams de RECORD_STREAMS se RECORD_DIR estiver definido
+func startRecorder(cfg *config.Config, trader *traderbot.BTCTrader, logger *traderbot.Logger) *traderbot.Recorder {
+	if cfg.Record.Dir == "" {
+		return nil
+	}
+
+	recorder, err := traderbot.NewRecorder(cfg.Record.Dir)
+	if err != nil {
+		logger.Errorf("❌ Gravação desabilitada: %v", err)
+		return nil
+	}
+	trader.SetRecorder(recorder)
+	trader.RecordMarketStreams(context.Background(), cfg.Record.Streams)
+	logger.Infof("⏺️ Gravando streams de mercado em %s", recorder.Path())
+	return recorder
+}
+
+// startWebhook inicia o webhook de sinais externos se SIGNAL_WEBHOOK_ADDR estiver definido
+func startWebhook(cfg *config.Config, trader *traderbot.BTCTrader, logger *traderbot.Logger) {
+	if cfg.Webhook.Addr == "" {
+		return
+	}
+
+	server := webhook.NewServer(cfg.Webhook.Addr, cfg.Webhook.Token, cfg.Webhook.MaxSizePct, trader, logger)
+	go func() {
+		if err := server.ListenAndServe(); err != nil {
+			logger.Errorf("❌ Erro no webhook de sinais: %v", err)
+		}
+	}()
+	logger.Infof("📡 Webhook de sinais escutando em http://%s/signal", cfg.Webhook.Addr)
+}
+
+// runRemoteTUI abre a TUI conectada a um bot em execução. Sair da TUI só encerra
+// a conexão; o bot continua operando.
+func runRemoteTUI(addr, token string) {
+	client, err := remote.Dial(addr, token)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	defer client.Close()
+
+	p := tea.NewProgram(
+		tui.New(client),
+		tea.WithAltScreen(),
+		tea.WithMouseCellMotion(),
+	)
+	if err := p.Start(); err != nil {
+		log.Fatalf("Erro ao iniciar TUI: %v", err)
 	}
 }
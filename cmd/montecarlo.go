@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/casarotto/binance-bot/internal/report"
+	traderbot "github.com/casarotto/binance-bot/internal/trader-bot"
+	"github.com/joho/godotenv"
+)
+
+// runMonteCarlo implementa o subcomando "montecarlo": reamostra as operações do histórico
+// (real, do replay ou de um backtest) para estimar a distribuição dos resultados
+func runMonteCarlo(args []string) {
+	opts := report.DefaultMonteCarloOptions()
+
+	fs := flag.NewFlagSet("montecarlo", flag.ExitOnError)
+	envPath := fs.String("env", ".env", "Arquivo .env de onde vem RISK_PER_TRADE quando -risk não é informado")
+	historyFile := fs.String("history", filepath.Join("history", "trade_history.json"), "Arquivo de histórico de trades")
+	since := fs.String("since", "", "Início do período (AAAA-MM-DD, RFC3339 ou timestamp Unix)")
+	until := fs.String("until", "", "Fim do período (AAAA-MM-DD, RFC3339 ou timestamp Unix)")
+	risks := fs.String("risk", "", "Frações do patrimônio por operação, separadas por vírgula (ex. 0.1,0.25,0.5)")
+	fee := fs.Float64("fee", 0.001, "Taxa por execução (0.001 = 0.1%)")
+	fs.IntVar(&opts.Iterations, "iterations", opts.Iterations, "Número de simulações")
+	fs.StringVar(&opts.Mode, "mode", opts.Mode, "Reamostragem: bootstrap (com reposição) ou shuffle (só a ordem)")
+	fs.Float64Var(&opts.Equity, "equity", opts.Equity, "Patrimônio inicial em USDT")
+	fs.Float64Var(&opts.EntryJitter, "jitter", opts.EntryJitter, "Desvio padrão do erro no preço de entrada (0.0005 = 5 bps)")
+	fs.Float64Var(&opts.Ruin, "ruin", opts.Ruin, "Queda do patrimônio inicial considerada ruína (0.5 = 50%)")
+	fs.Int64Var(&opts.Seed, "seed", opts.Seed, "Semente das simulações")
+	fs.Parse(args)
+
+	if *risks == "" {
+		// O .env é opcional aqui: sem ele, -risk é obrigatório
+		godotenv.Load(*envPath)
+		*risks = os.Getenv("RISK_PER_TRADE")
+		if *risks == "" {
+			log.Fatalf("❌ Informe o risco com -risk ou defina RISK_PER_TRADE")
+		}
+	}
+	var levels []float64
+	for _, value := range strings.Split(*risks, ",") {
+		risk, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			log.Fatalf("❌ -risk inválido: %q", value)
+		}
+		levels = append(levels, risk)
+	}
+
+	sinceTime, err := parseReportTime(*since, false, time.Local)
+	if err != nil {
+		log.Fatalf("❌ -since inválido: %v", err)
+	}
+	untilTime, err := parseReportTime(*until, true, time.Local)
+	if err != nil {
+		log.Fatalf("❌ -until inválido: %v", err)
+	}
+
+	trades, err := traderbot.LoadTradeHistory(*historyFile)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	returns := report.TradeReturns(trades, sinceTime, untilTime, *fee)
+
+	for i, risk := range levels {
+		opts.Risk = risk
+		result, err := report.MonteCarlo(returns, opts)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(result)
+	}
+}
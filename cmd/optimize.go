@@ -0,0 +1,194 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/casarotto/binance-bot/internal/backtest"
+	"github.com/casarotto/binance-bot/internal/config"
+	traderbot "github.com/casarotto/binance-bot/internal/trader-bot"
+)
+
+// runOptimize implementa o subcomando "optimize": backtests em paralelo dos parâmetros
+// da estratégia rsi_ma sobre dados históricos, ordenados pelo objetivo escolhido
+func runOptimize(args []string) {
+	fs := flag.NewFlagSet("optimize", flag.ExitOnError)
+	envPath := fs.String("env", ".env", "Caminho para o arquivo .env (configuração base da estratégia)")
+	data := fs.String("data", "", "Dados históricos: CSV de klines da Binance ou gravação .jsonl.gz")
+	interval := fs.String("interval", "", "Intervalo dos candles (padrão: SIGNAL_INTERVAL)")
+	ranges := map[string]*string{
+		"rsi-period": fs.String("rsi-period", "", "Período do RSI: valor ou min:max:step"),
+		"rsi-buy":    fs.String("rsi-buy", "", "RSI de compra: valor ou min:max:step (ex. 20:40:5)"),
+		"rsi-sell":   fs.String("rsi-sell", "", "RSI de venda: valor ou min:max:step (ex. 60:80:5)"),
+		"ma-short":   fs.String("ma-short", "", "Média curta: valor ou min:max:step"),
+		"ma-long":    fs.String("ma-long", "", "Média longa: valor ou min:max:step"),
+		"stop":       fs.String("stop", "", "Stop loss: valor ou min:max:step (ex. 0.01:0.05:0.01)"),
+		"min-profit": fs.String("min-profit", "", "Lucro mínimo em %: valor ou min:max:step"),
+	}
+	mode := fs.String("search", backtest.SearchGrid, "Busca: grid (todas as combinações) ou random")
+	samples := fs.Int("samples", 200, "Combinações sorteadas na busca random")
+	seed := fs.Int64("seed", 1, "Semente da busca random")
+	objective := fs.String("objective", backtest.ObjectiveProfit, "Objetivo: profit, sharpe ou return_dd")
+	workers := fs.Int("workers", runtime.NumCPU(), "Backtests em paralelo")
+	top := fs.Int("top", 10, "Resultados exibidos")
+	folds := fs.Int("folds", 0, "Folds do walk-forward (0 = otimizar no período inteiro)")
+	isChunks := fs.Int("is-chunks", 3, "Blocos in-sample por bloco out-of-sample no walk-forward")
+	usdt := fs.Float64("usdt", 1000, "Saldo inicial em USDT")
+	fee := fs.Float64("fee", 0, "Taxa por execução, substitui PAPER_MAKER_FEE e PAPER_TAKER_FEE (0.001 = 0.1%)")
+	fs.Parse(args)
+
+	if *data == "" {
+		log.Fatalf("❌ Informe os dados históricos com -data")
+	}
+	if err := backtest.ValidateObjective(*objective); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	cfg, err := config.LoadFromEnv(*envPath)
+	if err != nil {
+		log.Fatalf("❌ Erro ao carregar configurações: %v", err)
+	}
+	if cfg.Strategy == "rules" || cfg.Grid.Enabled {
+		log.Fatalf("❌ O optimize ajusta os parâmetros da estratégia rsi_ma; use STRATEGY=rsi_ma no .env")
+	}
+	riskPerTrade, err := strconv.ParseFloat(os.Getenv("RISK_PER_TRADE"), 64)
+	if err != nil {
+		log.Fatalf("Erro ao converter RISK_PER_TRADE: %v", err)
+	}
+	if *interval == "" {
+		*interval = cfg.SignalInterval
+	}
+	// Os sinais são avaliados no intervalo dos candles históricos
+	cfg.SignalInterval = *interval
+
+	search := backtest.Search{Mode: *mode, Samples: *samples, Seed: *seed, Workers: *workers}
+	targets := map[string]*backtest.Range{
+		"rsi-period": &search.Space.RSIPeriod,
+		"rsi-buy":    &search.Space.RSIBuy,
+		"rsi-sell":   &search.Space.RSISell,
+		"ma-short":   &search.Space.MAShort,
+		"ma-long":    &search.Space.MALong,
+		"stop":       &search.Space.StopLoss,
+		"min-profit": &search.Space.MinProfit,
+	}
+	for name, value := range ranges {
+		if *targets[name], err = backtest.ParseRange(*value); err != nil {
+			log.Fatalf("❌ -%s: %v", name, err)
+		}
+	}
+
+	candidates, err := search.Candidates(cfg.Signal)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	candles, err := backtest.LoadCandles(*data, *interval)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	warmup := backtest.Warmup(candidates)
+	opts := backtest.Options{
+		Interval:       *interval,
+		CandleCapacity: max(cfg.CandleCapacity, warmup+1),
+		InitialUSDT:    *usdt,
+		Execution:      paperConfig(fs, cfg, *fee),
+		RiskPerTrade:   riskPerTrade,
+		Objective:      *objective,
+		Configure: func(trader *traderbot.BTCTrader) {
+			configureTrader(trader, cfg, nil)
+		},
+	}
+
+	fmt.Printf("📊 %d candles de %s (%s a %s), %d combinações, %d workers, objetivo %s\n",
+		len(candles), *interval,
+		time.UnixMilli(candles[0].OpenTime).Format("2006-01-02 15:04"), time.UnixMilli(candles[len(candles)-1].CloseTime).Format("2006-01-02 15:04"),
+		len(candidates), *workers, *objective)
+
+	// Os traders criados a cada backtest registram a inicialização no log padrão
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	search.Progress = func(done, total int) {
+		fmt.Fprintf(os.Stderr, "\r⏳ %d/%d backtests", done, total)
+		if done == total {
+			fmt.Fprintln(os.Stderr)
+		}
+	}
+
+	if *folds > 0 {
+		results, err := search.WalkForward(candles, cfg.Signal, opts, *folds, *isChunks, warmup)
+		if err != nil {
+			log.SetOutput(os.Stderr)
+			log.Fatalf("❌ %v", err)
+		}
+		printWalkForward(results)
+		return
+	}
+
+	results, err := search.Optimize(candles, warmup, candidates, opts)
+	if err != nil {
+		log.SetOutput(os.Stderr)
+		log.Fatalf("❌ %v", err)
+	}
+	printRanking(results, *top)
+}
+
+func printRanking(results []backtest.Result, top int) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "#\tRSI\tBUY\tSELL\tMA\tSTOP\tMIN%\tSELLS\tWIN%\tPROFIT\tRET%\tDD%\tSHARPE\tOBJ\t")
+	for i, r := range results {
+		if i >= top {
+			break
+		}
+		p := r.Params
+		fmt.Fprintf(w, "%d\t%d\t%g\t%g\t%d/%d\t%g\t%g\t%d\t%.1f\t%.2f\t%.2f\t%.2f\t%.2f\t%.3f\t\n",
+			i+1, p.RSIPeriod, p.RSIBuy, p.RSISell, p.MAShort, p.MALong, p.StopLoss, p.MinProfit,
+			r.Sells, r.WinRate, r.NetProfit, r.Return, r.MaxDrawdown, r.Sharpe, r.Objective)
+	}
+	w.Flush()
+
+	if len(results) > 0 {
+		fmt.Println("\n✅ Melhores parâmetros (.env):")
+		printSignalEnv(results[0].Params)
+	}
+}
+
+// printWalkForward mostra, por fold, o desempenho in-sample dos parâmetros vencedores
+// e o out-of-sample no período seguinte. A eficiência é a razão entre os retornos.
+func printWalkForward(folds []backtest.Fold) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "FOLD\tOUT-OF-SAMPLE\tRSI\tBUY/SELL\tMA\tSTOP\tIS RET%\tOOS RET%\tOOS DD%\tOOS SELLS\tEFIC.\t")
+	var isTotal, oosTotal float64
+	for i, f := range folds {
+		p := f.InSample.Params
+		efficiency := "-"
+		if f.InSample.Return != 0 {
+			efficiency = fmt.Sprintf("%.2f", f.OutSample.Return/f.InSample.Return)
+		}
+		fmt.Fprintf(w, "%d\t%s → %s\t%d\t%g/%g\t%d/%d\t%g\t%.2f\t%.2f\t%.2f\t%d\t%s\t\n",
+			i+1, f.OutSample.From.Format("2006-01-02"), f.OutSample.To.Format("2006-01-02"),
+			p.RSIPeriod, p.RSIBuy, p.RSISell, p.MAShort, p.MALong, p.StopLoss,
+			f.InSample.Return, f.OutSample.Return, f.OutSample.MaxDrawdown, f.OutSample.Sells, efficiency)
+		isTotal += f.InSample.Return
+		oosTotal += f.OutSample.Return
+	}
+	w.Flush()
+
+	if len(folds) > 0 {
+		fmt.Printf("\n📈 Retorno médio: in-sample %.2f%%, out-of-sample %.2f%%\n",
+			isTotal/float64(len(folds)), oosTotal/float64(len(folds)))
+		fmt.Println("✅ Parâmetros do último fold (.env):")
+		printSignalEnv(folds[len(folds)-1].InSample.Params)
+	}
+}
+
+func printSignalEnv(p config.SignalParams) {
+	fmt.Printf("RSI_PERIOD=%d\nRSI_BUY=%g\nRSI_SELL=%g\nMA_SHORT=%d\nMA_LONG=%d\nSTOP_LOSS=%g\nMIN_PROFIT_PCT=%g\n",
+		p.RSIPeriod, p.RSIBuy, p.RSISell, p.MAShort, p.MALong, p.StopLoss, p.MinProfit)
+}
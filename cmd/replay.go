@@ -0,0 +1,140 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/casarotto/binance-bot/internal/config"
+	"github.com/casarotto/binance-bot/internal/report"
+	traderbot "github.com/casarotto/binance-bot/internal/trader-bot"
+	"github.com/casarotto/binance-bot/internal/tui"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runReplay implementa o subcomando "replay": reproduz uma gravação de RECORD_DIR no
+// trader com a estratégia do .env, operando na corretora simulada
+func runReplay(args []string) {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	envPath := fs.String("env", ".env", "Caminho para o arquivo .env (configuração da estratégia)")
+	file := fs.String("file", "", "Gravação a reproduzir (.jsonl.gz)")
+	speed := fs.Float64("speed", 1, "Velocidade da reprodução (1 = tempo real, 0 = sem pausas)")
+	usdt := fs.Float64("usdt", 1000, "Saldo inicial em USDT da corretora simulada (padrão: o da conta gravada)")
+	btc := fs.Float64("btc", 0, "Saldo inicial em BTC da corretora simulada (padrão: o da conta gravada)")
+	fee := fs.Float64("fee", 0, "Taxa por execução, substitui PAPER_MAKER_FEE e PAPER_TAKER_FEE (0.001 = 0.1%)")
+	headless := fs.Bool("headless", false, "Reproduzir sem a TUI e imprimir o relatório no fim")
+	fs.Parse(args)
+
+	if *file == "" {
+		log.Fatalf("❌ Informe a gravação com -file")
+	}
+
+	cfg, err := config.LoadFromEnv(*envPath)
+	if err != nil {
+		log.Fatalf("❌ Erro ao carregar configurações: %v", err)
+	}
+	riskPerTrade, err := strconv.ParseFloat(os.Getenv("RISK_PER_TRADE"), 64)
+	if err != nil {
+		log.Fatalf("Erro ao converter RISK_PER_TRADE: %v", err)
+	}
+	var ruleStrategy *traderbot.RuleStrategy
+	if cfg.Strategy == "rules" {
+		ruleStrategy, err = traderbot.CompileRuleStrategy(cfg.Rules.Entry, cfg.Rules.Exit)
+		if err != nil {
+			log.Fatalf("❌ Erro nas regras da estratégia: %v", err)
+		}
+		if err := ruleStrategy.CheckCandleCapacity(cfg.SignalInterval, cfg.CandleCapacity); err != nil {
+			log.Fatalf("❌ Erro nas regras da estratégia: %v", err)
+		}
+	}
+
+	// Histórico e logs do replay ficam separados dos da operação real e começam vazios
+	replayDir := filepath.Join("history", "replay")
+	logger, err := traderbot.NewLogger(replayDir, cfg.Log)
+	if err != nil {
+		log.Fatal("Erro ao criar logger:", err)
+	}
+	defer logger.Close()
+	historyFile := filepath.Join(replayDir, "trade_history.json")
+	if err := os.Remove(historyFile); err != nil && !os.IsNotExist(err) {
+		log.Fatalf("❌ Erro ao limpar o histórico do replay: %v", err)
+	}
+
+	player, err := traderbot.NewPlayer(*file, *speed, cfg.SignalInterval)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	// Sem -usdt/-btc a corretora simulada começa com os saldos da conta gravada
+	if account, ok := player.Account(); ok {
+		set := make(map[string]bool)
+		fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		if !set["usdt"] {
+			*usdt = account.USDT
+		}
+		if !set["btc"] {
+			*btc = account.BTC
+		}
+	}
+	execution := paperConfig(fs, cfg, *fee)
+	exchange := traderbot.NewPaperExchange(player, *usdt, *btc, execution)
+	exchange.SetClock(player.Now)
+	if execution.Slippage == config.SlippageBook && !cfg.Depth.Enabled {
+		// O deslizamento percorre o livro montado com o depth gravado (RECORD_STREAMS=depth);
+		// com DEPTH_ENABLED o livro já é atualizado pela assinatura do trader
+		if err := exchange.TrackDepth(func(err error) { log.Printf("⚠️ Erro no depth gravado: %v", err) }); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+	}
+
+	trader := traderbot.NewBTCTraderWithExchange(exchange, historyFile, riskPerTrade, cfg.CandleCapacity)
+	trader.SetClock(player.Now)
+	trader.SetLogger(logger)
+	configureTrader(trader, cfg, ruleStrategy)
+	trader.SetInitialPosition(false, 0)
+
+	run := func() {
+		logger.Infof("⏯️ Reproduzindo %s (velocidade %gx)", *file, *speed)
+		trader.Start()
+		if err := player.Err(); err != nil {
+			logger.Errorf("❌ Replay interrompido: %v", err)
+		}
+		trader.FlushTradeHistory()
+		logger.Infof("⏹️ Replay concluído: %d klines, %d trades (histórico em %s)",
+			player.Events(), len(trader.GetTradeHistory()), historyFile)
+	}
+
+	if *headless {
+		logger.EnableStdout()
+		run()
+
+		r, err := report.Compute(trader.GetTradeHistory(), report.Options{Period: "day", FeeRate: execution.FeeRate(false)})
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		fmt.Println(r)
+		return
+	}
+
+	// Com a TUI a reprodução roda em segundo plano e a tela fica aberta no fim
+	go run()
+	p := tea.NewProgram(tui.New(trader), tea.WithAltScreen(), tea.WithMouseCellMotion())
+	if err := p.Start(); err != nil {
+		log.Fatalf("Erro ao iniciar TUI: %v", err)
+	}
+	trader.Stop()
+}
+
+// paperConfig retorna o modelo de execução do .env (PAPER_*), com as taxas de -fee
+// quando a flag foi informada
+func paperConfig(fs *flag.FlagSet, cfg *config.Config, fee float64) config.PaperConfig {
+	execution := cfg.Paper
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "fee" {
+			execution.MakerFee, execution.TakerFee = fee, fee
+		}
+	})
+	return execution
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/casarotto/binance-bot/internal/report"
+	traderbot "github.com/casarotto/binance-bot/internal/trader-bot"
+)
+
+// runReport implementa o subcomando "report": estatísticas de desempenho do histórico de trades
+func runReport(args []string) {
+	opts := report.DefaultOptions()
+
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	historyFile := fs.String("history", filepath.Join("history", "trade_history.json"), "Arquivo de histórico de trades")
+	since := fs.String("since", "", "Início do período (AAAA-MM-DD, RFC3339 ou timestamp Unix)")
+	until := fs.String("until", "", "Fim do período (AAAA-MM-DD, RFC3339 ou timestamp Unix)")
+	fs.StringVar(&opts.Period, "period", opts.Period, "Agrupamento do lucro: day, week ou month")
+	fs.Float64Var(&opts.FeeRate, "fee", opts.FeeRate, "Taxa por execução (0.001 = 0.1%)")
+	fs.Parse(args)
+
+	var err error
+	if opts.Since, err = parseReportTime(*since, false, time.Local); err != nil {
+		log.Fatalf("❌ -since inválido: %v", err)
+	}
+	if opts.Until, err = parseReportTime(*until, true, time.Local); err != nil {
+		log.Fatalf("❌ -until inválido: %v", err)
+	}
+
+	trades, err := traderbot.LoadTradeHistory(*historyFile)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	r, err := report.Compute(trades, opts)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	fmt.Fprintln(os.Stdout, r)
+}
+
+// parseReportTime aceita data (AAAA-MM-DD), RFC3339 ou timestamp Unix em segundos.
+// Uma data sem hora é interpretada em loc e, usada como fim do período, inclui o dia inteiro.
+func parseReportTime(value string, endOfDay bool, loc *time.Location) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if sec, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(sec, 0), nil
+	}
+	if day, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		if endOfDay {
+			return day.Add(24*time.Hour - time.Nanosecond), nil
+		}
+		return day, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,224 @@
+// Package api expõe uma API HTTP local para consultar e controlar o bot em execução.
+// Todas as rotas exigem o cabeçalho "Authorization: Bearer <API_TOKEN>".
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	traderbot "github.com/casarotto/binance-bot/internal/trader-bot"
+)
+
+// Server é o servidor HTTP de controle e status
+type Server struct {
+	trader *traderbot.BTCTrader
+	logger *traderbot.Logger
+	token  string
+	http   *http.Server
+}
+
+// NewServer cria o servidor para o trader informado
+func NewServer(addr, token string, trader *traderbot.BTCTrader, logger *traderbot.Logger) *Server {
+	s := &Server{trader: trader, logger: logger, token: token}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/status", s.handleStatus)
+	mux.HandleFunc("GET /api/trades", s.handleTrades)
+	mux.HandleFunc("GET /api/logs", s.handleLogs)
+	mux.HandleFunc("POST /api/pause", s.handlePause)
+	mux.HandleFunc("POST /api/resume", s.handleResume)
+	mux.HandleFunc("POST /api/close", s.handleClose)
+	mux.HandleFunc("POST /api/breaker/reset", s.handleBreakerReset)
+	mux.HandleFunc("POST /api/risk", s.handleRisk)
+
+	s.http = &http.Server{
+		Addr:              addr,
+		Handler:           s.authenticate(mux),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s
+}
+
+// ListenAndServe atende as requisições até Shutdown ser chamado
+func (s *Server) ListenAndServe() error {
+	err := s.http.ListenAndServe()
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+// Shutdown encerra o servidor aguardando as requisições em andamento
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.http.Shutdown(ctx)
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("token inválido"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.trader.Snapshot())
+}
+
+// handleTrades retorna o histórico filtrado por action (buy/sell), since/until
+// (RFC3339 ou timestamp Unix em segundos) e limit (os mais recentes)
+func (s *Server) handleTrades(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	action := query.Get("action")
+
+	since, err := parseTime(query.Get("since"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("since inválido: %v", err))
+		return
+	}
+	until, err := parseTime(query.Get("until"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("until inválido: %v", err))
+		return
+	}
+	limit, err := parseLimit(query.Get("limit"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	trades := make([]traderbot.Trade, 0)
+	for _, trade := range s.trader.GetTradeHistory() {
+		if action != "" && trade.Action != action {
+			continue
+		}
+		if since > 0 && trade.Timestamp < since {
+			continue
+		}
+		if until > 0 && trade.Timestamp > until {
+			continue
+		}
+		trades = append(trades, trade)
+	}
+	if limit > 0 && len(trades) > limit {
+		trades = trades[len(trades)-limit:]
+	}
+	writeJSON(w, http.StatusOK, trades)
+}
+
+// handleLogs retorna as entradas recentes a partir de level (debug, info, warn, error; padrão info)
+func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	level := slog.LevelInfo
+	if name := r.URL.Query().Get("level"); name != "" {
+		if err := level.UnmarshalText([]byte(name)); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("level inválido: %s", name))
+			return
+		}
+	}
+	logs := s.logger.GetRecentLogs(level)
+	if limit > 0 && len(logs) > limit {
+		logs = logs[len(logs)-limit:]
+	}
+	writeJSON(w, http.StatusOK, logs)
+}
+
+func (s *Server) handlePause(w http.ResponseWriter, r *http.Request) {
+	s.trader.PauseSignals()
+	writeJSON(w, http.StatusOK, s.trader.Snapshot())
+}
+
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
+	s.trader.ResumeSignals()
+	writeJSON(w, http.StatusOK, s.trader.Snapshot())
+}
+
+func (s *Server) handleClose(w http.ResponseWriter, r *http.Request) {
+	if err := s.trader.ForceClose(); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.trader.Snapshot())
+}
+
+func (s *Server) handleBreakerReset(w http.ResponseWriter, r *http.Request) {
+	s.trader.ResetBreaker()
+	writeJSON(w, http.StatusOK, s.trader.Snapshot())
+}
+
+// riskUpdate são os parâmetros de risco alteráveis; campos ausentes não mudam
+type riskUpdate struct {
+	RiskPerTrade *float64 `json:"risk_per_trade"`
+	StopLoss     *float64 `json:"stop_loss"`
+}
+
+func (s *Server) handleRisk(w http.ResponseWriter, r *http.Request) {
+	var update riskUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("JSON inválido: %v", err))
+		return
+	}
+	if update.RiskPerTrade != nil {
+		if err := s.trader.SetRiskPerTrade(*update.RiskPerTrade); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if update.StopLoss != nil {
+		if err := s.trader.SetStopLoss(*update.StopLoss); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, s.trader.Snapshot())
+}
+
+// parseTime aceita RFC3339 ou timestamp Unix em segundos (a unidade de Trade.Timestamp)
+func parseTime(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if sec, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return sec, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Unix(), nil
+}
+
+func parseLimit(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		return 0, fmt.Errorf("limit inválido: %s", value)
+	}
+	return limit, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
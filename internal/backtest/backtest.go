@@ -0,0 +1,143 @@
+// Package backtest executa o trader sobre candles históricos na corretora simulada
+// e busca os parâmetros da estratégia que maximizam um objetivo.
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/casarotto/binance-bot/internal/config"
+	"github.com/casarotto/binance-bot/internal/report"
+	traderbot "github.com/casarotto/binance-bot/internal/trader-bot"
+)
+
+// Objetivos disponíveis para ordenar os resultados
+const (
+	ObjectiveProfit   = "profit"    // lucro líquido em USDT
+	ObjectiveSharpe   = "sharpe"    // índice Sharpe anualizado da curva de patrimônio
+	ObjectiveReturnDD = "return_dd" // retorno % dividido pelo drawdown máximo %
+)
+
+// Options configura as execuções do backtest
+type Options struct {
+	Interval       string             // intervalo dos candles e dos sinais
+	CandleCapacity int                // candles mantidos pelo trader
+	InitialUSDT    float64            // saldo inicial da corretora simulada
+	Execution      config.PaperConfig // taxas, deslizamento e latência da corretora simulada
+	RiskPerTrade   float64
+	Objective      string
+
+	// Configure aplica a configuração do .env (DCA, grid, timeframes, regras) a cada
+	// trader criado; os parâmetros de sinal testados são aplicados em seguida
+	Configure func(*traderbot.BTCTrader)
+}
+
+// Result é o desempenho de um conjunto de parâmetros em um período
+type Result struct {
+	Params      config.SignalParams
+	From, To    time.Time
+	Trades      int     // execuções (compras e vendas)
+	Sells       int     // operações encerradas
+	WinRate     float64 // percentual
+	NetProfit   float64 // variação do patrimônio em USDT, posição aberta marcada a mercado
+	Return      float64 // percentual
+	MaxDrawdown float64 // percentual
+	Sharpe      float64
+	Objective   float64 // valor do objetivo escolhido, maior é melhor
+}
+
+// Run executa o trader sobre candles. Os primeiros warmup candles só preenchem os
+// indicadores (sinais pausados) e não entram no resultado.
+func Run(candles []traderbot.Candle, warmup int, params config.SignalParams, opts Options) (Result, error) {
+	result := Result{Params: params}
+	if err := params.Validate(); err != nil {
+		return result, err
+	}
+	if warmup < 0 || warmup >= len(candles) {
+		return result, fmt.Errorf("aquecimento de %d candles sem candles para avaliar (%d no total)", warmup, len(candles))
+	}
+
+	f := newFeed(candles, opts.Interval)
+	exchange := traderbot.NewPaperExchange(f, opts.InitialUSDT, 0, opts.Execution)
+	exchange.SetClock(f.now)
+
+	trader := traderbot.NewBTCTraderWithExchange(exchange, "", opts.RiskPerTrade, opts.CandleCapacity)
+	trader.SetClock(f.now)
+	if opts.Configure != nil {
+		opts.Configure(trader)
+	}
+	// O grid ignora os parâmetros de sinal testados: o resultado não os mediria
+	if trader.IsGridEnabled() {
+		return result, fmt.Errorf("o backtest avalia os parâmetros de sinal e não suporta a estratégia de grid")
+	}
+	trader.SetSignalParams(params)
+	trader.SetInitialPosition(false, 0)
+	if warmup > 0 {
+		trader.PauseSignals()
+	}
+
+	times := make([]time.Time, 0, len(candles)-warmup)
+	equity := make([]float64, 0, len(candles)-warmup)
+	f.onCandle = func(i int, c traderbot.Candle) {
+		if i == warmup-1 {
+			trader.ResumeSignals()
+		}
+		if i < warmup {
+			return
+		}
+		btc, usdt, price := exchange.Balances()
+		times = append(times, time.UnixMilli(c.CloseTime))
+		equity = append(equity, usdt+btc*price)
+	}
+
+	trader.Start()
+	if !f.complete.Load() {
+		return result, fmt.Errorf("backtest interrompido antes do fim dos candles")
+	}
+
+	result.From = time.UnixMilli(candles[warmup].OpenTime)
+	result.To = time.UnixMilli(candles[len(candles)-1].CloseTime)
+	history := trader.GetTradeHistory()
+	r, err := report.Compute(history, report.Options{Since: result.From, FeeRate: opts.Execution.FeeRate(false)})
+	if err != nil {
+		return result, err
+	}
+	result.Trades = r.Trades
+	result.Sells = r.Sells
+	result.WinRate = r.WinRate
+
+	// O patrimônio de partida é o do fechamento do último candle de aquecimento
+	start := opts.InitialUSDT
+	result.NetProfit = equity[len(equity)-1] - start
+	if start > 0 {
+		result.Return = result.NetProfit / start * 100
+	}
+	result.MaxDrawdown, result.Sharpe, _ = report.CurveStats(append([]time.Time{result.From}, times...), append([]float64{start}, equity...))
+	result.Objective = objectiveValue(opts.Objective, result)
+	return result, nil
+}
+
+// ValidateObjective verifica se o objetivo é conhecido
+func ValidateObjective(objective string) error {
+	switch objective {
+	case ObjectiveProfit, ObjectiveSharpe, ObjectiveReturnDD:
+		return nil
+	}
+	return fmt.Errorf("objetivo inválido: %s (use %s, %s ou %s)", objective, ObjectiveProfit, ObjectiveSharpe, ObjectiveReturnDD)
+}
+
+func objectiveValue(objective string, r Result) float64 {
+	switch objective {
+	case ObjectiveSharpe:
+		if math.IsNaN(r.Sharpe) || math.IsInf(r.Sharpe, 0) {
+			return 0
+		}
+		return r.Sharpe
+	case ObjectiveReturnDD:
+		// Drawdowns abaixo de 1% contam como 1% para não premiar quem quase não operou
+		return r.Return / math.Max(r.MaxDrawdown, 1)
+	default:
+		return r.NetProfit
+	}
+}
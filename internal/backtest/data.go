@@ -0,0 +1,137 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/adshao/go-binance/v2"
+	traderbot "github.com/casarotto/binance-bot/internal/trader-bot"
+)
+
+// LoadCandles lê os candles do intervalo de um arquivo de dados históricos:
+//   - .csv no formato dos arquivos de klines da Binance (data.binance.vision):
+//     open_time, open, high, low, close, volume, close_time, ... com ou sem cabeçalho
+//   - .jsonl.gz gravado pelo bot (RECORD_DIR), usando a última atualização de cada candle
+//
+// Os candles são retornados em ordem cronológica e sem repetições.
+func LoadCandles(path, interval string) ([]traderbot.Candle, error) {
+	var candles []traderbot.Candle
+	var err error
+	if strings.HasSuffix(path, ".jsonl.gz") {
+		candles, err = loadRecording(path, interval)
+	} else {
+		candles, err = loadCSV(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(candles, func(i, j int) bool { return candles[i].OpenTime < candles[j].OpenTime })
+	unique := candles[:0]
+	for _, c := range candles {
+		// A última atualização de um candle prevalece sobre as anteriores
+		if n := len(unique); n > 0 && unique[n-1].OpenTime == c.OpenTime {
+			unique[n-1] = c
+			continue
+		}
+		unique = append(unique, c)
+	}
+	if len(unique) == 0 {
+		return nil, fmt.Errorf("nenhum candle de %s em %s", interval, path)
+	}
+	return unique, nil
+}
+
+func loadCSV(path string) ([]traderbot.Candle, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir dados históricos: %v", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	var candles []traderbot.Candle
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler dados históricos: %v", err)
+		}
+		if len(record) < 7 {
+			return nil, fmt.Errorf("linha %d: esperadas ao menos 7 colunas (open_time ... close_time)", line)
+		}
+		openTime, err := strconv.ParseInt(strings.TrimSpace(record[0]), 10, 64)
+		if err != nil {
+			if line == 1 {
+				continue // cabeçalho
+			}
+			return nil, fmt.Errorf("linha %d: open_time inválido %q", line, record[0])
+		}
+		closeTime, err := strconv.ParseInt(strings.TrimSpace(record[6]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("linha %d: close_time inválido %q", line, record[6])
+		}
+
+		candle, err := traderbot.CandleFromWsKline(binance.WsKline{
+			StartTime: toMillis(openTime),
+			EndTime:   toMillis(closeTime),
+			Open:      record[1],
+			High:      record[2],
+			Low:       record[3],
+			Close:     record[4],
+			Volume:    record[5],
+			IsFinal:   true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("linha %d: %v", line, err)
+		}
+		candles = append(candles, candle)
+	}
+	return candles, nil
+}
+
+// toMillis converte os timestamps em microssegundos usados nos arquivos da Binance a partir de 2025
+func toMillis(ts int64) int64 {
+	if ts > 1e14 {
+		return ts / 1000
+	}
+	return ts
+}
+
+func loadRecording(path, interval string) ([]traderbot.Candle, error) {
+	var candles []traderbot.Candle
+	var decodeErr error
+	err := traderbot.ReadRecording(path, func(event traderbot.RecordedEvent) bool {
+		if event.Stream != traderbot.StreamKline {
+			return true
+		}
+		var kline binance.WsKlineEvent
+		if err := json.Unmarshal(event.Data, &kline); err != nil {
+			decodeErr = fmt.Errorf("evento de kline inválido na gravação: %v", err)
+			return false
+		}
+		if kline.Kline.Interval != interval {
+			return true
+		}
+		candle, err := traderbot.CandleFromWsKline(kline.Kline)
+		if err != nil {
+			return true
+		}
+		candle.Final = true
+		candles = append(candles, candle)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return candles, decodeErr
+}
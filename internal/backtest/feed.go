@@ -0,0 +1,209 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+	traderbot "github.com/casarotto/binance-bot/internal/trader-bot"
+)
+
+// feed entrega candles históricos ao trader como se viessem do WebSocket de klines.
+// Os timeframes de confirmação recebem candles agregados a partir dos do intervalo
+// dos sinais, atualizados a cada candle como o candle em andamento na Binance.
+type feed struct {
+	candles  []traderbot.Candle
+	interval string
+	onCandle func(i int, c traderbot.Candle) // chamado depois que o trader processou o candle
+
+	mu       sync.Mutex
+	handlers map[string]binance.WsKlineHandler
+	dones    []chan struct{}
+	stopC    chan struct{}
+	started  bool
+	complete atomic.Bool
+
+	clock atomic.Int64 // fechamento do candle atual, em milissegundos
+}
+
+func newFeed(candles []traderbot.Candle, interval string) *feed {
+	f := &feed{
+		candles:  candles,
+		interval: interval,
+		handlers: make(map[string]binance.WsKlineHandler),
+		stopC:    make(chan struct{}),
+	}
+	f.clock.Store(candles[0].OpenTime)
+	return f
+}
+
+// now é o relógio do trader e da corretora simulada durante o backtest
+func (f *feed) now() time.Time {
+	return time.UnixMilli(f.clock.Load())
+}
+
+// PriceAt estima o preço no instante t interpolando entre a abertura e o fechamento do
+// candle que o contém; usado pela corretora simulada para aplicar a latência das ordens
+func (f *feed) PriceAt(t time.Time) (float64, bool) {
+	ms := t.UnixMilli()
+	i := sort.Search(len(f.candles), func(i int) bool { return f.candles[i].CloseTime >= ms })
+	if i == len(f.candles) || f.candles[i].OpenTime > ms {
+		return 0, false
+	}
+	c := f.candles[i]
+	frac := float64(ms-c.OpenTime) / float64(c.CloseTime-c.OpenTime+1)
+	return c.Open + (c.Close-c.Open)*frac, true
+}
+
+// Klines não tem histórico anterior: os timeframes são preenchidos pelos candles agregados
+func (f *feed) Klines(ctx context.Context, interval string, limit int) ([]*binance.Kline, error) {
+	return nil, nil
+}
+
+// KlineStream registra o handler; a entrega começa quando o intervalo dos sinais é assinado
+func (f *feed) KlineStream(interval string, handler binance.WsKlineHandler, errHandler binance.ErrHandler) (chan struct{}, chan struct{}, error) {
+	if interval != f.interval {
+		// Só timeframes maiores podem ser agregados a partir dos candles históricos
+		d, err := traderbot.IntervalDuration(interval)
+		if err != nil {
+			return nil, nil, err
+		}
+		if base, _ := traderbot.IntervalDuration(f.interval); d <= base {
+			return nil, nil, fmt.Errorf("timeframe %s não é maior que o intervalo dos dados (%s)", interval, f.interval)
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.started && interval == f.interval {
+		return nil, nil, fmt.Errorf("o backtest só pode ser executado uma vez")
+	}
+	done := make(chan struct{})
+	stop := make(chan struct{})
+	f.handlers[interval] = handler
+	f.dones = append(f.dones, done)
+	go func() {
+		select {
+		case <-stop:
+			f.halt()
+		case <-done:
+		}
+	}()
+
+	if interval == f.interval {
+		f.started = true
+		go f.play()
+	}
+	return done, stop, nil
+}
+
+// DepthStream não é suportado: os candles históricos não têm livro de ofertas
+func (f *feed) DepthStream(handler func(traderbot.DepthUpdate), errHandler binance.ErrHandler) (chan struct{}, chan struct{}, error) {
+	return nil, nil, fmt.Errorf("dados históricos sem livro de ofertas (depth)")
+}
+
+// AggTradeStream não é suportado: os candles históricos não têm os negócios
+func (f *feed) AggTradeStream(handler binance.WsAggTradeHandler, errHandler binance.ErrHandler) (chan struct{}, chan struct{}, error) {
+	return nil, nil, fmt.Errorf("dados históricos sem negócios agregados (aggTrade)")
+}
+
+func (f *feed) halt() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	select {
+	case <-f.stopC:
+	default:
+		close(f.stopC)
+	}
+}
+
+// aggregate é o candle em formação de um timeframe maior
+type aggregate struct {
+	interval string
+	millis   int64
+	offset   int64
+	handler  binance.WsKlineHandler
+	candle   traderbot.Candle
+}
+
+func (f *feed) play() {
+	f.mu.Lock()
+	primary := f.handlers[f.interval]
+	var higher []*aggregate
+	for interval, handler := range f.handlers {
+		if interval == f.interval {
+			continue
+		}
+		d, _ := traderbot.IntervalDuration(interval)
+		a := &aggregate{interval: interval, millis: d.Milliseconds(), handler: handler}
+		if interval[len(interval)-1] == 'w' {
+			// As semanas da Binance começam na segunda-feira; 1/1/1970 foi uma quinta
+			a.offset = 4 * 24 * time.Hour.Milliseconds()
+		}
+		higher = append(higher, a)
+	}
+	f.mu.Unlock()
+	sort.Slice(higher, func(i, j int) bool { return higher[i].millis < higher[j].millis })
+
+	defer func() {
+		f.mu.Lock()
+		for _, done := range f.dones {
+			close(done)
+		}
+		f.mu.Unlock()
+	}()
+
+	for i, c := range f.candles {
+		select {
+		case <-f.stopC:
+			return
+		default:
+		}
+		f.clock.Store(c.CloseTime)
+
+		for _, a := range higher {
+			start := c.OpenTime - (c.OpenTime-a.offset)%a.millis
+			if a.candle.OpenTime != start || a.candle.CloseTime == 0 {
+				a.candle = traderbot.Candle{OpenTime: start, CloseTime: start + a.millis - 1, Open: c.Open, High: c.High, Low: c.Low}
+			}
+			a.candle.High = max(a.candle.High, c.High)
+			a.candle.Low = min(a.candle.Low, c.Low)
+			a.candle.Close = c.Close
+			a.candle.Volume += c.Volume
+			a.candle.Final = c.CloseTime >= a.candle.CloseTime
+			a.handler(klineEvent(a.candle, a.interval))
+		}
+
+		primary(klineEvent(c, f.interval))
+		if f.onCandle != nil {
+			f.onCandle(i, c)
+		}
+	}
+	f.complete.Store(true)
+}
+
+func klineEvent(c traderbot.Candle, interval string) *binance.WsKlineEvent {
+	format := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	return &binance.WsKlineEvent{
+		Event:  "kline",
+		Time:   c.CloseTime,
+		Symbol: "BTCUSDT",
+		Kline: binance.WsKline{
+			StartTime: c.OpenTime,
+			EndTime:   c.CloseTime,
+			Symbol:    "BTCUSDT",
+			Interval:  interval,
+			Open:      format(c.Open),
+			High:      format(c.High),
+			Low:       format(c.Low),
+			Close:     format(c.Close),
+			Volume:    format(c.Volume),
+			IsFinal:   c.Final,
+		},
+	}
+}
@@ -0,0 +1,274 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/casarotto/binance-bot/internal/config"
+	traderbot "github.com/casarotto/binance-bot/internal/trader-bot"
+)
+
+// Modos de busca
+const (
+	SearchGrid   = "grid"   // todas as combinações dos intervalos
+	SearchRandom = "random" // Samples combinações sorteadas
+)
+
+// maxCombinations limita a busca em grade para evitar execuções de dias
+const maxCombinations = 100000
+
+// Range é o intervalo testado de um parâmetro. Vazio mantém o valor base.
+type Range struct {
+	Min, Max, Step float64
+	set            bool
+}
+
+// ParseRange lê um valor fixo ("30") ou um intervalo "min:max:step" ("20:40:5")
+func ParseRange(value string) (Range, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Range{}, nil
+	}
+	parts := strings.Split(value, ":")
+	if len(parts) != 1 && len(parts) != 3 {
+		return Range{}, fmt.Errorf("intervalo inválido: %q (use valor ou min:max:step)", value)
+	}
+	nums := make([]float64, len(parts))
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return Range{}, fmt.Errorf("intervalo inválido: %q", value)
+		}
+		nums[i] = v
+	}
+	if len(nums) == 1 {
+		return Range{Min: nums[0], Max: nums[0], Step: 1, set: true}, nil
+	}
+	if nums[2] <= 0 || nums[1] < nums[0] {
+		return Range{}, fmt.Errorf("intervalo inválido: %q (exige min <= max e step > 0)", value)
+	}
+	return Range{Min: nums[0], Max: nums[1], Step: nums[2], set: true}, nil
+}
+
+// values retorna os valores do intervalo, ou base quando ele não foi definido
+func (r Range) values(base float64) []float64 {
+	if !r.set {
+		return []float64{base}
+	}
+	var out []float64
+	n := int(math.Floor((r.Max-r.Min)/r.Step+1e-9)) + 1
+	for i := 0; i < n; i++ {
+		// Arredonda para não acumular erro de ponto flutuante (0.1 + 0.2 ...)
+		out = append(out, math.Round((r.Min+float64(i)*r.Step)*1e8)/1e8)
+	}
+	return out
+}
+
+// sample sorteia um valor do intervalo respeitando o passo
+func (r Range) sample(base float64, rng *rand.Rand) float64 {
+	values := r.values(base)
+	return values[rng.Intn(len(values))]
+}
+
+// Space é o espaço de busca dos parâmetros de sinal
+type Space struct {
+	RSIPeriod, RSIBuy, RSISell Range
+	MAShort, MALong            Range
+	StopLoss, MinProfit        Range
+}
+
+func (s Space) combine(base config.SignalParams, pick func(r Range, base float64) []float64, emit func(config.SignalParams) bool) {
+	dims := []struct {
+		r   Range
+		get func(p *config.SignalParams) float64
+		set func(p *config.SignalParams, v float64)
+	}{
+		{s.RSIPeriod, func(p *config.SignalParams) float64 { return float64(p.RSIPeriod) }, func(p *config.SignalParams, v float64) { p.RSIPeriod = int(v) }},
+		{s.RSIBuy, func(p *config.SignalParams) float64 { return p.RSIBuy }, func(p *config.SignalParams, v float64) { p.RSIBuy = v }},
+		{s.RSISell, func(p *config.SignalParams) float64 { return p.RSISell }, func(p *config.SignalParams, v float64) { p.RSISell = v }},
+		{s.MAShort, func(p *config.SignalParams) float64 { return float64(p.MAShort) }, func(p *config.SignalParams, v float64) { p.MAShort = int(v) }},
+		{s.MALong, func(p *config.SignalParams) float64 { return float64(p.MALong) }, func(p *config.SignalParams, v float64) { p.MALong = int(v) }},
+		{s.StopLoss, func(p *config.SignalParams) float64 { return p.StopLoss }, func(p *config.SignalParams, v float64) { p.StopLoss = v }},
+		{s.MinProfit, func(p *config.SignalParams) float64 { return p.MinProfit }, func(p *config.SignalParams, v float64) { p.MinProfit = v }},
+	}
+
+	var walk func(i int, p config.SignalParams) bool
+	walk = func(i int, p config.SignalParams) bool {
+		if i == len(dims) {
+			return emit(p)
+		}
+		for _, v := range pick(dims[i].r, dims[i].get(&base)) {
+			dims[i].set(&p, v)
+			if !walk(i+1, p) {
+				return false
+			}
+		}
+		return true
+	}
+	walk(0, base)
+}
+
+// Search configura uma otimização
+type Search struct {
+	Space    Space
+	Mode     string // SearchGrid ou SearchRandom
+	Samples  int    // combinações sorteadas no modo random
+	Seed     int64
+	Workers  int                   // backtests em paralelo (0 = um por CPU)
+	Progress func(done, total int) // chamado a cada backtest concluído
+}
+
+// Candidates gera as combinações a testar a partir de base, descartando as incoerentes
+// (ex. média curta maior que a longa)
+func (s Search) Candidates(base config.SignalParams) ([]config.SignalParams, error) {
+	var out []config.SignalParams
+	switch s.Mode {
+	case SearchGrid, "":
+		var tooMany bool
+		s.Space.combine(base, Range.values, func(p config.SignalParams) bool {
+			if p.Validate() != nil {
+				return true
+			}
+			out = append(out, p)
+			if len(out) > maxCombinations {
+				tooMany = true
+				return false
+			}
+			return true
+		})
+		if tooMany {
+			return nil, fmt.Errorf("mais de %d combinações na grade: aumente os passos ou use -search random", maxCombinations)
+		}
+	case SearchRandom:
+		if s.Samples <= 0 {
+			return nil, fmt.Errorf("informe quantas combinações sortear")
+		}
+		rng := rand.New(rand.NewSource(s.Seed))
+		seen := make(map[config.SignalParams]bool)
+		// Limita as tentativas para espaços pequenos ou com muitas combinações inválidas
+		for tries := 0; len(out) < s.Samples && tries < s.Samples*50; tries++ {
+			s.Space.combine(base, func(r Range, b float64) []float64 {
+				return []float64{r.sample(b, rng)}
+			}, func(p config.SignalParams) bool {
+				if p.Validate() == nil && !seen[p] {
+					seen[p] = true
+					out = append(out, p)
+				}
+				return true
+			})
+		}
+	default:
+		return nil, fmt.Errorf("busca inválida: %s (use %s ou %s)", s.Mode, SearchGrid, SearchRandom)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("nenhuma combinação válida de parâmetros no espaço de busca")
+	}
+	return out, nil
+}
+
+// Optimize executa o backtest de cada candidato em paralelo e retorna os resultados
+// do melhor para o pior segundo opts.Objective
+func (s Search) Optimize(candles []traderbot.Candle, warmup int, candidates []config.SignalParams, opts Options) ([]Result, error) {
+	if err := ValidateObjective(opts.Objective); err != nil {
+		return nil, err
+	}
+	workers := s.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	results := make([]Result, len(candidates))
+	errs := make([]error, len(candidates))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	done := 0
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i], errs[i] = Run(candles, warmup, candidates[i], opts)
+				if s.Progress != nil {
+					mu.Lock()
+					done++
+					s.Progress(done, len(candidates))
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range candidates {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Objective > results[j].Objective })
+	return results, nil
+}
+
+// Fold é uma janela da análise walk-forward: os melhores parâmetros do período
+// in-sample e o desempenho deles no período out-of-sample seguinte
+type Fold struct {
+	InSample  Result
+	OutSample Result
+}
+
+// WalkForward divide os candles em folds+isChunks blocos iguais. Cada fold otimiza em
+// isChunks blocos consecutivos e avalia o vencedor no bloco seguinte, que nunca foi
+// visto pela otimização. Os candles anteriores a cada período servem de aquecimento.
+func (s Search) WalkForward(candles []traderbot.Candle, base config.SignalParams, opts Options, folds, isChunks, warmup int) ([]Fold, error) {
+	if folds < 1 || isChunks < 1 {
+		return nil, fmt.Errorf("walk-forward exige ao menos 1 fold e 1 bloco in-sample")
+	}
+	chunk := (len(candles) - warmup) / (folds + isChunks)
+	if chunk < 2 {
+		return nil, fmt.Errorf("poucos candles (%d) para %d folds", len(candles), folds)
+	}
+	candidates, err := s.Candidates(base)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Fold
+	for i := 0; i < folds; i++ {
+		isStart := warmup + i*chunk
+		isEnd := isStart + isChunks*chunk
+		oosEnd := isEnd + chunk
+		if i == folds-1 {
+			oosEnd = len(candles) // o último fold inclui a sobra da divisão
+		}
+
+		ranked, err := s.Optimize(candles[isStart-warmup:isEnd], warmup, candidates, opts)
+		if err != nil {
+			return nil, err
+		}
+		oos, err := Run(candles[isEnd-warmup:oosEnd], warmup, ranked[0].Params, opts)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, Fold{InSample: ranked[0], OutSample: oos})
+	}
+	return out, nil
+}
+
+// Warmup retorna quantos candles os indicadores dos candidatos precisam antes de gerar sinais
+func Warmup(candidates []config.SignalParams) int {
+	n := 0
+	for _, p := range candidates {
+		n = max(n, p.MALong, p.RSIPeriod+1)
+	}
+	return n
+}
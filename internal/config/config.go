@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,6 +15,390 @@ type Config struct {
 	ApiSecret    string
 	Testnet      bool
 	InitialFunds float64
+	DCA          DCAConfig
+	Strategy     string // estratégia ativa: "rsi_ma" (padrão), "grid" ou "rules"
+	Grid         GridConfig
+	Rules        RulesConfig
+	Signal       SignalParams // parâmetros da estratégia rsi_ma e do stop loss
+
+	// Multi-timeframe
+	SignalInterval string          // intervalo dos candles que geram os sinais (padrão "1s")
+	CandleCapacity int             // quantidade de candles mantidos na série dos sinais
+	Confirmations  []TimeframeRule // regras de confirmação em timeframes maiores
+
+	// Modo headless
+	InitialPosition   string  // "auto" (reconcilia com a conta), "flat" ou "long"
+	InitialEntryPrice float64 // preço de entrada usado com INITIAL_POSITION=long (0 = última compra)
+	InitialQuantity   float64 // quantidade em BTC usada com INITIAL_POSITION=long (0 = saldo livre)
+
+	API         APIConfig
+	Remote      RemoteConfig
+	MetricsAddr string // endereço do endpoint /metrics do Prometheus (vazio = desabilitado)
+	Log         LogConfig
+	Notify      NotifyConfig
+	Webhook     WebhookConfig
+
+	EquityInterval time.Duration // intervalo das marcações do patrimônio (0 = desabilitado)
+
+	Record RecordConfig
+	Paper  PaperConfig // modelo de execução da corretora simulada (replay e backtests)
+	Depth  DepthConfig
+	Flow   OrderFlowConfig
+
+	RateLimit RateLimitConfig
+	Clock     ClockConfig
+	Retry     OrderRetryConfig
+	Breaker   BreakerConfig
+}
+
+// SignalParams são os parâmetros ajustáveis da estratégia rsi_ma e do stop loss
+type SignalParams struct {
+	RSIPeriod int     `json:"rsi_period"`
+	RSIBuy    float64 `json:"rsi_buy"`    // compra com RSI abaixo deste valor
+	RSISell   float64 `json:"rsi_sell"`   // vende com RSI acima deste valor
+	MAShort   int     `json:"ma_short"`   // período da média curta
+	MALong    int     `json:"ma_long"`    // período da média longa
+	StopLoss  float64 `json:"stop_loss"`  // queda máxima sobre a entrada (0.02 = 2%)
+	MinProfit float64 `json:"min_profit"` // lucro mínimo, em %, para vender por sinal
+}
+
+// DefaultSignalParams retorna os parâmetros originais: RSI(14) 30/70, médias 9/21,
+// stop de 2% e lucro mínimo de 0.3%
+func DefaultSignalParams() SignalParams {
+	return SignalParams{RSIPeriod: 14, RSIBuy: 30, RSISell: 70, MAShort: 9, MALong: 21, StopLoss: 0.02, MinProfit: 0.3}
+}
+
+// Validate verifica se os parâmetros formam uma estratégia coerente
+func (p SignalParams) Validate() error {
+	switch {
+	case p.RSIPeriod < 2:
+		return fmt.Errorf("período do RSI deve ser pelo menos 2")
+	case p.RSIBuy <= 0 || p.RSISell >= 100 || p.RSIBuy >= p.RSISell:
+		return fmt.Errorf("limites do RSI inválidos: compra %.2f, venda %.2f", p.RSIBuy, p.RSISell)
+	case p.MAShort < 1 || p.MAShort >= p.MALong:
+		return fmt.Errorf("a média curta (%d) deve ser menor que a longa (%d)", p.MAShort, p.MALong)
+	case p.StopLoss <= 0 || p.StopLoss >= 1:
+		return fmt.Errorf("stop loss deve estar entre 0 e 1")
+	case p.MinProfit < 0:
+		return fmt.Errorf("lucro mínimo não pode ser negativo")
+	}
+	return nil
+}
+
+// loadSignalParams lê os parâmetros da estratégia rsi_ma, mantendo os padrões não definidos
+func loadSignalParams() (SignalParams, error) {
+	p := DefaultSignalParams()
+	var err error
+	if p.RSIPeriod, err = intFromEnv("RSI_PERIOD", p.RSIPeriod); err != nil {
+		return p, err
+	}
+	if p.RSIBuy, err = floatFromEnv("RSI_BUY", p.RSIBuy); err != nil {
+		return p, err
+	}
+	if p.RSISell, err = floatFromEnv("RSI_SELL", p.RSISell); err != nil {
+		return p, err
+	}
+	if p.MAShort, err = intFromEnv("MA_SHORT", p.MAShort); err != nil {
+		return p, err
+	}
+	if p.MALong, err = intFromEnv("MA_LONG", p.MALong); err != nil {
+		return p, err
+	}
+	if p.StopLoss, err = floatFromEnv("STOP_LOSS", p.StopLoss); err != nil {
+		return p, err
+	}
+	if p.MinProfit, err = floatFromEnv("MIN_PROFIT_PCT", p.MinProfit); err != nil {
+		return p, err
+	}
+	if err := p.Validate(); err != nil {
+		return p, fmt.Errorf("parâmetros da estratégia: %v", err)
+	}
+	return p, nil
+}
+
+// RecordConfig configura a gravação dos streams de mercado usada pelo replay
+type RecordConfig struct {
+	Dir     string   // diretório das gravações (vazio = desabilitado)
+	Streams []string // streams gravados além dos klines: depth e/ou aggTrade
+}
+
+// DepthConfig configura o livro de ofertas local e o filtro de liquidez das entradas
+type DepthConfig struct {
+	Enabled        bool
+	MaxSpreadBps   float64 // spread máximo entre a melhor oferta de compra e de venda (0 = sem limite)
+	MaxSlippageBps float64 // deslizamento estimado máximo da compra sobre a melhor oferta (0 = sem limite)
+}
+
+// RateLimitConfig são os limites de requisições REST da conta na Binance
+type RateLimitConfig struct {
+	Weight int // peso por minuto (REQUEST_WEIGHT)
+	Orders int // ordens a cada 10 segundos (ORDERS)
+}
+
+// DefaultRateLimitConfig retorna os limites padrão do spot: 6000 de peso por minuto
+// e 100 ordens a cada 10 segundos
+func DefaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{Weight: 6000, Orders: 100}
+}
+
+// ClockConfig configura a sincronização com o horário do servidor da Binance, usado
+// no timestamp das requisições assinadas
+type ClockConfig struct {
+	SyncInterval time.Duration // intervalo entre as sincronizações (0 = só na inicialização)
+	RecvWindow   time.Duration // validade das requisições assinadas (recvWindow)
+	MaxDrift     time.Duration // diferença para o servidor a partir da qual o relógio é dado como dessincronizado
+}
+
+// Limite do recvWindow aceito pela Binance
+const MaxRecvWindow = time.Minute
+
+// DefaultClockConfig retorna a sincronização a cada 5 minutos, com recvWindow de 5s
+// e aviso a partir de 1s de diferença
+func DefaultClockConfig() ClockConfig {
+	return ClockConfig{SyncInterval: 5 * time.Minute, RecvWindow: 5 * time.Second, MaxDrift: time.Second}
+}
+
+// OrderRetryConfig é a política de novas tentativas das ordens cujo resultado é
+// desconhecido (timeout, erro de rede ou 5xx da Binance).
+//
+// O envio acontece com o tradeMutex travado, bloqueando a avaliação dos candles.
+// Nenhum reenvio começa depois de MaxWait, mas a espera e a consulta da última
+// tentativa ainda rodam: o bloqueio máximo é MaxWait + Backoff*2^(Attempts-1) +
+// Timeout (27s com os padrões).
+type OrderRetryConfig struct {
+	Attempts int           // tentativas de envio, incluindo a primeira
+	Backoff  time.Duration // espera antes de consultar a ordem, dobrada a cada tentativa
+	Timeout  time.Duration // tempo máximo de cada envio e de cada consulta
+	MaxWait  time.Duration // tempo máximo dos envios somados; depois dele a ordem não é reenviada
+}
+
+// DefaultOrderRetryConfig retorna 3 tentativas, começando com 500ms de espera,
+// 10s de timeout por envio e 15s para os envios somados
+func DefaultOrderRetryConfig() OrderRetryConfig {
+	return OrderRetryConfig{Attempts: 3, Backoff: 500 * time.Millisecond, Timeout: 10 * time.Second, MaxWait: 15 * time.Second}
+}
+
+// BreakerConfig configura o circuit breaker que suspende as compras após falhas
+// seguidas no envio de ordens
+type BreakerConfig struct {
+	Threshold int           // falhas seguidas que abrem o circuito (0 = só os erros que exigem intervenção)
+	Cooldown  time.Duration // tempo até uma nova tentativa com o circuito aberto (0 = só rearme manual)
+}
+
+// DefaultBreakerConfig retorna o circuito aberto após 5 falhas seguidas, com nova
+// tentativa após 15 minutos
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{Threshold: 5, Cooldown: 15 * time.Minute}
+}
+
+// OrderFlowConfig configura os indicadores de fluxo de ordens calculados a partir dos
+// negócios agregados (aggTrade)
+type OrderFlowConfig struct {
+	Enabled      bool
+	Window       time.Duration // janela padrão do delta, da intensidade e dos negócios grandes
+	LargeTrade   float64       // quantidade mínima (BTC) de um negócio grande
+	RequireDelta bool          // só compra com delta positivo na janela
+}
+
+// Modelos de deslizamento das ordens a mercado na corretora simulada
+const (
+	SlippageNone       = "none"       // executa no último preço
+	SlippageFixed      = "fixed"      // SlippageBps contra a ordem
+	SlippageVolatility = "volatility" // VolFactor × amplitude do último candle
+	SlippageBook       = "book"       // percorre o livro de ofertas gravado (depth)
+)
+
+// PaperConfig é o modelo de execução da corretora simulada usada no replay e nos backtests
+type PaperConfig struct {
+	Slippage    string
+	SlippageBps float64       // fixed; também usado pelo book quando o livro não cobre a ordem
+	VolFactor   float64       // fração da amplitude (máxima - mínima) do último candle
+	Latency     time.Duration // atraso entre o envio e a execução das ordens a mercado
+	MakerFee    float64       // ordens limitadas que ficam no livro
+	TakerFee    float64       // ordens a mercado e limitadas executadas na criação
+	BNBDiscount float64       // desconto nas taxas pagas em BNB (0.25 = 25%)
+	MinNotional float64       // valor mínimo da ordem em USDT (filtro NOTIONAL)
+}
+
+// DefaultPaperConfig retorna a execução sem atrito: último preço, taxa de 0.1% e mínimo de 5 USDT
+func DefaultPaperConfig() PaperConfig {
+	return PaperConfig{Slippage: SlippageNone, SlippageBps: 5, VolFactor: 0.1, MakerFee: 0.001, TakerFee: 0.001, MinNotional: 5}
+}
+
+// FeeRate retorna a taxa efetiva de uma execução, já com o desconto em BNB
+func (c PaperConfig) FeeRate(maker bool) float64 {
+	fee := c.TakerFee
+	if maker {
+		fee = c.MakerFee
+	}
+	return fee * (1 - c.BNBDiscount)
+}
+
+// Validate verifica se o modelo de execução é coerente
+func (c PaperConfig) Validate() error {
+	switch c.Slippage {
+	case SlippageNone, SlippageFixed, SlippageVolatility, SlippageBook:
+	default:
+		return fmt.Errorf("modelo de deslizamento inválido: %s (use none, fixed, volatility ou book)", c.Slippage)
+	}
+	switch {
+	case c.SlippageBps < 0 || c.VolFactor < 0:
+		return fmt.Errorf("o deslizamento não pode ser negativo")
+	case c.Latency < 0:
+		return fmt.Errorf("a latência não pode ser negativa")
+	case c.MakerFee < 0 || c.TakerFee < 0 || c.MakerFee >= 1 || c.TakerFee >= 1:
+		return fmt.Errorf("as taxas devem estar entre 0 e 1")
+	case c.BNBDiscount < 0 || c.BNBDiscount >= 1:
+		return fmt.Errorf("o desconto em BNB deve estar entre 0 e 1")
+	case c.MinNotional < 0:
+		return fmt.Errorf("o valor mínimo da ordem não pode ser negativo")
+	}
+	return nil
+}
+
+// loadPaperConfig lê o modelo de execução da corretora simulada, mantendo os padrões não definidos
+func loadPaperConfig() (PaperConfig, error) {
+	c := DefaultPaperConfig()
+	if v := os.Getenv("PAPER_SLIPPAGE"); v != "" {
+		c.Slippage = v
+	}
+	var err error
+	if c.SlippageBps, err = floatFromEnv("PAPER_SLIPPAGE_BPS", c.SlippageBps); err != nil {
+		return c, err
+	}
+	if c.VolFactor, err = floatFromEnv("PAPER_SLIPPAGE_VOL_FACTOR", c.VolFactor); err != nil {
+		return c, err
+	}
+	latencyMs, err := intFromEnv("PAPER_LATENCY_MS", 0)
+	if err != nil {
+		return c, err
+	}
+	c.Latency = time.Duration(latencyMs) * time.Millisecond
+	if c.MakerFee, err = floatFromEnv("PAPER_MAKER_FEE", c.MakerFee); err != nil {
+		return c, err
+	}
+	if c.TakerFee, err = floatFromEnv("PAPER_TAKER_FEE", c.TakerFee); err != nil {
+		return c, err
+	}
+	if c.BNBDiscount, err = floatFromEnv("PAPER_BNB_DISCOUNT", c.BNBDiscount); err != nil {
+		return c, err
+	}
+	if c.MinNotional, err = floatFromEnv("PAPER_MIN_NOTIONAL", c.MinNotional); err != nil {
+		return c, err
+	}
+	if err := c.Validate(); err != nil {
+		return c, fmt.Errorf("corretora simulada: %v", err)
+	}
+	return c, nil
+}
+
+// WebhookConfig configura o webhook que recebe sinais externos (ex. TradingView)
+type WebhookConfig struct {
+	Addr       string  // endereço de escuta, ex. "0.0.0.0:8081" (vazio = desabilitado)
+	Token      string  // token exigido em cada alerta
+	MaxSizePct float64 // maior fração do capital aceita numa compra por sinal externo
+}
+
+// NotifyConfig configura os canais de aviso. Cada canal recebe só os eventos da
+// sua lista: entry, exit, stop, error, kill_switch e disconnect.
+type NotifyConfig struct {
+	WebhookURL    string // recebe um POST com o aviso em JSON (vazio = desabilitado)
+	WebhookEvents []string
+
+	TelegramAPIURL string // URL base da Bot API (padrão https://api.telegram.org)
+	TelegramToken  string
+	TelegramChatID string // vazio = desabilitado
+	TelegramEvents []string
+
+	SMTPAddr     string // host:porta do servidor SMTP (vazio = desabilitado)
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+	SMTPTo       []string
+	SMTPEvents   []string
+
+	MaxPerMinute int               // avisos por minuto de cada tipo de evento (0 = sem limite)
+	Templates    map[string]string // modelos (text/template) por evento, sobrepõem os padrões
+}
+
+// NotifyEvents são os eventos que podem gerar avisos
+var NotifyEvents = []string{"entry", "exit", "stop", "error", "kill_switch", "disconnect"}
+
+// LogConfig configura o logger estruturado e a rotação do bot.log
+type LogConfig struct {
+	Level       string        // nível mínimo: debug, info, warn ou error
+	Format      string        // "text" ou "json"
+	MaxSizeMB   int           // tamanho máximo do arquivo antes da rotação (0 = sem limite)
+	RotateEvery time.Duration // intervalo de rotação por tempo (0 = desabilitada)
+	MaxBackups  int           // arquivos compactados mantidos (0 = todos)
+	PanelLevel  string        // nível inicial do painel de logs da TUI
+}
+
+// RemoteConfig configura o servidor ao qual a TUI remota se conecta
+type RemoteConfig struct {
+	Addr  string // "unix:/caminho.sock", "tcp:host:porta" ou "host:porta" (vazio = desabilitado)
+	Token string // token exigido dos clientes (obrigatório em TCP)
+}
+
+// APIConfig configura a API HTTP de controle e status
+type APIConfig struct {
+	Addr  string // endereço de escuta, ex. "127.0.0.1:8080" (vazio = desabilitada)
+	Token string // token exigido no cabeçalho Authorization: Bearer <token>
+}
+
+// TimeframeRule é uma condição que precisa ser verdadeira em um timeframe para liberar entradas.
+// Formato no .env: intervalo:tipo:período, ex. "1h:ma_slope_up:50"
+type TimeframeRule struct {
+	Interval string // intervalo do kline (1m, 5m, 1h, 4h, 1d...)
+	Kind     string // ma_slope_up, ma_slope_down, close_above_ma, close_below_ma
+	Period   int    // período da média móvel
+}
+
+// String retorna a regra no mesmo formato usado no .env
+func (r TimeframeRule) String() string {
+	return fmt.Sprintf("%s:%s:%d", r.Interval, r.Kind, r.Period)
+}
+
+// intervalos de kline aceitos pela Binance, exceto o mensal "1M", que não tem
+// duração fixa para a agregação dos candles
+var validIntervals = map[string]bool{
+	"1s": true, "1m": true, "3m": true, "5m": true, "15m": true, "30m": true,
+	"1h": true, "2h": true, "4h": true, "6h": true, "8h": true, "12h": true,
+	"1d": true, "3d": true, "1w": true,
+}
+
+var validRuleKinds = map[string]bool{
+	"ma_slope_up": true, "ma_slope_down": true, "close_above_ma": true, "close_below_ma": true,
+}
+
+// DCAConfig agrupa os parâmetros do modo de preço médio (DCA) com ordens de segurança.
+// Os desvios, escalas e o take-profit são frações (0.01 = 1%), como o RISK_PER_TRADE.
+type DCAConfig struct {
+	Enabled          bool
+	MaxSafetyOrders  int     // número máximo de ordens de segurança
+	PriceDeviation   float64 // queda em relação à entrada para a primeira ordem de segurança
+	StepScale        float64 // multiplicador do desvio entre ordens consecutivas
+	SafetyOrderRatio float64 // tamanho da primeira ordem de segurança em relação à ordem base
+	VolumeScale      float64 // multiplicador de volume entre ordens consecutivas
+	TakeProfit       float64 // alvo de lucro sobre o preço médio
+	MaxCapital       float64 // capital máximo comprometido no ciclo em USDT (0 = sem limite)
+}
+
+// RulesConfig guarda as regras de entrada e saída da estratégia "rules".
+// A compilação e a verificação de tipos são feitas pelo trader na inicialização.
+type RulesConfig struct {
+	Entry string
+	Exit  string
+}
+
+// GridConfig agrupa os parâmetros da estratégia de grid
+type GridConfig struct {
+	Enabled      bool
+	LowerPrice   float64       // limite inferior da faixa
+	UpperPrice   float64       // limite superior da faixa
+	Levels       int           // número de níveis de preço (Levels-1 grids)
+	OrderAmount  float64       // valor em USDT de cada ordem do grid
+	PollInterval time.Duration // intervalo entre as verificações de execução das ordens
 }
 
 func LoadFromEnv(envPath string) (*Config, error) {
@@ -24,7 +410,7 @@ func LoadFromEnv(envPath string) (*Config, error) {
 	apiKey := os.Getenv("BINANCE_API_KEY")
 	apiSecret := os.Getenv("BINANCE_API_SECRET")
 	testnet := os.Getenv("USE_TESTNET") == "true"
-	
+
 	initialFunds := 1000.0 // valor padrão
 	if fundsStr := os.Getenv("INITIAL_FUNDS"); fundsStr != "" {
 		initialFunds, err = strconv.ParseFloat(fundsStr, 64)
@@ -32,11 +418,664 @@ func LoadFromEnv(envPath string) (*Config, error) {
 			return nil, fmt.Errorf("erro ao converter INITIAL_FUNDS para float: %v", err)
 		}
 	}
-	
+
+	dca, err := loadDCAConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	strategy := os.Getenv("STRATEGY")
+	if strategy == "" {
+		strategy = "rsi_ma"
+	}
+	if strategy != "rsi_ma" && strategy != "grid" && strategy != "rules" {
+		return nil, fmt.Errorf("STRATEGY inválida: %s (use rsi_ma, grid ou rules)", strategy)
+	}
+
+	var rulesConfig RulesConfig
+	if strategy == "rules" {
+		rulesConfig, err = loadRulesConfig()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	grid, err := loadGridConfig(strategy == "grid")
+	if err != nil {
+		return nil, err
+	}
+	if grid.Enabled && dca.Enabled {
+		return nil, fmt.Errorf("STRATEGY=grid não pode ser usada com DCA_ENABLED=true")
+	}
+
+	signalInterval := os.Getenv("SIGNAL_INTERVAL")
+	if signalInterval == "" {
+		signalInterval = "1s"
+	}
+	if !validIntervals[signalInterval] {
+		return nil, fmt.Errorf("SIGNAL_INTERVAL inválido: %s", signalInterval)
+	}
+
+	confirmations, err := parseTimeframeRules(os.Getenv("MTF_CONFIRM"))
+	if err != nil {
+		return nil, err
+	}
+
+	candleCapacity, err := intFromEnv("CANDLE_CAPACITY", 500)
+	if err != nil {
+		return nil, err
+	}
+	if candleCapacity < 30 {
+		return nil, fmt.Errorf("CANDLE_CAPACITY deve ser pelo menos 30 para os indicadores")
+	}
+
+	initialPosition := os.Getenv("INITIAL_POSITION")
+	if initialPosition == "" {
+		initialPosition = "auto"
+	}
+	if initialPosition != "auto" && initialPosition != "flat" && initialPosition != "long" {
+		return nil, fmt.Errorf("INITIAL_POSITION inválida: %s (use auto, flat ou long)", initialPosition)
+	}
+	initialEntryPrice, err := floatFromEnv("INITIAL_ENTRY_PRICE", 0)
+	if err != nil {
+		return nil, err
+	}
+	initialQuantity, err := floatFromEnv("INITIAL_QUANTITY", 0)
+	if err != nil {
+		return nil, err
+	}
+	if initialQuantity < 0 {
+		return nil, fmt.Errorf("INITIAL_QUANTITY não pode ser negativa")
+	}
+
+	api := APIConfig{
+		Addr:  os.Getenv("API_ADDR"),
+		Token: os.Getenv("API_TOKEN"),
+	}
+	if api.Addr != "" && api.Token == "" {
+		return nil, fmt.Errorf("API_TOKEN é obrigatório quando API_ADDR está definido")
+	}
+
+	remote := RemoteConfig{
+		Addr:  os.Getenv("REMOTE_ADDR"),
+		Token: os.Getenv("REMOTE_TOKEN"),
+	}
+	if remote.Addr != "" && !strings.HasPrefix(remote.Addr, "unix:") && remote.Token == "" {
+		return nil, fmt.Errorf("REMOTE_TOKEN é obrigatório quando REMOTE_ADDR é TCP")
+	}
+
+	logConfig, err := loadLogConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	notify, err := loadNotifyConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook := WebhookConfig{
+		Addr:  os.Getenv("SIGNAL_WEBHOOK_ADDR"),
+		Token: os.Getenv("SIGNAL_WEBHOOK_TOKEN"),
+	}
+	if webhook.MaxSizePct, err = floatFromEnv("SIGNAL_MAX_SIZE_PCT", 0.25); err != nil {
+		return nil, err
+	}
+	if webhook.Addr != "" && webhook.Token == "" {
+		return nil, fmt.Errorf("SIGNAL_WEBHOOK_TOKEN é obrigatório quando SIGNAL_WEBHOOK_ADDR está definido")
+	}
+	if webhook.MaxSizePct <= 0 || webhook.MaxSizePct > 1 {
+		return nil, fmt.Errorf("SIGNAL_MAX_SIZE_PCT deve estar entre 0 e 1")
+	}
+
+	equitySeconds, err := intFromEnv("EQUITY_SNAPSHOT_INTERVAL", 60)
+	if err != nil {
+		return nil, err
+	}
+	if equitySeconds < 0 {
+		return nil, fmt.Errorf("EQUITY_SNAPSHOT_INTERVAL não pode ser negativo")
+	}
+
+	signal, err := loadSignalParams()
+	if err != nil {
+		return nil, err
+	}
+	// A série dos sinais precisa de mais candles que o maior período dos indicadores
+	if needed := max(signal.MALong, signal.RSIPeriod+1); candleCapacity <= needed {
+		return nil, fmt.Errorf("CANDLE_CAPACITY (%d) deve ser maior que %d (MA_LONG=%d, RSI_PERIOD=%d)",
+			candleCapacity, needed, signal.MALong, signal.RSIPeriod)
+	}
+
+	record := RecordConfig{Dir: os.Getenv("RECORD_DIR")}
+	for _, stream := range splitList(os.Getenv("RECORD_STREAMS")) {
+		switch stream {
+		case "kline":
+			// os klines são sempre gravados
+		case "depth", "aggTrade":
+			record.Streams = append(record.Streams, stream)
+		default:
+			return nil, fmt.Errorf("stream inválido em RECORD_STREAMS: %s (use kline, depth ou aggTrade)", stream)
+		}
+	}
+
+	paper, err := loadPaperConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	depth := DepthConfig{Enabled: os.Getenv("DEPTH_ENABLED") == "true"}
+	if depth.MaxSpreadBps, err = floatFromEnv("MAX_SPREAD_BPS", 5); err != nil {
+		return nil, err
+	}
+	if depth.MaxSlippageBps, err = floatFromEnv("MAX_SLIPPAGE_BPS", 10); err != nil {
+		return nil, err
+	}
+	if depth.MaxSpreadBps < 0 || depth.MaxSlippageBps < 0 {
+		return nil, fmt.Errorf("MAX_SPREAD_BPS e MAX_SLIPPAGE_BPS não podem ser negativos")
+	}
+
+	flow, err := loadOrderFlowConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimit := DefaultRateLimitConfig()
+	if rateLimit.Weight, err = intFromEnv("RATE_LIMIT_WEIGHT", rateLimit.Weight); err != nil {
+		return nil, err
+	}
+	if rateLimit.Orders, err = intFromEnv("RATE_LIMIT_ORDERS", rateLimit.Orders); err != nil {
+		return nil, err
+	}
+	if rateLimit.Weight < 1 || rateLimit.Orders < 1 {
+		return nil, fmt.Errorf("RATE_LIMIT_WEIGHT e RATE_LIMIT_ORDERS devem ser positivos")
+	}
+
+	clock, err := loadClockConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	retry, err := loadOrderRetryConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	breaker := DefaultBreakerConfig()
+	if breaker.Threshold, err = intFromEnv("BREAKER_THRESHOLD", breaker.Threshold); err != nil {
+		return nil, err
+	}
+	cooldownSeconds, err := intFromEnv("BREAKER_COOLDOWN", int(breaker.Cooldown.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+	breaker.Cooldown = time.Duration(cooldownSeconds) * time.Second
+	if breaker.Threshold < 0 || breaker.Cooldown < 0 {
+		return nil, fmt.Errorf("BREAKER_THRESHOLD e BREAKER_COOLDOWN não podem ser negativos")
+	}
+
 	return &Config{
-		ApiKey:       apiKey,
-		ApiSecret:    apiSecret,
-		Testnet:      testnet,
-		InitialFunds: initialFunds,
+		ApiKey:         apiKey,
+		ApiSecret:      apiSecret,
+		Testnet:        testnet,
+		InitialFunds:   initialFunds,
+		DCA:            dca,
+		Strategy:       strategy,
+		Grid:           grid,
+		Rules:          rulesConfig,
+		Signal:         signal,
+		SignalInterval: signalInterval,
+		CandleCapacity: candleCapacity,
+		Confirmations:  confirmations,
+
+		InitialPosition:   initialPosition,
+		InitialEntryPrice: initialEntryPrice,
+		InitialQuantity:   initialQuantity,
+
+		API:         api,
+		Remote:      remote,
+		MetricsAddr: os.Getenv("METRICS_ADDR"),
+		Log:         logConfig,
+		Notify:      notify,
+		Webhook:     webhook,
+
+		EquityInterval: time.Duration(equitySeconds) * time.Second,
+
+		Record: record,
+		Paper:  paper,
+		Depth:  depth,
+		Flow:   flow,
+
+		RateLimit: rateLimit,
+		Clock:     clock,
+		Retry:     retry,
+		Breaker:   breaker,
 	}, nil
 }
+
+func loadClockConfig() (ClockConfig, error) {
+	clock := DefaultClockConfig()
+	syncSeconds, err := intFromEnv("TIME_SYNC_INTERVAL", int(clock.SyncInterval.Seconds()))
+	if err != nil {
+		return clock, err
+	}
+	recvWindowMs, err := intFromEnv("RECV_WINDOW", int(clock.RecvWindow.Milliseconds()))
+	if err != nil {
+		return clock, err
+	}
+	maxDriftMs, err := intFromEnv("MAX_CLOCK_DRIFT", int(clock.MaxDrift.Milliseconds()))
+	if err != nil {
+		return clock, err
+	}
+	clock.SyncInterval = time.Duration(syncSeconds) * time.Second
+	clock.RecvWindow = time.Duration(recvWindowMs) * time.Millisecond
+	clock.MaxDrift = time.Duration(maxDriftMs) * time.Millisecond
+
+	if clock.SyncInterval < 0 {
+		return clock, fmt.Errorf("TIME_SYNC_INTERVAL não pode ser negativo")
+	}
+	if clock.RecvWindow <= 0 || clock.RecvWindow > MaxRecvWindow {
+		return clock, fmt.Errorf("RECV_WINDOW deve estar entre 1 e %d ms", MaxRecvWindow.Milliseconds())
+	}
+	if clock.MaxDrift <= 0 {
+		return clock, fmt.Errorf("MAX_CLOCK_DRIFT deve ser positivo")
+	}
+	return clock, nil
+}
+
+func loadOrderRetryConfig() (OrderRetryConfig, error) {
+	retry := DefaultOrderRetryConfig()
+	var err error
+	if retry.Attempts, err = intFromEnv("ORDER_RETRY_ATTEMPTS", retry.Attempts); err != nil {
+		return retry, err
+	}
+	backoffMs, err := intFromEnv("ORDER_RETRY_BACKOFF", int(retry.Backoff.Milliseconds()))
+	if err != nil {
+		return retry, err
+	}
+	timeoutSeconds, err := intFromEnv("ORDER_TIMEOUT", int(retry.Timeout.Seconds()))
+	if err != nil {
+		return retry, err
+	}
+	maxWaitSeconds, err := intFromEnv("ORDER_RETRY_MAX_WAIT", int(retry.MaxWait.Seconds()))
+	if err != nil {
+		return retry, err
+	}
+	retry.Backoff = time.Duration(backoffMs) * time.Millisecond
+	retry.Timeout = time.Duration(timeoutSeconds) * time.Second
+	retry.MaxWait = time.Duration(maxWaitSeconds) * time.Second
+
+	if retry.Attempts < 1 {
+		return retry, fmt.Errorf("ORDER_RETRY_ATTEMPTS deve ser pelo menos 1")
+	}
+	if retry.Backoff < 0 {
+		return retry, fmt.Errorf("ORDER_RETRY_BACKOFF não pode ser negativo")
+	}
+	if retry.Timeout <= 0 {
+		return retry, fmt.Errorf("ORDER_TIMEOUT deve ser positivo")
+	}
+	if retry.MaxWait < retry.Timeout {
+		return retry, fmt.Errorf("ORDER_RETRY_MAX_WAIT (%s) deve ser pelo menos ORDER_TIMEOUT (%s)", retry.MaxWait, retry.Timeout)
+	}
+	return retry, nil
+}
+
+// DefaultOrderFlowConfig retorna o fluxo de ordens desabilitado, com janela de 1 minuto
+// e negócios grandes a partir de 1 BTC
+func DefaultOrderFlowConfig() OrderFlowConfig {
+	return OrderFlowConfig{Window: time.Minute, LargeTrade: 1}
+}
+
+// loadOrderFlowConfig lê ORDERFLOW_*; ORDERFLOW_REQUIRE_DELTA também habilita o stream
+func loadOrderFlowConfig() (OrderFlowConfig, error) {
+	flow := DefaultOrderFlowConfig()
+	flow.RequireDelta = os.Getenv("ORDERFLOW_REQUIRE_DELTA") == "true"
+	flow.Enabled = os.Getenv("ORDERFLOW_ENABLED") == "true" || flow.RequireDelta
+
+	windowSeconds, err := intFromEnv("ORDERFLOW_WINDOW", int(flow.Window.Seconds()))
+	if err != nil {
+		return flow, err
+	}
+	if windowSeconds < 1 || windowSeconds > MaxOrderFlowWindow {
+		return flow, fmt.Errorf("ORDERFLOW_WINDOW deve estar entre 1 e %d segundos", MaxOrderFlowWindow)
+	}
+	flow.Window = time.Duration(windowSeconds) * time.Second
+
+	if flow.LargeTrade, err = floatFromEnv("ORDERFLOW_LARGE_TRADE", flow.LargeTrade); err != nil {
+		return flow, err
+	}
+	if flow.LargeTrade <= 0 {
+		return flow, fmt.Errorf("ORDERFLOW_LARGE_TRADE deve ser positivo")
+	}
+	return flow, nil
+}
+
+// MaxOrderFlowWindow é a maior janela (em segundos) dos indicadores de fluxo de ordens
+const MaxOrderFlowWindow = 3600
+
+// parseTimeframeRules interpreta a lista MTF_CONFIRM separada por vírgulas
+func parseTimeframeRules(value string) ([]TimeframeRule, error) {
+	var rules []TimeframeRule
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		parts := strings.Split(item, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("regra MTF_CONFIRM inválida %q: use intervalo:tipo:período", item)
+		}
+		if !validIntervals[parts[0]] {
+			return nil, fmt.Errorf("regra MTF_CONFIRM %q: intervalo inválido %s", item, parts[0])
+		}
+		if !validRuleKinds[parts[1]] {
+			return nil, fmt.Errorf("regra MTF_CONFIRM %q: tipo inválido %s", item, parts[1])
+		}
+		period, err := strconv.Atoi(parts[2])
+		if err != nil || period < 2 {
+			return nil, fmt.Errorf("regra MTF_CONFIRM %q: período inválido %s", item, parts[2])
+		}
+
+		rules = append(rules, TimeframeRule{Interval: parts[0], Kind: parts[1], Period: period})
+	}
+	return rules, nil
+}
+
+var validLogLevels = map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
+
+// DefaultLogConfig retorna a configuração de log usada quando não há .env
+func DefaultLogConfig() LogConfig {
+	return LogConfig{
+		Level:       "info",
+		Format:      "text",
+		MaxSizeMB:   10,
+		RotateEvery: 24 * time.Hour,
+		MaxBackups:  7,
+		PanelLevel:  "info",
+	}
+}
+
+func loadLogConfig() (LogConfig, error) {
+	cfg := DefaultLogConfig()
+	if level := os.Getenv("LOG_LEVEL"); level != "" {
+		cfg.Level = strings.ToLower(level)
+	}
+	if format := os.Getenv("LOG_FORMAT"); format != "" {
+		cfg.Format = strings.ToLower(format)
+	}
+	if level := os.Getenv("LOG_PANEL_LEVEL"); level != "" {
+		cfg.PanelLevel = strings.ToLower(level)
+	}
+
+	var err error
+	if cfg.MaxSizeMB, err = intFromEnv("LOG_MAX_SIZE_MB", cfg.MaxSizeMB); err != nil {
+		return cfg, err
+	}
+	rotateHours, err := intFromEnv("LOG_ROTATE_HOURS", int(cfg.RotateEvery.Hours()))
+	if err != nil {
+		return cfg, err
+	}
+	cfg.RotateEvery = time.Duration(rotateHours) * time.Hour
+	if cfg.MaxBackups, err = intFromEnv("LOG_MAX_BACKUPS", cfg.MaxBackups); err != nil {
+		return cfg, err
+	}
+
+	if !validLogLevels[cfg.Level] {
+		return cfg, fmt.Errorf("LOG_LEVEL inválido: %s (use debug, info, warn ou error)", cfg.Level)
+	}
+	if !validLogLevels[cfg.PanelLevel] {
+		return cfg, fmt.Errorf("LOG_PANEL_LEVEL inválido: %s (use debug, info, warn ou error)", cfg.PanelLevel)
+	}
+	if cfg.Format != "text" && cfg.Format != "json" {
+		return cfg, fmt.Errorf("LOG_FORMAT inválido: %s (use text ou json)", cfg.Format)
+	}
+	if cfg.MaxSizeMB < 0 || rotateHours < 0 || cfg.MaxBackups < 0 {
+		return cfg, fmt.Errorf("LOG_MAX_SIZE_MB, LOG_ROTATE_HOURS e LOG_MAX_BACKUPS não podem ser negativos")
+	}
+	return cfg, nil
+}
+
+func loadNotifyConfig() (NotifyConfig, error) {
+	cfg := NotifyConfig{
+		WebhookURL:     os.Getenv("NOTIFY_WEBHOOK_URL"),
+		TelegramAPIURL: os.Getenv("NOTIFY_TELEGRAM_API_URL"),
+		TelegramToken:  os.Getenv("NOTIFY_TELEGRAM_TOKEN"),
+		TelegramChatID: os.Getenv("NOTIFY_TELEGRAM_CHAT_ID"),
+		SMTPAddr:       os.Getenv("NOTIFY_SMTP_ADDR"),
+		SMTPUsername:   os.Getenv("NOTIFY_SMTP_USERNAME"),
+		SMTPPassword:   os.Getenv("NOTIFY_SMTP_PASSWORD"),
+		SMTPFrom:       os.Getenv("NOTIFY_SMTP_FROM"),
+		SMTPTo:         splitList(os.Getenv("NOTIFY_SMTP_TO")),
+		Templates:      make(map[string]string),
+	}
+	if cfg.TelegramAPIURL == "" {
+		cfg.TelegramAPIURL = "https://api.telegram.org"
+	}
+
+	var err error
+	if cfg.WebhookEvents, err = notifyEventsFromEnv("NOTIFY_WEBHOOK_EVENTS"); err != nil {
+		return cfg, err
+	}
+	if cfg.TelegramEvents, err = notifyEventsFromEnv("NOTIFY_TELEGRAM_EVENTS"); err != nil {
+		return cfg, err
+	}
+	if cfg.SMTPEvents, err = notifyEventsFromEnv("NOTIFY_SMTP_EVENTS"); err != nil {
+		return cfg, err
+	}
+	if cfg.MaxPerMinute, err = intFromEnv("NOTIFY_MAX_PER_MINUTE", 6); err != nil {
+		return cfg, err
+	}
+	if cfg.MaxPerMinute < 0 {
+		return cfg, fmt.Errorf("NOTIFY_MAX_PER_MINUTE não pode ser negativo")
+	}
+
+	for _, event := range NotifyEvents {
+		if tmpl := os.Getenv("NOTIFY_TEMPLATE_" + strings.ToUpper(event)); tmpl != "" {
+			cfg.Templates[event] = tmpl
+		}
+	}
+
+	if cfg.TelegramChatID != "" && cfg.TelegramToken == "" {
+		return cfg, fmt.Errorf("NOTIFY_TELEGRAM_TOKEN é obrigatório quando NOTIFY_TELEGRAM_CHAT_ID está definido")
+	}
+	if cfg.SMTPAddr != "" && (cfg.SMTPFrom == "" || len(cfg.SMTPTo) == 0) {
+		return cfg, fmt.Errorf("NOTIFY_SMTP_FROM e NOTIFY_SMTP_TO são obrigatórios quando NOTIFY_SMTP_ADDR está definido")
+	}
+	return cfg, nil
+}
+
+// notifyEventsFromEnv lê uma lista de eventos separada por vírgulas (vazia = todos)
+func notifyEventsFromEnv(name string) ([]string, error) {
+	events := splitList(os.Getenv(name))
+	if len(events) == 0 {
+		return NotifyEvents, nil
+	}
+	for _, event := range events {
+		valid := false
+		for _, known := range NotifyEvents {
+			valid = valid || event == known
+		}
+		if !valid {
+			return nil, fmt.Errorf("%s: evento inválido %s (use %s)", name, event, strings.Join(NotifyEvents, ", "))
+		}
+	}
+	return events, nil
+}
+
+// splitList separa uma lista por vírgulas, ignorando itens vazios
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// loadRulesConfig lê as regras de STRATEGY_ENTRY/STRATEGY_EXIT ou de um arquivo
+// indicado em STRATEGY_RULES_FILE, com linhas "entry: ..." e "exit: ..."
+func loadRulesConfig() (RulesConfig, error) {
+	rules := RulesConfig{
+		Entry: strings.TrimSpace(os.Getenv("STRATEGY_ENTRY")),
+		Exit:  strings.TrimSpace(os.Getenv("STRATEGY_EXIT")),
+	}
+
+	if path := os.Getenv("STRATEGY_RULES_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return rules, fmt.Errorf("erro ao ler STRATEGY_RULES_FILE: %v", err)
+		}
+		for i, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				return rules, fmt.Errorf("%s linha %d: use \"entry: <regra>\" ou \"exit: <regra>\"", path, i+1)
+			}
+			switch strings.TrimSpace(key) {
+			case "entry":
+				rules.Entry = strings.TrimSpace(value)
+			case "exit":
+				rules.Exit = strings.TrimSpace(value)
+			default:
+				return rules, fmt.Errorf("%s linha %d: chave desconhecida %q", path, i+1, strings.TrimSpace(key))
+			}
+		}
+	}
+
+	if rules.Entry == "" || rules.Exit == "" {
+		return rules, fmt.Errorf("STRATEGY=rules exige as regras de entrada e saída (STRATEGY_ENTRY/STRATEGY_EXIT ou STRATEGY_RULES_FILE)")
+	}
+	return rules, nil
+}
+
+func loadGridConfig(enabled bool) (GridConfig, error) {
+	grid := GridConfig{
+		Enabled:      enabled,
+		Levels:       10,
+		OrderAmount:  20,
+		PollInterval: 5 * time.Second,
+	}
+	if !enabled {
+		return grid, nil
+	}
+
+	var err error
+	if grid.LowerPrice, err = floatFromEnv("GRID_LOWER_PRICE", 0); err != nil {
+		return grid, err
+	}
+	if grid.UpperPrice, err = floatFromEnv("GRID_UPPER_PRICE", 0); err != nil {
+		return grid, err
+	}
+	if grid.Levels, err = intFromEnv("GRID_LEVELS", grid.Levels); err != nil {
+		return grid, err
+	}
+	if grid.OrderAmount, err = floatFromEnv("GRID_ORDER_AMOUNT", grid.OrderAmount); err != nil {
+		return grid, err
+	}
+	pollSeconds, err := intFromEnv("GRID_POLL_INTERVAL", int(grid.PollInterval.Seconds()))
+	if err != nil {
+		return grid, err
+	}
+	grid.PollInterval = time.Duration(pollSeconds) * time.Second
+
+	if grid.LowerPrice <= 0 || grid.UpperPrice <= grid.LowerPrice {
+		return grid, fmt.Errorf("GRID_LOWER_PRICE e GRID_UPPER_PRICE devem formar uma faixa válida")
+	}
+	if grid.Levels < 2 {
+		return grid, fmt.Errorf("GRID_LEVELS deve ser pelo menos 2")
+	}
+	if grid.OrderAmount < 11 {
+		return grid, fmt.Errorf("GRID_ORDER_AMOUNT deve ser pelo menos 11 USDT (valor mínimo de ordem)")
+	}
+	if grid.PollInterval <= 0 {
+		return grid, fmt.Errorf("GRID_POLL_INTERVAL deve ser maior que zero")
+	}
+
+	return grid, nil
+}
+
+func loadDCAConfig() (DCAConfig, error) {
+	dca := DCAConfig{
+		Enabled:          os.Getenv("DCA_ENABLED") == "true",
+		MaxSafetyOrders:  5,
+		PriceDeviation:   0.01,
+		StepScale:        1.0,
+		SafetyOrderRatio: 1.0,
+		VolumeScale:      1.5,
+		TakeProfit:       0.01,
+	}
+
+	var err error
+	if dca.MaxSafetyOrders, err = intFromEnv("DCA_MAX_SAFETY_ORDERS", dca.MaxSafetyOrders); err != nil {
+		return dca, err
+	}
+	if dca.PriceDeviation, err = floatFromEnv("DCA_PRICE_DEVIATION", dca.PriceDeviation); err != nil {
+		return dca, err
+	}
+	if dca.StepScale, err = floatFromEnv("DCA_STEP_SCALE", dca.StepScale); err != nil {
+		return dca, err
+	}
+	if dca.SafetyOrderRatio, err = floatFromEnv("DCA_SAFETY_ORDER_RATIO", dca.SafetyOrderRatio); err != nil {
+		return dca, err
+	}
+	if dca.VolumeScale, err = floatFromEnv("DCA_VOLUME_SCALE", dca.VolumeScale); err != nil {
+		return dca, err
+	}
+	if dca.TakeProfit, err = floatFromEnv("DCA_TAKE_PROFIT", dca.TakeProfit); err != nil {
+		return dca, err
+	}
+	if dca.MaxCapital, err = floatFromEnv("DCA_MAX_CAPITAL", dca.MaxCapital); err != nil {
+		return dca, err
+	}
+
+	if dca.MaxSafetyOrders < 0 {
+		return dca, fmt.Errorf("DCA_MAX_SAFETY_ORDERS não pode ser negativo")
+	}
+	if dca.PriceDeviation <= 0 || dca.PriceDeviation >= 1 {
+		return dca, fmt.Errorf("DCA_PRICE_DEVIATION deve estar entre 0 e 1")
+	}
+	if dca.TakeProfit <= 0 {
+		return dca, fmt.Errorf("DCA_TAKE_PROFIT deve ser maior que zero")
+	}
+	if dca.StepScale <= 0 {
+		return dca, fmt.Errorf("DCA_STEP_SCALE deve ser maior que zero")
+	}
+	if dca.VolumeScale <= 0 {
+		return dca, fmt.Errorf("DCA_VOLUME_SCALE deve ser maior que zero")
+	}
+	if dca.SafetyOrderRatio <= 0 {
+		return dca, fmt.Errorf("DCA_SAFETY_ORDER_RATIO deve ser maior que zero")
+	}
+
+	return dca, nil
+}
+
+// floatFromEnv lê uma variável de ambiente numérica, usando o valor padrão se estiver vazia
+func floatFromEnv(name string, def float64) (float64, error) {
+	str := os.Getenv(name)
+	if str == "" {
+		return def, nil
+	}
+	value, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao converter %s para float: %v", name, err)
+	}
+	return value, nil
+}
+
+// intFromEnv lê uma variável de ambiente inteira, usando o valor padrão se estiver vazia
+func intFromEnv(name string, def int) (int, error) {
+	str := os.Getenv(name)
+	if str == "" {
+		return def, nil
+	}
+	value, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao converter %s para inteiro: %v", name, err)
+	}
+	return value, nil
+}
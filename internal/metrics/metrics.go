@@ -0,0 +1,218 @@
+// Package metrics implementa o mínimo do formato de exposição de texto do
+// Prometheus: contadores e gauges, com ou sem labels.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registry guarda as métricas e as escreve no formato do Prometheus
+type Registry struct {
+	mu        sync.Mutex
+	metrics   []metric
+	onCollect []func()
+}
+
+type metric interface {
+	write(w io.Writer)
+}
+
+// NewRegistry cria um registro vazio
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, m)
+}
+
+// OnCollect registra uma função chamada antes de cada coleta, para atualizar
+// vários gauges a partir de uma única leitura do estado
+func (r *Registry) OnCollect(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onCollect = append(r.onCollect, fn)
+}
+
+// Write escreve todas as métricas na ordem em que foram registradas
+func (r *Registry) Write(w io.Writer) {
+	r.mu.Lock()
+	metrics := append([]metric(nil), r.metrics...)
+	hooks := append([]func(){}, r.onCollect...)
+	r.mu.Unlock()
+
+	for _, fn := range hooks {
+		fn()
+	}
+	for _, m := range metrics {
+		m.write(w)
+	}
+}
+
+// Handler retorna o handler HTTP do endpoint /metrics
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.Write(w)
+	})
+}
+
+// vec guarda os valores de uma métrica por combinação de labels
+type vec struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+	mu     sync.Mutex
+	values map[string]float64 // chave: valores dos labels separados por \xff
+}
+
+func newVec(r *Registry, name, help, kind string, labels []string) *vec {
+	v := &vec{name: name, help: help, kind: kind, labels: labels, values: make(map[string]float64)}
+	r.register(v)
+	return v
+}
+
+func (v *vec) key(values []string) string {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("métrica %s espera %d labels, recebeu %d", v.name, len(v.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+func (v *vec) add(delta float64, values []string) {
+	k := v.key(values)
+	v.mu.Lock()
+	v.values[k] += delta
+	v.mu.Unlock()
+}
+
+func (v *vec) set(value float64, values []string) {
+	k := v.key(values)
+	v.mu.Lock()
+	v.values[k] = value
+	v.mu.Unlock()
+}
+
+func (v *vec) write(w io.Writer) {
+	v.mu.Lock()
+	keys := make([]string, 0, len(v.values))
+	for k := range v.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	values := make([]float64, len(keys))
+	for i, k := range keys {
+		values[i] = v.values[k]
+	}
+	v.mu.Unlock()
+
+	writeHeader(w, v.name, v.help, v.kind)
+	// Métricas sem labels aparecem com zero antes do primeiro valor
+	if len(v.labels) == 0 && len(keys) == 0 {
+		keys, values = []string{""}, []float64{0}
+	}
+	for i, k := range keys {
+		var labelValues []string
+		if len(v.labels) > 0 {
+			labelValues = strings.Split(k, "\xff")
+		}
+		writeSample(w, v.name, v.labels, labelValues, values[i])
+	}
+}
+
+// Counter é um contador sem labels
+type Counter struct{ v *vec }
+
+// NewCounter registra um contador
+func (r *Registry) NewCounter(name, help string) *Counter {
+	return &Counter{v: newVec(r, name, help, "counter", nil)}
+}
+
+// Inc incrementa o contador
+func (c *Counter) Inc() { c.v.add(1, nil) }
+
+// CounterVec é um contador com labels
+type CounterVec struct{ v *vec }
+
+// NewCounterVec registra um contador com os labels informados
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{v: newVec(r, name, help, "counter", labels)}
+}
+
+// Inc incrementa o contador da combinação de labels
+func (c *CounterVec) Inc(labelValues ...string) { c.v.add(1, labelValues) }
+
+// Gauge é um valor que sobe e desce, sem labels
+type Gauge struct{ v *vec }
+
+// NewGauge registra um gauge
+func (r *Registry) NewGauge(name, help string) *Gauge {
+	return &Gauge{v: newVec(r, name, help, "gauge", nil)}
+}
+
+// Set define o valor do gauge
+func (g *Gauge) Set(value float64) { g.v.set(value, nil) }
+
+// GaugeVec é um gauge com labels
+type GaugeVec struct{ v *vec }
+
+// NewGaugeVec registra um gauge com os labels informados
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{v: newVec(r, name, help, "gauge", labels)}
+}
+
+// Set define o valor do gauge para a combinação de labels
+func (g *GaugeVec) Set(value float64, labelValues ...string) { g.v.set(value, labelValues) }
+
+func writeHeader(w io.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, escapeHelp(help), name, kind)
+}
+
+func writeSample(w io.Writer, name string, labels, values []string, value float64) {
+	var b strings.Builder
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(&b, "%s=\"%s\"", label, escapeLabel(values[i]))
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(formatValue(value))
+	b.WriteByte('\n')
+	io.WriteString(w, b.String())
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
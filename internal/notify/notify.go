@@ -0,0 +1,267 @@
+// Package notify envia avisos de eventos do bot (entradas, saídas, stops, erros)
+// para canais externos: webhook JSON, e-mail via SMTP e Telegram.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/casarotto/binance-bot/internal/config"
+)
+
+// Event é o tipo de evento que gera um aviso
+type Event string
+
+const (
+	EventEntry      Event = "entry"       // compra executada
+	EventExit       Event = "exit"        // venda executada por sinal
+	EventStop       Event = "stop"        // venda executada pelo stop loss
+	EventError      Event = "error"       // erro ao executar uma ordem
+	EventKillSwitch Event = "kill_switch" // posição encerrada ou operação interrompida por segurança
+	EventDisconnect Event = "disconnect"  // conexão com a Binance perdida
+)
+
+// modelos padrão; os campos disponíveis dependem do evento
+var defaultTemplates = map[Event]string{
+	EventEntry:      `🟢 Compra executada - Preço: ${{printf "%.2f" .price}}, Quantidade: {{printf "%.5f" .quantity}} BTC`,
+	EventExit:       `🔴 Venda executada - Preço: ${{printf "%.2f" .price}}, Quantidade: {{printf "%.5f" .quantity}} BTC, Lucro: {{printf "%.2f" .profit_pct}}%`,
+	EventStop:       `⚠️ Stop loss executado - Preço: ${{printf "%.2f" .price}}, Quantidade: {{printf "%.5f" .quantity}} BTC, Resultado: {{printf "%.2f" .profit_pct}}%`,
+	EventError:      `❌ {{.error}}`,
+	EventKillSwitch: `🚨 {{.reason}}`,
+	EventDisconnect: `🔌 Conexão com a Binance perdida: {{.error}} - reconectando em {{.backoff}}`,
+}
+
+// Message é um aviso pronto para envio
+type Message struct {
+	Event      Event          `json:"event"`
+	Time       time.Time      `json:"time"`
+	Text       string         `json:"text"`
+	Fields     map[string]any `json:"fields,omitempty"`
+	Suppressed int            `json:"suppressed,omitempty"` // avisos do mesmo evento descartados pelo limite desde o último envio
+}
+
+// Sink é um canal de entrega de avisos
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, msg Message) error
+}
+
+// route liga um canal aos eventos que ele deve receber
+type route struct {
+	sink   Sink
+	events map[Event]bool
+}
+
+// tempo máximo de entrega de um aviso em cada canal
+const sendTimeout = 15 * time.Second
+
+// Notifier formata os eventos, aplica o limite de avisos por minuto e entrega em
+// segundo plano para os canais inscritos, sem bloquear quem notifica
+type Notifier struct {
+	routes       []route
+	templates    map[Event]*template.Template
+	maxPerMinute int
+	onError      func(error)
+
+	mu         sync.Mutex
+	sent       map[Event][]time.Time // envios do último minuto por evento
+	suppressed map[Event]int
+	closed     bool
+
+	queue chan Message
+	done  chan struct{}
+}
+
+// New cria o notificador com os canais configurados. Sem canais, Notify não faz nada.
+func New(cfg config.NotifyConfig) (*Notifier, error) {
+	n := &Notifier{
+		templates:    make(map[Event]*template.Template),
+		maxPerMinute: cfg.MaxPerMinute,
+		sent:         make(map[Event][]time.Time),
+		suppressed:   make(map[Event]int),
+		queue:        make(chan Message, 100),
+		done:         make(chan struct{}),
+	}
+
+	for event, text := range defaultTemplates {
+		if custom, ok := cfg.Templates[string(event)]; ok {
+			text = custom
+		}
+		tmpl, err := template.New(string(event)).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("modelo inválido para o evento %s: %v", event, err)
+		}
+		n.templates[event] = tmpl
+	}
+
+	if cfg.WebhookURL != "" {
+		n.addRoute(NewWebhookSink(cfg.WebhookURL), cfg.WebhookEvents)
+	}
+	if cfg.TelegramChatID != "" {
+		n.addRoute(NewTelegramSink(cfg.TelegramAPIURL, cfg.TelegramToken, cfg.TelegramChatID), cfg.TelegramEvents)
+	}
+	if cfg.SMTPAddr != "" {
+		n.addRoute(NewSMTPSink(cfg.SMTPAddr, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom, cfg.SMTPTo), cfg.SMTPEvents)
+	}
+
+	go n.loop()
+	return n, nil
+}
+
+func (n *Notifier) addRoute(sink Sink, events []string) {
+	r := route{sink: sink, events: make(map[Event]bool, len(events))}
+	for _, event := range events {
+		r.events[Event(event)] = true
+	}
+	n.routes = append(n.routes, r)
+}
+
+// Sinks retorna os nomes dos canais configurados
+func (n *Notifier) Sinks() []string {
+	names := make([]string, len(n.routes))
+	for i, r := range n.routes {
+		names[i] = r.sink.Name()
+	}
+	return names
+}
+
+// SetErrorHandler define a função chamada quando a entrega em um canal falha
+func (n *Notifier) SetErrorHandler(fn func(error)) {
+	n.onError = fn
+}
+
+// Notify formata e enfileira um aviso. Avisos acima do limite por minuto são
+// descartados e contados no próximo envio do mesmo evento.
+func (n *Notifier) Notify(event Event, fields map[string]any) {
+	if !n.subscribed(event) {
+		return
+	}
+
+	now := time.Now()
+	msg := n.format(event, now, fields)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed {
+		return
+	}
+	if n.maxPerMinute > 0 {
+		recent := n.sent[event][:0]
+		for _, t := range n.sent[event] {
+			if now.Sub(t) < time.Minute {
+				recent = append(recent, t)
+			}
+		}
+		n.sent[event] = recent
+		if len(recent) >= n.maxPerMinute {
+			n.suppressed[event]++
+			return
+		}
+		n.sent[event] = append(recent, now)
+	}
+	msg.Suppressed = n.suppressed[event]
+	n.suppressed[event] = 0
+
+	select {
+	case n.queue <- msg:
+	default:
+		n.reportError(fmt.Errorf("fila de avisos cheia, aviso %s descartado", event))
+	}
+}
+
+func (n *Notifier) subscribed(event Event) bool {
+	for _, r := range n.routes {
+		if r.events[event] {
+			return true
+		}
+	}
+	return false
+}
+
+// format aplica o modelo do evento; os campos e "event"/"time" ficam disponíveis no modelo
+func (n *Notifier) format(event Event, now time.Time, fields map[string]any) Message {
+	data := make(map[string]any, len(fields)+2)
+	for k, v := range fields {
+		data[k] = v
+	}
+	data["event"] = string(event)
+	data["time"] = now.Format("2006-01-02 15:04:05")
+
+	msg := Message{Event: event, Time: now, Fields: fields}
+	var b strings.Builder
+	if tmpl, ok := n.templates[event]; ok {
+		if err := tmpl.Execute(&b, data); err != nil {
+			n.reportError(fmt.Errorf("erro no modelo do evento %s: %v", event, err))
+		}
+	}
+	msg.Text = b.String()
+	if msg.Text == "" {
+		msg.Text = fmt.Sprintf("%s: %v", event, fields)
+	}
+	return msg
+}
+
+func (n *Notifier) loop() {
+	defer close(n.done)
+	for msg := range n.queue {
+		for _, r := range n.routes {
+			if !r.events[msg.Event] {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+			if err := r.sink.Send(ctx, msg); err != nil {
+				n.reportError(fmt.Errorf("erro ao enviar aviso %s por %s: %v", msg.Event, r.sink.Name(), err))
+			}
+			cancel()
+		}
+	}
+}
+
+func (n *Notifier) reportError(err error) {
+	if n.onError != nil {
+		n.onError(err)
+	}
+}
+
+// Test envia um aviso de teste para todos os canais e espera as entregas
+func (n *Notifier) Test(ctx context.Context) error {
+	if len(n.routes) == 0 {
+		return fmt.Errorf("nenhum canal de aviso configurado")
+	}
+	now := time.Now()
+	msg := Message{
+		Event: "test",
+		Time:  now,
+		Text:  fmt.Sprintf("🔔 Aviso de teste do bot (%s)", now.Format("2006-01-02 15:04:05")),
+	}
+
+	var errs []error
+	for _, r := range n.routes {
+		if err := r.sink.Send(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", r.sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close para de aceitar avisos e espera a entrega dos que estão na fila até o fim do contexto
+func (n *Notifier) Close(ctx context.Context) error {
+	n.mu.Lock()
+	if !n.closed {
+		n.closed = true
+		close(n.queue)
+	}
+	n.mu.Unlock()
+
+	select {
+	case <-n.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,225 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/casarotto/binance-bot/internal/config"
+)
+
+// stubRequest é uma requisição recebida pelo stub
+type stubRequest struct {
+	path string
+	body []byte
+}
+
+// stub é um servidor HTTP local que registra as requisições e responde com status
+type stub struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	status   int
+	requests []stubRequest
+}
+
+func newStub(t *testing.T, status int) *stub {
+	s := &stub{status: status}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.requests = append(s.requests, stubRequest{path: r.URL.Path, body: body})
+		status := s.status
+		s.mu.Unlock()
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q, esperado application/json", r.Header.Get("Content-Type"))
+		}
+		w.WriteHeader(status)
+		io.WriteString(w, "resposta do stub")
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *stub) received() []stubRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]stubRequest(nil), s.requests...)
+}
+
+// closeNotifier espera a entrega dos avisos enfileirados
+func closeNotifier(t *testing.T, n *Notifier) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := n.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+func TestWebhookSinkPayload(t *testing.T) {
+	server := newStub(t, http.StatusOK)
+	sink := NewWebhookSink(server.URL + "/hook")
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	err := sink.Send(context.Background(), Message{
+		Event:      EventEntry,
+		Time:       now,
+		Text:       "compra",
+		Fields:     map[string]any{"price": 95000.5},
+		Suppressed: 2,
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	requests := server.received()
+	if len(requests) != 1 || requests[0].path != "/hook" {
+		t.Fatalf("requisições = %+v, esperada uma em /hook", requests)
+	}
+	var got Message
+	if err := json.Unmarshal(requests[0].body, &got); err != nil {
+		t.Fatalf("JSON inválido: %v", err)
+	}
+	if got.Event != EventEntry || got.Text != "compra" || got.Suppressed != 2 || !got.Time.Equal(now) {
+		t.Errorf("mensagem = %+v", got)
+	}
+	if got.Fields["price"] != 95000.5 {
+		t.Errorf("fields = %v", got.Fields)
+	}
+}
+
+func TestTelegramSinkPayload(t *testing.T) {
+	server := newStub(t, http.StatusOK)
+	sink := NewTelegramSink(server.URL+"/", "123:abc", "-100")
+
+	err := sink.Send(context.Background(), Message{Event: EventStop, Text: "stop", Suppressed: 3})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	requests := server.received()
+	if len(requests) != 1 || requests[0].path != "/bot123:abc/sendMessage" {
+		t.Fatalf("requisições = %+v, esperada uma em /bot123:abc/sendMessage", requests)
+	}
+	var got map[string]string
+	if err := json.Unmarshal(requests[0].body, &got); err != nil {
+		t.Fatalf("JSON inválido: %v", err)
+	}
+	if got["chat_id"] != "-100" {
+		t.Errorf("chat_id = %q", got["chat_id"])
+	}
+	if want := "stop\n(+3 avisos de stop suprimidos pelo limite)"; got["text"] != want {
+		t.Errorf("text = %q, esperado %q", got["text"], want)
+	}
+}
+
+func TestSinkNon2xx(t *testing.T) {
+	server := newStub(t, http.StatusBadGateway)
+
+	sinks := []Sink{
+		NewWebhookSink(server.URL),
+		NewTelegramSink(server.URL, "token", "1"),
+	}
+	for _, sink := range sinks {
+		err := sink.Send(context.Background(), Message{Event: EventError, Text: "erro"})
+		if err == nil || !strings.Contains(err.Error(), "status 502") || !strings.Contains(err.Error(), "resposta do stub") {
+			t.Errorf("%s: erro = %v, esperado o status 502 com o corpo da resposta", sink.Name(), err)
+		}
+	}
+}
+
+func TestNotifierRouting(t *testing.T) {
+	webhook := newStub(t, http.StatusOK)
+	telegram := newStub(t, http.StatusOK)
+	n, err := New(config.NotifyConfig{
+		WebhookURL:     webhook.URL,
+		WebhookEvents:  []string{"entry", "exit"},
+		TelegramAPIURL: telegram.URL,
+		TelegramToken:  "token",
+		TelegramChatID: "1",
+		TelegramEvents: []string{"exit", "error"},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	n.Notify(EventEntry, map[string]any{"price": 1.0, "quantity": 0.001})
+	n.Notify(EventExit, map[string]any{"price": 2.0, "quantity": 0.001, "profit_pct": 1.0})
+	n.Notify(EventError, map[string]any{"error": "falha"})
+	n.Notify(EventDisconnect, map[string]any{"error": "eof", "backoff": "1s"})
+	closeNotifier(t, n)
+
+	events := func(requests []stubRequest, field string) []string {
+		var got []string
+		for _, r := range requests {
+			var body map[string]any
+			if err := json.Unmarshal(r.body, &body); err != nil {
+				t.Fatalf("JSON inválido: %v", err)
+			}
+			got = append(got, body[field].(string))
+		}
+		return got
+	}
+	if got := events(webhook.received(), "event"); strings.Join(got, ",") != "entry,exit" {
+		t.Errorf("webhook recebeu %v, esperado [entry exit]", got)
+	}
+	texts := events(telegram.received(), "text")
+	if len(texts) != 2 || !strings.HasPrefix(texts[0], "🔴 Venda executada") || texts[1] != "❌ falha" {
+		t.Errorf("telegram recebeu %q, esperados os avisos de exit e error", texts)
+	}
+}
+
+func TestNotifierRateLimit(t *testing.T) {
+	server := newStub(t, http.StatusOK)
+	n, err := New(config.NotifyConfig{
+		WebhookURL:    server.URL,
+		WebhookEvents: []string{"error", "entry"},
+		MaxPerMinute:  2,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		n.Notify(EventError, map[string]any{"error": "falha"})
+	}
+	// O limite é por evento: outro evento ainda é enviado
+	n.Notify(EventEntry, map[string]any{"price": 1.0, "quantity": 0.001})
+
+	// A janela de um minuto passou: o próximo aviso leva a contagem dos suprimidos
+	n.mu.Lock()
+	for i := range n.sent[EventError] {
+		n.sent[EventError][i] = n.sent[EventError][i].Add(-time.Minute)
+	}
+	n.mu.Unlock()
+	n.Notify(EventError, map[string]any{"error": "falha"})
+	closeNotifier(t, n)
+
+	var got []Message
+	for _, r := range server.received() {
+		var msg Message
+		if err := json.Unmarshal(r.body, &msg); err != nil {
+			t.Fatalf("JSON inválido: %v", err)
+		}
+		got = append(got, msg)
+	}
+	if len(got) != 4 {
+		t.Fatalf("%d avisos enviados, esperados 4: %+v", len(got), got)
+	}
+	want := []struct {
+		event      Event
+		suppressed int
+	}{{EventError, 0}, {EventError, 0}, {EventEntry, 0}, {EventError, 3}}
+	for i, w := range want {
+		if got[i].Event != w.event || got[i].Suppressed != w.suppressed {
+			t.Errorf("aviso %d = %s (+%d suprimidos), esperado %s (+%d)", i, got[i].Event, got[i].Suppressed, w.event, w.suppressed)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+)
+
+var httpClient = &http.Client{Timeout: sendTimeout}
+
+// text retorna o texto do aviso com a contagem de avisos suprimidos pelo limite
+func (m Message) text() string {
+	if m.Suppressed == 0 {
+		return m.Text
+	}
+	return fmt.Sprintf("%s\n(+%d avisos de %s suprimidos pelo limite)", m.Text, m.Suppressed, m.Event)
+}
+
+// WebhookSink envia o aviso em JSON (Message) num POST para uma URL
+type WebhookSink struct {
+	url string
+}
+
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{url: url}
+}
+
+func (s *WebhookSink) Name() string { return "webhook" }
+
+func (s *WebhookSink) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, s.url, body)
+}
+
+// TelegramSink envia o aviso pelo método sendMessage da Bot API do Telegram. A URL
+// base é configurável para usar servidores compatíveis ou um stub local em testes.
+type TelegramSink struct {
+	baseURL string
+	token   string
+	chatID  string
+}
+
+func NewTelegramSink(baseURL, token, chatID string) *TelegramSink {
+	return &TelegramSink{baseURL: strings.TrimRight(baseURL, "/"), token: token, chatID: chatID}
+}
+
+func (s *TelegramSink) Name() string { return "telegram" }
+
+func (s *TelegramSink) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(map[string]string{
+		"chat_id": s.chatID,
+		"text":    msg.text(),
+	})
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, fmt.Sprintf("%s/bot%s/sendMessage", s.baseURL, s.token), body)
+}
+
+// postJSON faz o POST e trata qualquer status fora de 2xx como erro
+func postJSON(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// SMTPSink envia o aviso por e-mail. Com usuário definido autentica com PLAIN,
+// o que o net/smtp só permite com TLS (STARTTLS) ou em localhost.
+type SMTPSink struct {
+	addr     string
+	username string
+	password string
+	from     string
+	to       []string
+}
+
+func NewSMTPSink(addr, username, password, from string, to []string) *SMTPSink {
+	return &SMTPSink{addr: addr, username: username, password: password, from: from, to: to}
+}
+
+func (s *SMTPSink) Name() string { return "smtp" }
+
+// Send não respeita o cancelamento do contexto: o net/smtp não aceita contexto
+func (s *SMTPSink) Send(_ context.Context, msg Message) error {
+	var auth smtp.Auth
+	if s.username != "" {
+		host, _, err := net.SplitHostPort(s.addr)
+		if err != nil {
+			return fmt.Errorf("NOTIFY_SMTP_ADDR inválido: %v", err)
+		}
+		auth = smtp.PlainAuth("", s.username, s.password, host)
+	}
+
+	text := msg.text()
+	subject, _, _ := strings.Cut(text, "\n")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(s.to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", "[binance-bot] "+subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", msg.Time.Format("Mon, 02 Jan 2006 15:04:05 -0700"))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(text, "\n", "\r\n"))
+	b.WriteString("\r\n")
+
+	return smtp.SendMail(s.addr, auth, s.from, s.to, []byte(b.String()))
+}
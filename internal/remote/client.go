@@ -0,0 +1,293 @@
+package remote
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/casarotto/binance-bot/internal/config"
+	traderbot "github.com/casarotto/binance-bot/internal/trader-bot"
+)
+
+// tempo máximo de espera pela resposta de um comando
+const commandTimeout = 30 * time.Second
+
+// Client conecta a um bot em execução e implementa a interface Trader da TUI a
+// partir do último estado recebido. Os comandos são enviados ao bot.
+type Client struct {
+	conn  net.Conn
+	enc   *json.Encoder
+	encMu sync.Mutex
+
+	mu      sync.RWMutex
+	state   State
+	trades  []traderbot.Trade
+	equity  []traderbot.EquityPoint
+	err     error // erro que encerrou a conexão
+	nextID  int64
+	pending map[int64]chan string
+}
+
+// Dial conecta ao bot em addr (formato de ParseAddr) e espera o primeiro estado
+func Dial(addr, token string) (*Client, error) {
+	network, address := ParseAddr(addr)
+	conn, err := net.DialTimeout(network, address, 10*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao conectar em %s: %v", addr, err)
+	}
+
+	c := &Client{conn: conn, enc: json.NewEncoder(conn), pending: make(map[int64]chan string)}
+	if err := c.enc.Encode(message{Type: msgHello, Token: token}); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("erro ao enviar hello: %v", err)
+	}
+
+	// O primeiro estado confirma a autenticação
+	dec := json.NewDecoder(conn)
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	var first message
+	if err := dec.Decode(&first); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("erro ao ler resposta do bot: %v", err)
+	}
+	if first.Type == msgError {
+		conn.Close()
+		return nil, fmt.Errorf("bot recusou a conexão: %s", first.Error)
+	}
+	conn.SetReadDeadline(time.Time{})
+	c.apply(first)
+
+	go c.readLoop(dec)
+	return c, nil
+}
+
+// Close encerra a conexão; o bot continua operando
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Err retorna o erro que encerrou a conexão, se houver
+func (c *Client) Err() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.err
+}
+
+func (c *Client) readLoop(dec *json.Decoder) {
+	for {
+		var msg message
+		if err := dec.Decode(&msg); err != nil {
+			c.mu.Lock()
+			c.err = fmt.Errorf("conexão com o bot perdida: %v", err)
+			for id, ch := range c.pending {
+				ch <- c.err.Error()
+				delete(c.pending, id)
+			}
+			c.mu.Unlock()
+			return
+		}
+		c.apply(msg)
+	}
+}
+
+func (c *Client) apply(msg message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch msg.Type {
+	case msgState:
+		if msg.State == nil {
+			return
+		}
+		if msg.State.Trades != nil {
+			c.trades = msg.State.Trades
+		}
+		if msg.State.Equity != nil {
+			c.equity = msg.State.Equity
+		}
+		c.state = *msg.State
+	case msgResult:
+		if ch, ok := c.pending[msg.ID]; ok {
+			ch <- msg.Error
+			delete(c.pending, msg.ID)
+		}
+	}
+}
+
+// command envia um comando e espera o resultado
+func (c *Client) command(msg message) error {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+	c.nextID++
+	msg.Type = msgCommand
+	msg.ID = c.nextID
+	result := make(chan string, 1)
+	c.pending[msg.ID] = result
+	c.mu.Unlock()
+
+	c.encMu.Lock()
+	err := c.enc.Encode(msg)
+	c.encMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("erro ao enviar comando: %v", err)
+	}
+
+	select {
+	case errMsg := <-result:
+		if errMsg != "" {
+			return errors.New(errMsg)
+		}
+		return nil
+	case <-time.After(commandTimeout):
+		c.mu.Lock()
+		delete(c.pending, msg.ID)
+		c.mu.Unlock()
+		return fmt.Errorf("sem resposta do bot para %s", msg.Command)
+	}
+}
+
+// snapshot retorna uma cópia do último estado
+func (c *Client) snapshot() State {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state
+}
+
+func (c *Client) GetLastCandle() (traderbot.Candle, bool) {
+	s := c.snapshot()
+	if s.Candle == nil {
+		return traderbot.Candle{}, false
+	}
+	return *s.Candle, true
+}
+
+func (c *Client) GetCandleCount() int       { return c.snapshot().CandleCount }
+func (c *Client) CalculateRSI() float64     { return c.snapshot().RSI }
+func (c *Client) GetMAShortPeriod() int     { return c.snapshot().MAShortPeriod }
+func (c *Client) GetMALongPeriod() int      { return c.snapshot().MALongPeriod }
+func (c *Client) GetSignalInterval() string { return c.snapshot().Interval }
+
+func (c *Client) GetSignalParams() config.SignalParams { return c.snapshot().Signal }
+
+// CalculateMA retorna as médias calculadas pelo bot para os períodos curto e longo
+func (c *Client) CalculateMA(period int) float64 {
+	s := c.snapshot()
+	if period == s.MALongPeriod {
+		return s.MALong
+	}
+	return s.MAShort
+}
+
+func (c *Client) IsInPosition() bool     { return c.snapshot().InPosition }
+func (c *Client) GetEntryPrice() float64 { return c.snapshot().EntryPrice }
+
+func (c *Client) GetBalances() (float64, float64, error) {
+	s := c.snapshot()
+	if s.BalanceError != "" {
+		return s.BTCBalance, s.USDTBalance, errors.New(s.BalanceError)
+	}
+	return s.BTCBalance, s.USDTBalance, nil
+}
+
+func (c *Client) GetTradeHistory() []traderbot.Trade {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.trades
+}
+
+func (c *Client) GetEquityCurve() []traderbot.EquityPoint {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.equity
+}
+
+func (c *Client) GetPrices() []float64                { return c.snapshot().Prices }
+func (c *Client) GetRecentLogs() []traderbot.LogEntry { return c.snapshot().Logs }
+func (c *Client) GetRiskPerTrade() float64            { return c.snapshot().RiskPerTrade }
+func (c *Client) GetTotalFunds() float64              { return c.snapshot().TotalFunds }
+func (c *Client) GetNextTradeAmount() float64         { return c.snapshot().NextTradeAmount }
+
+func (c *Client) UpdateTotalFunds() error {
+	return c.command(message{Command: cmdUpdateFunds})
+}
+
+func (c *Client) IsDCAEnabled() bool                    { return c.snapshot().DCAEnabled }
+func (c *Client) GetDCALadder() []traderbot.SafetyOrder { return c.snapshot().DCALadder }
+func (c *Client) GetDCATakeProfitPrice() float64        { return c.snapshot().DCATakeProfit }
+func (c *Client) GetDCACommitted() float64              { return c.snapshot().DCACommitted }
+
+func (c *Client) IsGridEnabled() bool                  { return c.snapshot().GridEnabled }
+func (c *Client) IsGridPaused() bool                   { return c.snapshot().GridPaused }
+func (c *Client) GetGridLevels() []traderbot.GridLevel { return c.snapshot().GridLevels }
+func (c *Client) GetGridProfit() float64               { return c.snapshot().GridProfit }
+
+func (c *Client) GetGridRange() (float64, float64) {
+	s := c.snapshot()
+	return s.GridLower, s.GridUpper
+}
+
+func (c *Client) GetTimeframeStatus() []traderbot.TimeframeStatus {
+	return c.snapshot().Timeframes
+}
+
+func (c *Client) GetDepth() traderbot.DepthStatus {
+	return c.snapshot().Depth
+}
+
+func (c *Client) GetOrderFlow() traderbot.OrderFlowStatus {
+	return c.snapshot().OrderFlow
+}
+
+func (c *Client) GetClock() traderbot.ClockStatus {
+	return c.snapshot().Clock
+}
+
+// CheckRules retorna a última avaliação das regras feita pelo bot
+func (c *Client) CheckRules(price float64) (traderbot.RuleCheck, traderbot.RuleCheck, bool) {
+	s := c.snapshot()
+	return s.EntryRule.check(), s.ExitRule.check(), s.HasRules
+}
+
+func (c *Client) IsSignalsPaused() bool { return c.snapshot().SignalsPaused }
+
+// PauseSignals e ResumeSignals atualizam o estado local na hora, sem esperar o próximo envio
+func (c *Client) PauseSignals() {
+	if c.command(message{Command: cmdPause}) == nil {
+		c.mu.Lock()
+		c.state.SignalsPaused = true
+		c.mu.Unlock()
+	}
+}
+
+func (c *Client) ResumeSignals() {
+	if c.command(message{Command: cmdResume}) == nil {
+		c.mu.Lock()
+		c.state.SignalsPaused = false
+		c.mu.Unlock()
+	}
+}
+
+func (c *Client) GetBreaker() traderbot.BreakerStatus { return c.snapshot().Breaker }
+
+// ResetBreaker atualiza o estado local na hora, como PauseSignals
+func (c *Client) ResetBreaker() {
+	if c.command(message{Command: cmdResetBreaker}) == nil {
+		c.mu.Lock()
+		c.state.Breaker.Open = false
+		c.state.Breaker.Failures = 0
+		c.mu.Unlock()
+	}
+}
+
+func (c *Client) ForceClose() error {
+	return c.command(message{Command: cmdClose})
+}
+
+func (c *Client) SetInitialPosition(inPosition bool, entryPrice float64) {
+	c.command(message{Command: cmdSetPosition, Bool: inPosition, Value: entryPrice})
+}
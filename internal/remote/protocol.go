@@ -0,0 +1,191 @@
+// Package remote permite que a TUI rode como um processo separado, conectada a um
+// bot em execução por um socket Unix ou TCP. O protocolo é de linhas JSON: o cliente
+// se apresenta com o token, o servidor envia o estado a cada segundo e o cliente
+// envia comandos, respondidos com o resultado.
+package remote
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/casarotto/binance-bot/internal/config"
+	traderbot "github.com/casarotto/binance-bot/internal/trader-bot"
+)
+
+// Tipos de mensagem
+const (
+	msgHello   = "hello"   // cliente -> servidor: autenticação
+	msgState   = "state"   // servidor -> cliente: estado atual
+	msgCommand = "command" // cliente -> servidor: comando
+	msgResult  = "result"  // servidor -> cliente: resultado de um comando
+	msgError   = "error"   // servidor -> cliente: erro fatal (ex. token inválido)
+)
+
+// Comandos aceitos pelo servidor
+const (
+	cmdPause        = "pause"
+	cmdResume       = "resume"
+	cmdClose        = "close"
+	cmdSetPosition  = "set_position"
+	cmdUpdateFunds  = "update_funds"
+	cmdResetBreaker = "reset_breaker"
+)
+
+// message é o envelope de todas as linhas trocadas
+type message struct {
+	Type    string  `json:"type"`
+	Token   string  `json:"token,omitempty"`
+	ID      int64   `json:"id,omitempty"`
+	Command string  `json:"command,omitempty"`
+	Bool    bool    `json:"bool,omitempty"`
+	Value   float64 `json:"value,omitempty"`
+	Error   string  `json:"error,omitempty"`
+	State   *State  `json:"state,omitempty"`
+}
+
+// ruleCheck é o traderbot.RuleCheck com o erro serializável
+type ruleCheck struct {
+	Source string `json:"source"`
+	Passed bool   `json:"passed"`
+	Err    string `json:"err,omitempty"`
+}
+
+func newRuleCheck(c traderbot.RuleCheck) ruleCheck {
+	rc := ruleCheck{Source: c.Source, Passed: c.Passed}
+	if c.Err != nil {
+		rc.Err = c.Err.Error()
+	}
+	return rc
+}
+
+func (c ruleCheck) check() traderbot.RuleCheck {
+	rc := traderbot.RuleCheck{Source: c.Source, Passed: c.Passed}
+	if c.Err != "" {
+		rc.Err = errors.New(c.Err)
+	}
+	return rc
+}
+
+// State é tudo o que a TUI exibe, coletado no processo do bot
+type State struct {
+	Time          time.Time           `json:"time"`
+	Candle        *traderbot.Candle   `json:"candle,omitempty"`
+	CandleCount   int                 `json:"candle_count"`
+	RSI           float64             `json:"rsi"`
+	MAShort       float64             `json:"ma_short"`
+	MALong        float64             `json:"ma_long"`
+	MAShortPeriod int                 `json:"ma_short_period"`
+	MALongPeriod  int                 `json:"ma_long_period"`
+	Interval      string              `json:"interval"`
+	Signal        config.SignalParams `json:"signal"`
+
+	InPosition      bool                    `json:"in_position"`
+	EntryPrice      float64                 `json:"entry_price"`
+	BTCBalance      float64                 `json:"btc_balance"`
+	USDTBalance     float64                 `json:"usdt_balance"`
+	BalanceError    string                  `json:"balance_error,omitempty"`
+	TotalFunds      float64                 `json:"total_funds"`
+	RiskPerTrade    float64                 `json:"risk_per_trade"`
+	NextTradeAmount float64                 `json:"next_trade_amount"`
+	SignalsPaused   bool                    `json:"signals_paused"`
+	Breaker         traderbot.BreakerStatus `json:"breaker"`
+
+	// Histórico e patrimônio só são enviados quando mudam; nil mantém o anterior no cliente
+	Trades []traderbot.Trade       `json:"trades,omitempty"`
+	Equity []traderbot.EquityPoint `json:"equity,omitempty"`
+	Prices []float64               `json:"prices,omitempty"`
+	Logs   []traderbot.LogEntry    `json:"logs"`
+
+	DCAEnabled    bool                    `json:"dca_enabled"`
+	DCALadder     []traderbot.SafetyOrder `json:"dca_ladder,omitempty"`
+	DCATakeProfit float64                 `json:"dca_take_profit"`
+	DCACommitted  float64                 `json:"dca_committed"`
+
+	GridEnabled bool                  `json:"grid_enabled"`
+	GridPaused  bool                  `json:"grid_paused"`
+	GridLower   float64               `json:"grid_lower"`
+	GridUpper   float64               `json:"grid_upper"`
+	GridLevels  []traderbot.GridLevel `json:"grid_levels,omitempty"`
+	GridProfit  float64               `json:"grid_profit"`
+
+	Timeframes []traderbot.TimeframeStatus `json:"timeframes,omitempty"`
+	Depth      traderbot.DepthStatus       `json:"depth"`
+	OrderFlow  traderbot.OrderFlowStatus   `json:"order_flow"`
+	Clock      traderbot.ClockStatus       `json:"clock"`
+	HasRules   bool                        `json:"has_rules"`
+	EntryRule  ruleCheck                   `json:"entry_rule"`
+	ExitRule   ruleCheck                   `json:"exit_rule"`
+}
+
+// collectState lê o estado do trader a partir do Snapshot, completado com os
+// painéis específicos de cada modo. Os saldos vêm do cache do trader para não
+// gerar uma requisição à corretora por cliente conectado.
+func collectState(t *traderbot.BTCTrader) *State {
+	status := t.Snapshot()
+	s := &State{
+		Time:            status.Time,
+		Candle:          status.Candle,
+		CandleCount:     t.GetCandleCount(),
+		RSI:             status.RSI,
+		MAShort:         status.MAShort,
+		MALong:          status.MALong,
+		MAShortPeriod:   t.GetMAShortPeriod(),
+		MALongPeriod:    t.GetMALongPeriod(),
+		Signal:          t.GetSignalParams(),
+		Interval:        status.Interval,
+		InPosition:      status.InPosition,
+		EntryPrice:      status.EntryPrice,
+		BTCBalance:      status.BTCBalance,
+		USDTBalance:     status.USDTBalance,
+		BalanceError:    status.BalanceError,
+		TotalFunds:      t.GetTotalFunds(),
+		RiskPerTrade:    status.RiskPerTrade,
+		NextTradeAmount: t.GetNextTradeAmount(),
+		SignalsPaused:   status.SignalsPaused,
+		Breaker:         status.Breaker,
+		Prices:          lastPrices(t.GetPrices()),
+		Logs:            t.GetRecentLogs(),
+		DCAEnabled:      t.IsDCAEnabled(),
+		GridEnabled:     t.IsGridEnabled(),
+		Timeframes:      t.GetTimeframeStatus(),
+		Depth:           t.GetDepth(),
+		OrderFlow:       t.GetOrderFlow(),
+		Clock:           t.GetClock(),
+	}
+
+	if s.DCAEnabled {
+		s.DCALadder = t.GetDCALadder()
+		s.DCATakeProfit = t.GetDCATakeProfitPrice()
+		s.DCACommitted = t.GetDCACommitted()
+	}
+	if s.GridEnabled {
+		s.GridPaused = t.IsGridPaused()
+		s.GridLower, s.GridUpper = t.GetGridRange()
+		s.GridLevels = t.GetGridLevels()
+		s.GridProfit = t.GetGridProfit()
+	}
+	if entry, exit, ok := t.CheckRules(status.Price); ok {
+		s.HasRules = true
+		s.EntryRule, s.ExitRule = newRuleCheck(entry), newRuleCheck(exit)
+	}
+	return s
+}
+
+// quantidade de preços e pontos do patrimônio enviados ao cliente, suficiente para os gráficos
+const chartPoints = 300
+
+func lastPrices(prices []float64) []float64 {
+	if len(prices) > chartPoints {
+		return prices[len(prices)-chartPoints:]
+	}
+	return prices
+}
+
+// ParseAddr interpreta "unix:/caminho.sock", "tcp:host:porta" ou "host:porta" (TCP)
+func ParseAddr(addr string) (network, address string) {
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		return "unix", path
+	}
+	return "tcp", strings.TrimPrefix(addr, "tcp:")
+}
@@ -0,0 +1,210 @@
+package remote
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	traderbot "github.com/casarotto/binance-bot/internal/trader-bot"
+)
+
+// intervalo entre os envios de estado para os clientes
+const stateInterval = time.Second
+
+// Server aceita clientes da TUI remota. Vários clientes podem observar o mesmo bot
+// e desconectar um cliente não afeta as operações.
+type Server struct {
+	trader *traderbot.BTCTrader
+	logger *traderbot.Logger
+	addr   string
+	token  string
+}
+
+// NewServer cria o servidor; addr segue o formato de ParseAddr
+func NewServer(addr, token string, trader *traderbot.BTCTrader, logger *traderbot.Logger) *Server {
+	return &Server{trader: trader, logger: logger, addr: addr, token: token}
+}
+
+// Serve escuta no endereço configurado até o contexto ser cancelado
+func (s *Server) Serve(ctx context.Context) error {
+	network, address := ParseAddr(s.addr)
+
+	var listener net.Listener
+	var err error
+	if network == "unix" {
+		listener, err = listenUnix(address)
+	} else {
+		listener, err = net.Listen(network, address)
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao escutar em %s: %v", s.addr, err)
+	}
+
+	if network == "unix" {
+		defer os.Remove(address)
+	}
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("erro ao aceitar conexão: %v", err)
+		}
+		go s.handle(ctx, conn)
+	}
+}
+
+// listenUnix escuta no socket unix com permissão 0600, para que só o dono do processo
+// possa conectar. O socket é criado num diretório temporário 0700 e só depois de
+// restringido é movido para o endereço final, sem um intervalo em que outros
+// usuários poderiam conectar.
+func listenUnix(address string) (net.Listener, error) {
+	// Remove um socket que ficou de uma execução anterior
+	if err := os.Remove(address); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp(filepath.Dir(address), ".remote-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, "bot.sock")
+	listener, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, err
+	}
+	// O caminho temporário deixa de existir: a remoção fica a cargo de Serve
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+
+	if err := os.Chmod(tmp, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("erro ao restringir a permissão do socket: %v", err)
+	}
+	if err := os.Rename(tmp, address); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// clientConn serializa as escritas de uma conexão, feitas pelo envio de estado e
+// pelas respostas aos comandos
+type clientConn struct {
+	conn    net.Conn
+	enc     *json.Encoder
+	writeMu sync.Mutex
+}
+
+func (c *clientConn) send(msg message) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	return c.enc.Encode(msg)
+}
+
+func (s *Server) handle(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	client := &clientConn{conn: conn, enc: json.NewEncoder(conn)}
+	scanner := bufio.NewScanner(conn)
+
+	// Autenticação: a primeira linha precisa ser o hello com o token
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	var hello message
+	if !scanner.Scan() || json.Unmarshal(scanner.Bytes(), &hello) != nil || hello.Type != msgHello {
+		client.send(message{Type: msgError, Error: "esperado hello"})
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(hello.Token), []byte(s.token)) != 1 {
+		client.send(message{Type: msgError, Error: "token inválido"})
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+	s.logger.Infof("🖥️ TUI remota conectada (%s)", conn.RemoteAddr())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for scanner.Scan() {
+			var msg message
+			if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil || msg.Type != msgCommand {
+				continue
+			}
+			result := message{Type: msgResult, ID: msg.ID}
+			if err := s.execute(msg); err != nil {
+				result.Error = err.Error()
+			}
+			if client.send(result) != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(stateInterval)
+	defer ticker.Stop()
+	sentTrades := -1
+	var sentEquity int64 = -1
+	for {
+		state := collectState(s.trader)
+		// O histórico e o patrimônio só vão quando mudam
+		if trades := s.trader.GetTradeHistory(); len(trades) != sentTrades {
+			state.Trades = trades
+			sentTrades = len(trades)
+		}
+		if equity := s.trader.GetEquityCurve(); len(equity) > 0 && equity[len(equity)-1].Timestamp != sentEquity {
+			if len(equity) > chartPoints {
+				equity = equity[len(equity)-chartPoints:]
+			}
+			state.Equity = equity
+			sentEquity = equity[len(equity)-1].Timestamp
+		}
+		if err := client.send(message{Type: msgState, State: state}); err != nil {
+			break
+		}
+
+		select {
+		case <-ticker.C:
+			continue
+		case <-done:
+		case <-ctx.Done():
+		}
+		break
+	}
+	s.logger.Infof("🖥️ TUI remota desconectada (%s)", conn.RemoteAddr())
+}
+
+// execute aplica um comando recebido de um cliente
+func (s *Server) execute(msg message) error {
+	switch msg.Command {
+	case cmdPause:
+		s.trader.PauseSignals()
+	case cmdResume:
+		s.trader.ResumeSignals()
+	case cmdClose:
+		return s.trader.ForceClose()
+	case cmdSetPosition:
+		s.trader.SetInitialPosition(msg.Bool, msg.Value)
+	case cmdUpdateFunds:
+		return s.trader.UpdateTotalFunds()
+	case cmdResetBreaker:
+		s.trader.ResetBreaker()
+	default:
+		return fmt.Errorf("comando desconhecido %q", msg.Command)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+
+	traderbot "github.com/casarotto/binance-bot/internal/trader-bot"
+)
+
+// ExportedTrade é um trade com a data formatada no fuso da exportação e a taxa estimada
+type ExportedTrade struct {
+	Time        string  `json:"time"`
+	Action      string  `json:"action"`
+	Price       float64 `json:"price"`
+	Quantity    float64 `json:"quantity"`
+	Value       float64 `json:"value_usdt"`
+	Fee         float64 `json:"fee_usdt"`
+	ProfitLoss  float64 `json:"profit_loss_pct"`
+	BTCBalance  float64 `json:"btc_balance"`
+	USDTBalance float64 `json:"usdt_balance"`
+	Source      string  `json:"source,omitempty"`
+	SignalID    string  `json:"signal_id,omitempty"`
+}
+
+// ExportTrades filtra os trades pelo período, ordena por data e formata as datas em loc
+func ExportTrades(trades []traderbot.Trade, since, until time.Time, loc *time.Location, feeRate float64) []ExportedTrade {
+	if loc == nil {
+		loc = time.Local
+	}
+	sorted := append([]traderbot.Trade(nil), trades...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp < sorted[j].Timestamp })
+
+	var out []ExportedTrade
+	for _, trade := range sorted {
+		ts := time.Unix(trade.Timestamp, 0).In(loc)
+		if (!since.IsZero() && ts.Before(since)) || (!until.IsZero() && ts.After(until)) {
+			continue
+		}
+		value := trade.Price * trade.Quantity
+		out = append(out, ExportedTrade{
+			Time:        ts.Format(time.RFC3339),
+			Action:      trade.Action,
+			Price:       trade.Price,
+			Quantity:    trade.Quantity,
+			Value:       value,
+			Fee:         value * feeRate,
+			ProfitLoss:  trade.ProfitLoss,
+			BTCBalance:  trade.BTCBalance,
+			USDTBalance: trade.USDTBalance,
+			Source:      trade.Source,
+			SignalID:    trade.SignalID,
+		})
+	}
+	return out
+}
+
+// WriteTradesCSV escreve os trades exportados em CSV com cabeçalho
+func WriteTradesCSV(w io.Writer, trades []ExportedTrade) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"time", "action", "price", "quantity", "value_usdt", "fee_usdt",
+		"profit_loss_pct", "btc_balance", "usdt_balance", "source", "signal_id"})
+	for _, t := range trades {
+		cw.Write([]string{
+			t.Time,
+			t.Action,
+			formatFloat(t.Price, 2),
+			formatFloat(t.Quantity, 8),
+			formatFloat(t.Value, 2),
+			formatFloat(t.Fee, 4),
+			formatFloat(t.ProfitLoss, 2),
+			formatFloat(t.BTCBalance, 8),
+			formatFloat(t.USDTBalance, 2),
+			t.Source,
+			t.SignalID,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON escreve v como JSON indentado
+func WriteJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,68 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// String formata o relatório em texto, usado pelo subcomando report e pela TUI
+func (r Report) String() string {
+	if r.Trades == 0 {
+		return "Nenhum trade no período."
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Período: %s a %s (%d execuções, %d vendas)\n\n",
+		r.From.Format("2006-01-02 15:04"), r.To.Format("2006-01-02 15:04"), r.Trades, r.Sells)
+
+	fmt.Fprintf(&b, "Lucro realizado:    %s USDT\n", signed(r.RealizedPnL))
+	fmt.Fprintf(&b, "Taxa de acerto:     %.1f%% (%d ganhos, %d perdas)\n", r.WinRate, r.Wins, r.Losses)
+	fmt.Fprintf(&b, "Ganho médio:        %.2f USDT\n", r.AvgWin)
+	fmt.Fprintf(&b, "Perda média:        %.2f USDT\n", r.AvgLoss)
+	fmt.Fprintf(&b, "Profit factor:      %s\n", formatRatio(r.ProfitFactor))
+	fmt.Fprintf(&b, "Expectativa:        %s USDT por venda\n", signed(r.Expectancy))
+	fmt.Fprintf(&b, "Taxas pagas:        %.2f USDT\n", r.Fees)
+	fmt.Fprintf(&b, "Tempo médio:        %s\n\n", formatDuration(r.AvgHolding))
+
+	fmt.Fprintf(&b, "Drawdown máximo:    %.2f%%\n", r.MaxDrawdown)
+	fmt.Fprintf(&b, "Sharpe:             %.2f\n", r.Sharpe)
+	fmt.Fprintf(&b, "Sortino:            %.2f\n\n", r.Sortino)
+
+	fmt.Fprintf(&b, "Retorno do bot:     %s%%\n", signed(r.StrategyReturn))
+	fmt.Fprintf(&b, "Buy-and-hold:       %s%%\n", signed(r.BuyHoldReturn))
+
+	if len(r.Periods) > 0 {
+		b.WriteString("\nLucro por período:\n")
+		for _, p := range r.Periods {
+			fmt.Fprintf(&b, "  %-10s %12s USDT  (%d vendas)\n", p.Label, signed(p.PnL), p.Sells)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func signed(v float64) string {
+	// Evita "-0.00" para valores que arredondam para zero
+	if math.Abs(v) < 0.005 {
+		v = 0
+	}
+	return fmt.Sprintf("%+.2f", v)
+}
+
+func formatRatio(v float64) string {
+	if math.IsInf(v, 1) {
+		return "∞ (sem perdas)"
+	}
+	return fmt.Sprintf("%.2f", v)
+}
+
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	if d >= 24*time.Hour {
+		return fmt.Sprintf("%.1f dias", d.Hours()/24)
+	}
+	return d.Round(time.Second).String()
+}
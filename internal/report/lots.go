@@ -0,0 +1,272 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	traderbot "github.com/casarotto/binance-bot/internal/trader-bot"
+)
+
+// LotOptions configura o casamento das vendas com as compras
+type LotOptions struct {
+	Method   string         // "fifo" ou "average" (custo médio)
+	FeeRate  float64        // taxa por execução, somada ao custo e descontada da venda
+	Since    time.Time      // vendas a partir de (zero = todas)
+	Until    time.Time      // vendas até (zero = todas)
+	Location *time.Location // fuso usado nas datas e na busca das cotações
+	Currency string         // moeda de apuração (vazio = apenas USDT)
+	Rates    *Rates         // cotações USDT -> Currency por data
+}
+
+// Disposal é uma venda, ou a parte dela casada com um lote de compra no FIFO
+type Disposal struct {
+	SoldAt     time.Time  `json:"sold_at"`
+	AcquiredAt *time.Time `json:"acquired_at,omitempty"` // nil no custo médio ou sem a compra no histórico
+	Quantity   float64    `json:"quantity"`
+	Proceeds   float64    `json:"proceeds_usdt"`   // valor da venda, descontada a taxa
+	CostBasis  float64    `json:"cost_basis_usdt"` // custo das compras, com as taxas
+	Gain       float64    `json:"gain_usdt"`
+
+	// Valores na moeda de apuração: a venda na cotação da data da venda e o custo
+	// na cotação da data de cada compra
+	Currency      string  `json:"currency,omitempty"`
+	SaleRate      float64 `json:"sale_rate,omitempty"`
+	ProceedsConv  float64 `json:"proceeds,omitempty"`
+	CostBasisConv float64 `json:"cost_basis,omitempty"`
+	GainConv      float64 `json:"gain,omitempty"`
+}
+
+// lot é um lote de compra ainda não vendido
+type lot struct {
+	acquiredAt *time.Time
+	qty        float64
+	cost       float64 // custo restante em USDT
+	costConv   float64 // custo restante na moeda de apuração
+}
+
+// MatchLots casa cada venda com as compras anteriores pelo método escolhido.
+// Todo o histórico entra no custo; só as vendas no período são retornadas.
+func MatchLots(trades []traderbot.Trade, opts LotOptions) ([]Disposal, error) {
+	if opts.Method != "fifo" && opts.Method != "average" {
+		return nil, fmt.Errorf("método inválido: %s (use fifo ou average)", opts.Method)
+	}
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	if opts.Currency != "" && opts.Rates == nil {
+		return nil, fmt.Errorf("cotações de %s não informadas", opts.Currency)
+	}
+
+	sorted := append([]traderbot.Trade(nil), trades...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp < sorted[j].Timestamp })
+
+	rate := func(ts time.Time) (float64, error) {
+		if opts.Currency == "" {
+			return 1, nil
+		}
+		return opts.Rates.At(ts.In(opts.Location))
+	}
+
+	var lots []*lot
+	var disposals []Disposal
+	for _, trade := range sorted {
+		if trade.Quantity <= 0 {
+			continue
+		}
+		ts := time.Unix(trade.Timestamp, 0).In(opts.Location)
+		notional := trade.Price * trade.Quantity
+		fee := notional * opts.FeeRate
+		r, err := rate(ts)
+		if err != nil {
+			return nil, err
+		}
+
+		if trade.Action == "buy" {
+			acquired := ts
+			l := &lot{acquiredAt: &acquired, qty: trade.Quantity, cost: notional + fee, costConv: (notional + fee) * r}
+			if opts.Method == "average" && len(lots) > 0 {
+				// No custo médio há um único lote com a soma das compras
+				lots[0].qty += l.qty
+				lots[0].cost += l.cost
+				lots[0].costConv += l.costConv
+				lots[0].acquiredAt = nil
+			} else {
+				lots = append(lots, l)
+			}
+			continue
+		}
+
+		// Venda maior que o estoque (posição anterior ao histórico): o custo da
+		// diferença é deduzido do lucro percentual registrado na venda
+		held := 0.0
+		for _, l := range lots {
+			held += l.qty
+		}
+		if missing := trade.Quantity - held; missing > 1e-9 {
+			entry := trade.Price / (1 + trade.ProfitLoss/100)
+			cost := entry * missing * (1 + opts.FeeRate)
+			unknown := &lot{qty: missing, cost: cost, costConv: cost * r}
+			if opts.Method == "average" && len(lots) > 0 {
+				lots[0].qty += unknown.qty
+				lots[0].cost += unknown.cost
+				lots[0].costConv += unknown.costConv
+				lots[0].acquiredAt = nil
+			} else {
+				lots = append([]*lot{unknown}, lots...)
+			}
+		}
+
+		proceeds := notional - fee
+		inWindow := (opts.Since.IsZero() || !ts.Before(opts.Since)) && (opts.Until.IsZero() || !ts.After(opts.Until))
+		remaining := trade.Quantity
+		for remaining > 1e-9 && len(lots) > 0 {
+			l := lots[0]
+			qty := remaining
+			if l.qty < qty {
+				qty = l.qty
+			}
+			share := qty / l.qty
+			cost, costConv := l.cost*share, l.costConv*share
+			l.qty -= qty
+			l.cost -= cost
+			l.costConv -= costConv
+			remaining -= qty
+			if l.qty < 1e-9 {
+				lots = lots[1:]
+			}
+
+			if !inWindow {
+				continue
+			}
+			d := Disposal{
+				SoldAt:     ts,
+				AcquiredAt: l.acquiredAt,
+				Quantity:   qty,
+				Proceeds:   proceeds * qty / trade.Quantity,
+				CostBasis:  cost,
+			}
+			d.Gain = d.Proceeds - d.CostBasis
+			if opts.Currency != "" {
+				d.Currency = opts.Currency
+				d.SaleRate = r
+				d.ProceedsConv = d.Proceeds * r
+				d.CostBasisConv = costConv
+				d.GainConv = d.ProceedsConv - d.CostBasisConv
+			}
+			disposals = append(disposals, d)
+		}
+	}
+	return disposals, nil
+}
+
+// Rates guarda cotações diárias USDT -> moeda de apuração
+type Rates struct {
+	dates  []string // AAAA-MM-DD em ordem crescente
+	values []float64
+}
+
+// LoadRates lê um CSV com linhas "AAAA-MM-DD,cotação"; um cabeçalho é ignorado
+func LoadRates(path string) (*Rates, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir cotações: %v", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler cotações: %v", err)
+	}
+
+	byDate := make(map[string]float64)
+	for i, record := range records {
+		date := strings.TrimSpace(record[0])
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			if i == 0 {
+				continue // cabeçalho
+			}
+			return nil, fmt.Errorf("cotações, linha %d: data inválida %q", i+1, date)
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("cotações, linha %d: cotação inválida %q", i+1, record[1])
+		}
+		byDate[date] = value
+	}
+	if len(byDate) == 0 {
+		return nil, fmt.Errorf("nenhuma cotação em %s", path)
+	}
+
+	r := &Rates{}
+	for date := range byDate {
+		r.dates = append(r.dates, date)
+	}
+	sort.Strings(r.dates)
+	for _, date := range r.dates {
+		r.values = append(r.values, byDate[date])
+	}
+	return r, nil
+}
+
+// At retorna a cotação do dia de t ou, sem ela, a do último dia anterior
+func (r *Rates) At(t time.Time) (float64, error) {
+	date := t.Format("2006-01-02")
+	i := sort.SearchStrings(r.dates, date)
+	if i < len(r.dates) && r.dates[i] == date {
+		return r.values[i], nil
+	}
+	if i == 0 {
+		return 0, fmt.Errorf("sem cotação em ou antes de %s", date)
+	}
+	return r.values[i-1], nil
+}
+
+// WriteLotsCSV escreve as baixas em CSV, com as colunas da moeda de apuração se houver
+func WriteLotsCSV(w io.Writer, disposals []Disposal, currency string) error {
+	cw := csv.NewWriter(w)
+	header := []string{"sold_at", "acquired_at", "quantity", "proceeds_usdt", "cost_basis_usdt", "gain_usdt"}
+	if currency != "" {
+		c := strings.ToLower(currency)
+		header = append(header, "sale_rate", "proceeds_"+c, "cost_basis_"+c, "gain_"+c)
+	}
+	cw.Write(header)
+
+	for _, d := range disposals {
+		acquired := ""
+		if d.AcquiredAt != nil {
+			acquired = d.AcquiredAt.Format(time.RFC3339)
+		}
+		record := []string{
+			d.SoldAt.Format(time.RFC3339),
+			acquired,
+			formatFloat(d.Quantity, 8),
+			formatFloat(d.Proceeds, 2),
+			formatFloat(d.CostBasis, 2),
+			formatFloat(d.Gain, 2),
+		}
+		if currency != "" {
+			record = append(record,
+				formatFloat(d.SaleRate, 4),
+				formatFloat(d.ProceedsConv, 2),
+				formatFloat(d.CostBasisConv, 2),
+				formatFloat(d.GainConv, 2),
+			)
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatFloat(v float64, decimals int) string {
+	return strconv.FormatFloat(v, 'f', decimals, 64)
+}
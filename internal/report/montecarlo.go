@@ -0,0 +1,198 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	traderbot "github.com/casarotto/binance-bot/internal/trader-bot"
+)
+
+// Modos de reamostragem da simulação de Monte Carlo
+const (
+	ResampleBootstrap = "bootstrap" // sorteia as operações com reposição
+	ResampleShuffle   = "shuffle"   // embaralha a ordem das mesmas operações
+)
+
+// Percentis exibidos nas distribuições
+var monteCarloPercentiles = []float64{5, 25, 50, 75, 95}
+
+// MonteCarloOptions configura a simulação
+type MonteCarloOptions struct {
+	Iterations  int
+	Mode        string  // ResampleBootstrap ou ResampleShuffle
+	Risk        float64 // fração do patrimônio aplicada em cada operação
+	Equity      float64 // patrimônio inicial em USDT
+	EntryJitter float64 // desvio padrão do erro no preço de entrada (0.0005 = 5 bps)
+	Ruin        float64 // perda sobre o patrimônio inicial considerada ruína (0.5 = 50%)
+	Seed        int64
+}
+
+// DefaultMonteCarloOptions retorna 5000 simulações por bootstrap com 5 bps de ruído na entrada
+func DefaultMonteCarloOptions() MonteCarloOptions {
+	return MonteCarloOptions{Iterations: 5000, Mode: ResampleBootstrap, Risk: 0.1, Equity: 1000, EntryJitter: 0.0005, Ruin: 0.5, Seed: 1}
+}
+
+// Distribution resume os valores de uma métrica nas simulações
+type Distribution struct {
+	Mean        float64
+	Percentiles []float64 // na ordem de monteCarloPercentiles
+}
+
+// MonteCarloResult é o resultado da simulação para um nível de risco
+type MonteCarloResult struct {
+	Options     MonteCarloOptions
+	Trades      int
+	FinalEquity Distribution // USDT
+	Return      Distribution // percentual
+	MaxDrawdown Distribution // percentual
+	ProbLoss    float64      // percentual das simulações que terminam abaixo do patrimônio inicial
+	RiskOfRuin  float64      // percentual das simulações que atingem a ruína em algum momento
+}
+
+// TradeReturns extrai o retorno líquido de cada venda do período (ProfitLoss menos as
+// taxas da compra e da venda), na ordem em que aconteceram
+func TradeReturns(trades []traderbot.Trade, since, until time.Time, feeRate float64) []float64 {
+	sorted := append([]traderbot.Trade(nil), trades...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp < sorted[j].Timestamp })
+
+	var returns []float64
+	for _, t := range sorted {
+		ts := time.Unix(t.Timestamp, 0)
+		if t.Action != "sell" || (!since.IsZero() && ts.Before(since)) || (!until.IsZero() && ts.After(until)) {
+			continue
+		}
+		returns = append(returns, t.ProfitLoss/100-2*feeRate)
+	}
+	return returns
+}
+
+// MonteCarlo simula opts.Iterations sequências das operações, com o patrimônio composto
+// a cada operação (patrimônio × risco × retorno), e resume os resultados
+func MonteCarlo(returns []float64, opts MonteCarloOptions) (MonteCarloResult, error) {
+	result := MonteCarloResult{Options: opts, Trades: len(returns)}
+	switch {
+	case len(returns) < 2:
+		return result, fmt.Errorf("são necessárias ao menos 2 vendas para a simulação (encontradas: %d)", len(returns))
+	case opts.Iterations < 1:
+		return result, fmt.Errorf("o número de simulações deve ser positivo")
+	case opts.Mode != ResampleBootstrap && opts.Mode != ResampleShuffle:
+		return result, fmt.Errorf("modo inválido: %s (use %s ou %s)", opts.Mode, ResampleBootstrap, ResampleShuffle)
+	case opts.Risk <= 0 || opts.Risk > 1:
+		return result, fmt.Errorf("o risco por operação deve estar entre 0 e 1")
+	case opts.Equity <= 0:
+		return result, fmt.Errorf("o patrimônio inicial deve ser positivo")
+	case opts.Ruin <= 0 || opts.Ruin > 1:
+		return result, fmt.Errorf("a ruína deve estar entre 0 e 1")
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	finals := make([]float64, opts.Iterations)
+	drawdowns := make([]float64, opts.Iterations)
+	sequence := make([]float64, len(returns))
+	ruinLevel := opts.Equity * (1 - opts.Ruin)
+	var losses, ruined int
+
+	for i := 0; i < opts.Iterations; i++ {
+		if opts.Mode == ResampleShuffle {
+			copy(sequence, returns)
+			rng.Shuffle(len(sequence), func(a, b int) { sequence[a], sequence[b] = sequence[b], sequence[a] })
+		} else {
+			for k := range sequence {
+				sequence[k] = returns[rng.Intn(len(returns))]
+			}
+		}
+
+		equity, peak, worst := opts.Equity, opts.Equity, 0.0
+		hitRuin := false
+		for _, r := range sequence {
+			if opts.EntryJitter > 0 {
+				// Uma entrada a um preço diferente muda o retorno da operação inteira
+				r = (1+r)/(1+rng.NormFloat64()*opts.EntryJitter) - 1
+			}
+			equity += equity * opts.Risk * r
+			if equity < 0 {
+				equity = 0
+			}
+			peak = math.Max(peak, equity)
+			worst = math.Max(worst, (peak-equity)/peak*100)
+			if equity <= ruinLevel {
+				hitRuin = true
+			}
+		}
+
+		finals[i] = equity
+		drawdowns[i] = worst
+		if equity < opts.Equity {
+			losses++
+		}
+		if hitRuin {
+			ruined++
+		}
+	}
+
+	returnsPct := make([]float64, len(finals))
+	for i, f := range finals {
+		returnsPct[i] = (f - opts.Equity) / opts.Equity * 100
+	}
+	result.FinalEquity = distribution(finals)
+	result.Return = distribution(returnsPct)
+	result.MaxDrawdown = distribution(drawdowns)
+	result.ProbLoss = float64(losses) / float64(opts.Iterations) * 100
+	result.RiskOfRuin = float64(ruined) / float64(opts.Iterations) * 100
+	return result, nil
+}
+
+// distribution calcula a média e os percentis (interpolação linear) dos valores
+func distribution(values []float64) Distribution {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	d := Distribution{Mean: sum / float64(len(sorted))}
+	for _, p := range monteCarloPercentiles {
+		pos := p / 100 * float64(len(sorted)-1)
+		lo := int(math.Floor(pos))
+		hi := min(lo+1, len(sorted)-1)
+		d.Percentiles = append(d.Percentiles, sorted[lo]+(sorted[hi]-sorted[lo])*(pos-float64(lo)))
+	}
+	return d
+}
+
+// String formata o resultado como tabela de percentis
+func (r MonteCarloResult) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Risco por operação: %.0f%% (%d vendas, %d simulações, %s)\n\n",
+		r.Options.Risk*100, r.Trades, r.Options.Iterations, r.Options.Mode)
+
+	// Alinha por caracteres, não bytes, por causa dos acentos
+	pad := func(text string, width int) string {
+		return strings.Repeat(" ", max(width-utf8.RuneCountInString(text), 0)) + text
+	}
+	b.WriteString(pad("", 20))
+	for _, p := range monteCarloPercentiles {
+		b.WriteString(pad(fmt.Sprintf("p%.0f", p), 12))
+	}
+	b.WriteString(pad("média", 12) + "\n")
+	row := func(label string, d Distribution, format func(float64) string) {
+		b.WriteString(label + pad("", 20-utf8.RuneCountInString(label)))
+		for _, v := range d.Percentiles {
+			b.WriteString(pad(format(v), 12))
+		}
+		b.WriteString(pad(format(d.Mean), 12) + "\n")
+	}
+	row("Patrimônio (USDT)", r.FinalEquity, func(v float64) string { return fmt.Sprintf("%.2f", v) })
+	row("Retorno (%)", r.Return, signed)
+	row("Drawdown máx. (%)", r.MaxDrawdown, func(v float64) string { return fmt.Sprintf("%.2f", v) })
+
+	fmt.Fprintf(&b, "\nChance de prejuízo: %.1f%%\n", r.ProbLoss)
+	fmt.Fprintf(&b, "Risco de ruína:     %.1f%% (queda de %.0f%% do patrimônio inicial)", r.RiskOfRuin, r.Options.Ruin*100)
+	return b.String()
+}
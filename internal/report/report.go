@@ -0,0 +1,306 @@
+// Package report calcula as estatísticas de desempenho a partir do histórico de
+// trades: lucro realizado, taxa de acerto, drawdown, Sharpe/Sortino e a comparação
+// com o buy-and-hold no mesmo período.
+package report
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	traderbot "github.com/casarotto/binance-bot/internal/trader-bot"
+)
+
+// Options filtra e ajusta o cálculo do relatório
+type Options struct {
+	Since   time.Time // zero = desde o primeiro trade
+	Until   time.Time // zero = até o último trade
+	Period  string    // agrupamento do lucro: "day", "week" ou "month"
+	FeeRate float64   // taxa cobrada por execução (0.001 = 0.1%)
+}
+
+// DefaultOptions retorna as opções padrão: tudo, agrupado por dia, taxa de taker da Binance
+func DefaultOptions() Options {
+	return Options{Period: "day", FeeRate: 0.001}
+}
+
+// PeriodPnL é o lucro realizado em um período
+type PeriodPnL struct {
+	Label string
+	PnL   float64
+	Sells int
+}
+
+// Report reúne as estatísticas de desempenho. Valores em USDT, exceto os
+// marcados como percentuais.
+type Report struct {
+	From, To time.Time
+	Trades   int // execuções (compras e vendas) no período
+	Sells    int // vendas, cada uma conta como uma operação encerrada
+	Wins     int
+	Losses   int
+
+	RealizedPnL  float64 // lucro líquido das vendas, já descontadas as taxas
+	GrossProfit  float64
+	GrossLoss    float64 // soma das perdas (positiva)
+	WinRate      float64 // percentual
+	AvgWin       float64
+	AvgLoss      float64 // perda média (positiva)
+	ProfitFactor float64 // lucro bruto / perda bruta (+Inf sem perdas)
+	Expectancy   float64 // resultado esperado por venda
+	Fees         float64
+	AvgHolding   time.Duration
+
+	MaxDrawdown float64 // queda máxima do patrimônio, percentual
+	Sharpe      float64 // anualizado a partir do intervalo médio entre os pontos do patrimônio
+	Sortino     float64
+
+	StrategyReturn float64 // variação do patrimônio no período, percentual
+	BuyHoldReturn  float64 // variação do preço no período, percentual
+
+	Periods []PeriodPnL
+}
+
+// Compute calcula o relatório. O custo das posições considera todo o histórico,
+// mesmo as compras anteriores a opts.Since; só as vendas do período entram no resultado.
+func Compute(trades []traderbot.Trade, opts Options) (Report, error) {
+	var r Report
+	if opts.Period == "" {
+		opts.Period = "day"
+	}
+	if periodLabel(opts.Period, time.Time{}) == "" {
+		return r, fmt.Errorf("período inválido: %s (use day, week ou month)", opts.Period)
+	}
+
+	sorted := append([]traderbot.Trade(nil), trades...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp < sorted[j].Timestamp })
+
+	inWindow := func(ts time.Time) bool {
+		return (opts.Since.IsZero() || !ts.Before(opts.Since)) && (opts.Until.IsZero() || !ts.After(opts.Until))
+	}
+
+	var (
+		qty, cost  float64   // estoque de BTC e custo total (com taxas)
+		openedAt   time.Time // primeira compra do estoque atual
+		holding    time.Duration
+		holdings   int
+		periods    = make(map[string]*PeriodPnL)
+		order      []string
+		equity     []equityPoint
+		firstPrice float64
+		lastPrice  float64
+	)
+
+	for _, trade := range sorted {
+		if trade.Quantity <= 0 {
+			continue
+		}
+		ts := time.Unix(trade.Timestamp, 0)
+		notional := trade.Price * trade.Quantity
+		fee := notional * opts.FeeRate
+		counted := inWindow(ts)
+
+		if trade.Action == "buy" {
+			if qty <= 0 {
+				openedAt = ts
+			}
+			qty += trade.Quantity
+			cost += notional + fee
+		} else {
+			// Venda sem compra suficiente no histórico (posição anterior ao bot):
+			// o preço de entrada é deduzido do percentual registrado
+			if missing := trade.Quantity - qty; missing > 1e-9 {
+				entry := trade.Price / (1 + trade.ProfitLoss/100)
+				cost += entry * missing * (1 + opts.FeeRate)
+				qty += missing
+				openedAt = time.Time{}
+			}
+			avgCost := cost / qty
+			pnl := notional - fee - avgCost*trade.Quantity
+			qty -= trade.Quantity
+			cost -= avgCost * trade.Quantity
+			if qty < 1e-9 {
+				qty, cost = 0, 0
+			}
+
+			if counted {
+				r.Sells++
+				r.RealizedPnL += pnl
+				if pnl >= 0 {
+					r.Wins++
+					r.GrossProfit += pnl
+				} else {
+					r.Losses++
+					r.GrossLoss -= pnl
+				}
+				if !openedAt.IsZero() {
+					holding += ts.Sub(openedAt)
+					holdings++
+				}
+
+				label := periodLabel(opts.Period, ts)
+				if periods[label] == nil {
+					periods[label] = &PeriodPnL{Label: label}
+					order = append(order, label)
+				}
+				periods[label].PnL += pnl
+				periods[label].Sells++
+			}
+			if qty == 0 {
+				openedAt = time.Time{}
+			}
+		}
+
+		if !counted {
+			continue
+		}
+		r.Trades++
+		r.Fees += fee
+		if r.From.IsZero() {
+			r.From = ts
+			firstPrice = trade.Price
+		}
+		r.To = ts
+		lastPrice = trade.Price
+
+		// Saldos zerados indicam falha na consulta ao registrar o trade
+		if trade.BTCBalance > 0 || trade.USDTBalance > 0 {
+			equity = append(equity, equityPoint{
+				time:  ts,
+				value: trade.USDTBalance + trade.BTCBalance*trade.Price,
+			})
+		}
+	}
+
+	if r.Sells > 0 {
+		r.WinRate = float64(r.Wins) / float64(r.Sells) * 100
+		r.Expectancy = r.RealizedPnL / float64(r.Sells)
+	}
+	if r.Wins > 0 {
+		r.AvgWin = r.GrossProfit / float64(r.Wins)
+	}
+	if r.Losses > 0 {
+		r.AvgLoss = r.GrossLoss / float64(r.Losses)
+	}
+	switch {
+	case r.GrossLoss > 0:
+		r.ProfitFactor = r.GrossProfit / r.GrossLoss
+	case r.GrossProfit > 0:
+		r.ProfitFactor = math.Inf(1)
+	}
+	if holdings > 0 {
+		r.AvgHolding = holding / time.Duration(holdings)
+	}
+	if firstPrice > 0 {
+		r.BuyHoldReturn = (lastPrice/firstPrice - 1) * 100
+	}
+
+	r.MaxDrawdown = maxDrawdown(equity)
+	r.Sharpe, r.Sortino = sharpeSortino(equity)
+	if len(equity) > 0 && equity[0].value > 0 {
+		r.StrategyReturn = (equity[len(equity)-1].value/equity[0].value - 1) * 100
+	}
+
+	for _, label := range order {
+		r.Periods = append(r.Periods, *periods[label])
+	}
+	return r, nil
+}
+
+// periodLabel retorna o rótulo do período de ts, ou "" se o período for inválido
+func periodLabel(period string, ts time.Time) string {
+	switch period {
+	case "day":
+		return ts.Format("2006-01-02")
+	case "week":
+		year, week := ts.ISOWeek()
+		return fmt.Sprintf("%d-S%02d", year, week)
+	case "month":
+		return ts.Format("2006-01")
+	}
+	return ""
+}
+
+// equityPoint é o patrimônio (USDT + BTC × preço) em um instante
+type equityPoint struct {
+	time  time.Time
+	value float64
+}
+
+// CurveStats calcula o drawdown máximo (%) e os índices Sharpe/Sortino anualizados
+// de uma curva de patrimônio qualquer, como a marcada candle a candle no backtest
+func CurveStats(times []time.Time, values []float64) (maxDD, sharpe, sortino float64) {
+	equity := make([]equityPoint, 0, len(values))
+	for i := range values {
+		equity = append(equity, equityPoint{time: times[i], value: values[i]})
+	}
+	maxDD = maxDrawdown(equity)
+	sharpe, sortino = sharpeSortino(equity)
+	return maxDD, sharpe, sortino
+}
+
+// maxDrawdown retorna a maior queda percentual de um pico até um vale seguinte
+func maxDrawdown(equity []equityPoint) float64 {
+	var peak, worst float64
+	for _, p := range equity {
+		if p.value > peak {
+			peak = p.value
+		}
+		if peak > 0 {
+			if dd := (peak - p.value) / peak * 100; dd > worst {
+				worst = dd
+			}
+		}
+	}
+	return worst
+}
+
+// sharpeSortino calcula os índices sobre os retornos entre pontos consecutivos,
+// anualizados pelo intervalo médio entre eles (taxa livre de risco zero)
+func sharpeSortino(equity []equityPoint) (sharpe, sortino float64) {
+	if len(equity) < 3 {
+		return 0, 0
+	}
+
+	returns := make([]float64, 0, len(equity)-1)
+	for i := 1; i < len(equity); i++ {
+		if equity[i-1].value > 0 {
+			returns = append(returns, equity[i].value/equity[i-1].value-1)
+		}
+	}
+	if len(returns) < 2 {
+		return 0, 0
+	}
+
+	var mean float64
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	var variance, downside float64
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+		if r < 0 {
+			downside += r * r
+		}
+	}
+	std := math.Sqrt(variance / float64(len(returns)-1))
+	downDev := math.Sqrt(downside / float64(len(returns)))
+
+	span := equity[len(equity)-1].time.Sub(equity[0].time)
+	if span <= 0 {
+		return 0, 0
+	}
+	interval := span / time.Duration(len(equity)-1)
+	annualize := math.Sqrt(float64(365*24*time.Hour) / float64(interval))
+
+	if std > 0 {
+		sharpe = mean / std * annualize
+	}
+	if downDev > 0 {
+		sortino = mean / downDev * annualize
+	}
+	return sharpe, sortino
+}
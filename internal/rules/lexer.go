@@ -0,0 +1,107 @@
+package rules
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokLParen
+	tokRParen
+	tokComma
+	tokOp
+)
+
+// token é um item léxico com a coluna (1-based) onde começa
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+	col  int
+}
+
+// lex divide a expressão em tokens
+func lex(source string) ([]token, error) {
+	runes := []rune(source)
+	var tokens []token
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		col := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			text := string(runes[start:i])
+			num, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, &Error{Source: source, Column: col, Msg: "número inválido " + strconv.Quote(text)}
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: text, num: num, col: col})
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: strings.ToLower(string(runes[start:i])), col: col})
+
+		case r == '"' || r == '\'':
+			quote := r
+			start := i + 1
+			i++
+			for i < len(runes) && runes[i] != quote {
+				i++
+			}
+			if i >= len(runes) {
+				return nil, &Error{Source: source, Column: col, Msg: "texto sem aspas de fechamento"}
+			}
+			tokens = append(tokens, token{kind: tokString, text: string(runes[start:i]), col: col})
+			i++
+
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", col: col})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", col: col})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokComma, text: ",", col: col})
+			i++
+
+		case r == '<' || r == '>' || r == '=' || r == '!':
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+			}
+			if op == "=" || op == "!" {
+				return nil, &Error{Source: source, Column: col, Msg: "operador inválido " + strconv.Quote(op) + " (use == ou !=)"}
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, col: col})
+			i += len(op)
+
+		case r == '+' || r == '-' || r == '*' || r == '/':
+			tokens = append(tokens, token{kind: tokOp, text: string(r), col: col})
+			i++
+
+		default:
+			return nil, &Error{Source: source, Column: col, Msg: "caractere inesperado " + strconv.QuoteRune(r)}
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokEOF, col: len(runes) + 1})
+	return tokens, nil
+}
@@ -0,0 +1,268 @@
+package rules
+
+import "strconv"
+
+// Nós da árvore sintática. Todos guardam a coluna para as mensagens de erro.
+type node interface {
+	column() int
+}
+
+type numberLit struct {
+	col   int
+	value float64
+}
+
+type stringLit struct {
+	col   int
+	value string
+}
+
+type boolLit struct {
+	col   int
+	value bool
+}
+
+type ident struct {
+	col  int
+	name string
+}
+
+type call struct {
+	col  int
+	name string
+	args []node
+}
+
+type unary struct {
+	col int
+	op  string
+	x   node
+}
+
+type binary struct {
+	col         int
+	op          string
+	left, right node
+}
+
+func (n *numberLit) column() int { return n.col }
+func (n *stringLit) column() int { return n.col }
+func (n *boolLit) column() int   { return n.col }
+func (n *ident) column() int     { return n.col }
+func (n *call) column() int      { return n.col }
+func (n *unary) column() int     { return n.col }
+func (n *binary) column() int    { return n.col }
+
+// parser implementa uma descida recursiva com a precedência:
+// or < and < not < comparação < + - < * / < - unário
+type parser struct {
+	source string
+	tokens []token
+	pos    int
+}
+
+func parse(source string) (node, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{source: source, tokens: tokens}
+	if p.peek().kind == tokEOF {
+		return nil, p.errorf(p.peek(), "regra vazia")
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorf(tok, "token inesperado %s", strconv.Quote(tok.text))
+	}
+	return root, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) isKeyword(word string) bool {
+	tok := p.peek()
+	return tok.kind == tokIdent && tok.text == word
+}
+
+func (p *parser) errorf(tok token, format string, args ...interface{}) error {
+	return newError(p.source, tok.col, format, args...)
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		op := p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{col: op.col, op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		op := p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{col: op.col, op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.isKeyword("not") {
+		op := p.next()
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &unary{col: op.col, op: "not", x: x}, nil
+	}
+	return p.parseComparison()
+}
+
+var comparisonOps = map[string]bool{"<": true, "<=": true, ">": true, ">=": true, "==": true, "!=": true}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind == tokOp && comparisonOps[tok.text] {
+		op := p.next()
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		if next := p.peek(); next.kind == tokOp && comparisonOps[next.text] {
+			return nil, p.errorf(next, "comparações encadeadas não são permitidas; use and")
+		}
+		return &binary{col: op.col, op: op.text, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for tok := p.peek(); tok.kind == tokOp && (tok.text == "+" || tok.text == "-"); tok = p.peek() {
+		op := p.next()
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{col: op.col, op: op.text, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for tok := p.peek(); tok.kind == tokOp && (tok.text == "*" || tok.text == "/"); tok = p.peek() {
+		op := p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{col: op.col, op: op.text, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if tok := p.peek(); tok.kind == tokOp && tok.text == "-" {
+		op := p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unary{col: op.col, op: "-", x: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNumber:
+		return &numberLit{col: tok.col, value: tok.num}, nil
+
+	case tokString:
+		return &stringLit{col: tok.col, value: tok.text}, nil
+
+	case tokIdent:
+		switch tok.text {
+		case "true", "false":
+			return &boolLit{col: tok.col, value: tok.text == "true"}, nil
+		case "and", "or", "not":
+			return nil, p.errorf(tok, "operador %q fora de lugar", tok.text)
+		}
+		if p.peek().kind != tokLParen {
+			return &ident{col: tok.col, name: tok.text}, nil
+		}
+		p.next()
+		c := &call{col: tok.col, name: tok.text}
+		if p.peek().kind != tokRParen {
+			for {
+				arg, err := p.parseOr()
+				if err != nil {
+					return nil, err
+				}
+				c.args = append(c.args, arg)
+				if p.peek().kind != tokComma {
+					break
+				}
+				p.next()
+			}
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, p.errorf(closing, "esperado ')' para fechar a chamada de %s", tok.text)
+		}
+		return c, nil
+
+	case tokLParen:
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, p.errorf(closing, "esperado ')'")
+		}
+		return x, nil
+
+	case tokEOF:
+		return nil, p.errorf(tok, "fim inesperado da regra")
+	}
+	return nil, p.errorf(tok, "token inesperado %s", strconv.Quote(tok.text))
+}
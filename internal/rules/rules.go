@@ -0,0 +1,359 @@
+// Package rules implementa a pequena linguagem de expressões usada para escrever
+// regras de entrada e saída sem recompilar o bot, por exemplo:
+//
+//	rsi(14) < 30 and sma(9) > sma(21)
+//	rsi(14) > 70 or pnl_pct >= 0.3
+//
+// As regras são analisadas e verificadas quanto aos tipos na inicialização; erros
+// apontam a coluna da expressão onde o problema foi encontrado.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Type é o tipo de uma expressão
+type Type int
+
+const (
+	Number Type = iota
+	Bool
+	String
+)
+
+func (t Type) String() string {
+	switch t {
+	case Number:
+		return "número"
+	case Bool:
+		return "booleano"
+	default:
+		return "texto"
+	}
+}
+
+// Func descreve a assinatura de uma função disponível nas regras. Todas as funções
+// retornam número e seus argumentos precisam ser literais (ex. rsi(14, "1h")).
+type Func struct {
+	Params   []Type
+	Optional int // quantos parâmetros finais podem ser omitidos
+}
+
+// Schema lista as variáveis e funções que as regras podem usar
+type Schema struct {
+	Vars  map[string]Type
+	Funcs map[string]Func
+}
+
+// Value é um valor passado para o contexto (argumento de função ou variável)
+type Value struct {
+	Num  float64
+	Str  string
+	Bool bool
+}
+
+// Context fornece os valores das variáveis e funções durante a avaliação
+type Context interface {
+	Var(name string) (Value, error)
+	Call(name string, args []Value) (float64, error)
+}
+
+// ErrNotReady indica que um indicador ainda não tem dados suficientes
+var ErrNotReady = errors.New("dados insuficientes")
+
+// Error é um erro de sintaxe ou de tipo com a coluna onde ocorreu
+type Error struct {
+	Source string
+	Column int
+	Msg    string
+}
+
+func newError(source string, col int, format string, args ...interface{}) *Error {
+	return &Error{Source: source, Column: col, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Error formata a mensagem com a expressão e um marcador na coluna do problema
+func (e *Error) Error() string {
+	return fmt.Sprintf("coluna %d: %s\n    %s\n    %s^", e.Column, e.Msg, e.Source, strings.Repeat(" ", e.Column-1))
+}
+
+// CallInfo descreve uma chamada de função presente na regra, com seus argumentos literais
+type CallInfo struct {
+	Name   string
+	Args   []Value
+	Column int
+}
+
+// Program é uma regra compilada, pronta para ser avaliada
+type Program struct {
+	source string
+	eval   evalFunc
+	calls  []CallInfo
+}
+
+type evalFunc func(ctx Context) (Value, error)
+
+// Compile analisa e verifica os tipos da regra, que precisa resultar em booleano
+func Compile(source string, schema Schema) (*Program, error) {
+	root, err := parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &checker{source: source, schema: schema}
+	typ, eval, err := c.check(root)
+	if err != nil {
+		return nil, err
+	}
+	if typ != Bool {
+		return nil, newError(source, root.column(), "a regra deve resultar em booleano, mas resulta em %s", typ)
+	}
+
+	return &Program{source: source, eval: eval, calls: c.calls}, nil
+}
+
+// Source retorna o texto original da regra
+func (p *Program) Source() string {
+	return p.source
+}
+
+// Calls retorna as chamadas de função usadas pela regra
+func (p *Program) Calls() []CallInfo {
+	return p.calls
+}
+
+// Eval avalia a regra no contexto informado
+func (p *Program) Eval(ctx Context) (bool, error) {
+	v, err := p.eval(ctx)
+	if err != nil {
+		return false, err
+	}
+	return v.Bool, nil
+}
+
+// checker verifica os tipos e gera as funções de avaliação de cada nó
+type checker struct {
+	source string
+	schema Schema
+	calls  []CallInfo
+}
+
+func (c *checker) errorf(n node, format string, args ...interface{}) error {
+	return newError(c.source, n.column(), format, args...)
+}
+
+func (c *checker) check(n node) (Type, evalFunc, error) {
+	switch n := n.(type) {
+	case *numberLit:
+		v := Value{Num: n.value}
+		return Number, func(Context) (Value, error) { return v, nil }, nil
+
+	case *boolLit:
+		v := Value{Bool: n.value}
+		return Bool, func(Context) (Value, error) { return v, nil }, nil
+
+	case *stringLit:
+		return String, nil, c.errorf(n, "texto só pode ser usado como argumento de função")
+
+	case *ident:
+		typ, ok := c.schema.Vars[n.name]
+		if !ok {
+			if _, isFunc := c.schema.Funcs[n.name]; isFunc {
+				return 0, nil, c.errorf(n, "%s é uma função; use %s(...)", n.name, n.name)
+			}
+			return 0, nil, c.errorf(n, "variável desconhecida %q", n.name)
+		}
+		name := n.name
+		return typ, func(ctx Context) (Value, error) { return ctx.Var(name) }, nil
+
+	case *call:
+		return c.checkCall(n)
+
+	case *unary:
+		typ, x, err := c.check(n.x)
+		if err != nil {
+			return 0, nil, err
+		}
+		if n.op == "not" {
+			if typ != Bool {
+				return 0, nil, c.errorf(n.x, "not espera booleano, recebeu %s", typ)
+			}
+			return Bool, func(ctx Context) (Value, error) {
+				v, err := x(ctx)
+				return Value{Bool: !v.Bool}, err
+			}, nil
+		}
+		if typ != Number {
+			return 0, nil, c.errorf(n.x, "- espera número, recebeu %s", typ)
+		}
+		return Number, func(ctx Context) (Value, error) {
+			v, err := x(ctx)
+			return Value{Num: -v.Num}, err
+		}, nil
+
+	case *binary:
+		return c.checkBinary(n)
+	}
+	return 0, nil, fmt.Errorf("nó desconhecido %T", n)
+}
+
+func (c *checker) checkCall(n *call) (Type, evalFunc, error) {
+	fn, ok := c.schema.Funcs[n.name]
+	if !ok {
+		if _, isVar := c.schema.Vars[n.name]; isVar {
+			return 0, nil, c.errorf(n, "%s é uma variável e não pode ser chamada", n.name)
+		}
+		return 0, nil, c.errorf(n, "função desconhecida %q", n.name)
+	}
+
+	min, max := len(fn.Params)-fn.Optional, len(fn.Params)
+	if len(n.args) < min || len(n.args) > max {
+		expected := fmt.Sprintf("%d", max)
+		if min != max {
+			expected = fmt.Sprintf("de %d a %d", min, max)
+		}
+		return 0, nil, c.errorf(n, "%s espera %s argumento(s), recebeu %d", n.name, expected, len(n.args))
+	}
+
+	args := make([]Value, len(n.args))
+	for i, arg := range n.args {
+		switch lit := arg.(type) {
+		case *numberLit:
+			if fn.Params[i] != Number {
+				return 0, nil, c.errorf(arg, "argumento %d de %s deve ser %s", i+1, n.name, fn.Params[i])
+			}
+			args[i] = Value{Num: lit.value}
+		case *stringLit:
+			if fn.Params[i] != String {
+				return 0, nil, c.errorf(arg, "argumento %d de %s deve ser %s", i+1, n.name, fn.Params[i])
+			}
+			args[i] = Value{Str: lit.value}
+		default:
+			return 0, nil, c.errorf(arg, "argumentos de %s devem ser literais", n.name)
+		}
+	}
+
+	c.calls = append(c.calls, CallInfo{Name: n.name, Args: args, Column: n.col})
+	name := n.name
+	return Number, func(ctx Context) (Value, error) {
+		v, err := ctx.Call(name, args)
+		return Value{Num: v}, err
+	}, nil
+}
+
+func (c *checker) checkBinary(n *binary) (Type, evalFunc, error) {
+	lt, left, err := c.check(n.left)
+	if err != nil {
+		return 0, nil, err
+	}
+	rt, right, err := c.check(n.right)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	switch n.op {
+	case "and", "or":
+		if lt != Bool {
+			return 0, nil, c.errorf(n.left, "%s espera booleano à esquerda, recebeu %s", n.op, lt)
+		}
+		if rt != Bool {
+			return 0, nil, c.errorf(n.right, "%s espera booleano à direita, recebeu %s", n.op, rt)
+		}
+		isAnd := n.op == "and"
+		// Avaliação em curto-circuito: o lado direito só é calculado se necessário
+		return Bool, func(ctx Context) (Value, error) {
+			l, err := left(ctx)
+			if err != nil {
+				return Value{}, err
+			}
+			if l.Bool != isAnd {
+				return l, nil
+			}
+			return right(ctx)
+		}, nil
+
+	case "==", "!=":
+		if lt != rt {
+			return 0, nil, c.errorf(n, "%s compara %s com %s", n.op, lt, rt)
+		}
+		equal := n.op == "=="
+		return Bool, func(ctx Context) (Value, error) {
+			l, r, err := evalBoth(ctx, left, right)
+			if err != nil {
+				return Value{}, err
+			}
+			if lt == Bool {
+				return Value{Bool: (l.Bool == r.Bool) == equal}, nil
+			}
+			return Value{Bool: (l.Num == r.Num) == equal}, nil
+		}, nil
+
+	case "<", "<=", ">", ">=":
+		if lt != Number {
+			return 0, nil, c.errorf(n.left, "%s espera número, recebeu %s", n.op, lt)
+		}
+		if rt != Number {
+			return 0, nil, c.errorf(n.right, "%s espera número, recebeu %s", n.op, rt)
+		}
+		op := n.op
+		return Bool, func(ctx Context) (Value, error) {
+			l, r, err := evalBoth(ctx, left, right)
+			if err != nil {
+				return Value{}, err
+			}
+			var result bool
+			switch op {
+			case "<":
+				result = l.Num < r.Num
+			case "<=":
+				result = l.Num <= r.Num
+			case ">":
+				result = l.Num > r.Num
+			case ">=":
+				result = l.Num >= r.Num
+			}
+			return Value{Bool: result}, nil
+		}, nil
+
+	default: // + - * /
+		if lt != Number {
+			return 0, nil, c.errorf(n.left, "%s espera número, recebeu %s", n.op, lt)
+		}
+		if rt != Number {
+			return 0, nil, c.errorf(n.right, "%s espera número, recebeu %s", n.op, rt)
+		}
+		op := n.op
+		return Number, func(ctx Context) (Value, error) {
+			l, r, err := evalBoth(ctx, left, right)
+			if err != nil {
+				return Value{}, err
+			}
+			switch op {
+			case "+":
+				return Value{Num: l.Num + r.Num}, nil
+			case "-":
+				return Value{Num: l.Num - r.Num}, nil
+			case "*":
+				return Value{Num: l.Num * r.Num}, nil
+			}
+			if r.Num == 0 {
+				return Value{}, ErrNotReady
+			}
+			return Value{Num: l.Num / r.Num}, nil
+		}, nil
+	}
+}
+
+func evalBoth(ctx Context, left, right evalFunc) (Value, Value, error) {
+	l, err := left(ctx)
+	if err != nil {
+		return Value{}, Value{}, err
+	}
+	r, err := right(ctx)
+	if err != nil {
+		return Value{}, Value{}, err
+	}
+	return l, r, nil
+}
@@ -0,0 +1,175 @@
+package rules_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/casarotto/binance-bot/internal/rules"
+	traderbot "github.com/casarotto/binance-bot/internal/trader-bot"
+)
+
+// fakeContext responde variáveis e chamadas a partir de mapas fixos
+type fakeContext struct {
+	vars  map[string]rules.Value
+	calls map[string]float64 // chave: nome(argumentos), ex. "rsi(14)"
+}
+
+func (c fakeContext) Var(name string) (rules.Value, error) {
+	v, ok := c.vars[name]
+	if !ok {
+		return rules.Value{}, fmt.Errorf("variável %s não definida no teste", name)
+	}
+	return v, nil
+}
+
+func (c fakeContext) Call(name string, args []rules.Value) (float64, error) {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		if arg.Str != "" {
+			parts[i] = fmt.Sprintf("%q", arg.Str)
+		} else {
+			parts[i] = fmt.Sprintf("%g", arg.Num)
+		}
+	}
+	key := name + "(" + strings.Join(parts, ", ") + ")"
+	v, ok := c.calls[key]
+	if !ok {
+		return 0, rules.ErrNotReady
+	}
+	return v, nil
+}
+
+func TestCompileAndEval(t *testing.T) {
+	const entry = "rsi(14) < 30 and sma(9) > sma(21)"
+	const exit = "rsi(14) > 70 or pnl_pct >= 0.3"
+
+	tests := []struct {
+		name   string
+		source string
+		ctx    fakeContext
+		want   bool
+	}{
+		{
+			name:   "entrada verdadeira",
+			source: entry,
+			ctx:    fakeContext{calls: map[string]float64{"rsi(14)": 25, "sma(9)": 101, "sma(21)": 100}},
+			want:   true,
+		},
+		{
+			name:   "entrada com médias cruzadas para baixo",
+			source: entry,
+			ctx:    fakeContext{calls: map[string]float64{"rsi(14)": 25, "sma(9)": 99, "sma(21)": 100}},
+			want:   false,
+		},
+		{
+			// Curto-circuito: as médias não são calculadas quando o RSI já falha
+			name:   "entrada com RSI alto",
+			source: entry,
+			ctx:    fakeContext{calls: map[string]float64{"rsi(14)": 50}},
+			want:   false,
+		},
+		{
+			name:   "saída pelo RSI",
+			source: exit,
+			ctx:    fakeContext{calls: map[string]float64{"rsi(14)": 75}},
+			want:   true,
+		},
+		{
+			name:   "saída pelo lucro",
+			source: exit,
+			ctx: fakeContext{
+				calls: map[string]float64{"rsi(14)": 50},
+				vars:  map[string]rules.Value{"pnl_pct": {Num: 0.3}},
+			},
+			want: true,
+		},
+		{
+			name:   "sem saída",
+			source: exit,
+			ctx: fakeContext{
+				calls: map[string]float64{"rsi(14)": 50},
+				vars:  map[string]rules.Value{"pnl_pct": {Num: 0.1}},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := rules.Compile(tt.source, traderbot.RuleSchema())
+			if err != nil {
+				t.Fatalf("Compile(%q): %v", tt.source, err)
+			}
+			got, err := program.Eval(tt.ctx)
+			if err != nil {
+				t.Fatalf("Eval: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Eval = %v, esperado %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		column int
+		msg    string
+	}{
+		{"variável desconhecida", "rsi(14) < 30 and foo > 1", 18, `variável desconhecida "foo"`},
+		{"função desconhecida", "macd(12) > 0", 1, `função desconhecida "macd"`},
+		{"aridade sem argumentos", "sma() > 1", 1, "sma espera de 1 a 2 argumento(s), recebeu 0"},
+		{"aridade com argumentos demais", "price > rsi(14, \"1h\", 2)", 9, "rsi espera de 1 a 2 argumento(s), recebeu 3"},
+		{"argumento com tipo errado", "rsi(\"1h\") < 30", 5, "argumento 1 de rsi deve ser número"},
+		{"booleano comparado a número", "in_position > 1", 1, "> espera número, recebeu booleano"},
+		{"número em and", "rsi(14) < 30 and price", 18, "and espera booleano à direita, recebeu número"},
+		{"igualdade entre tipos", "in_position == 1", 13, "== compara booleano com número"},
+		{"regra numérica", "rsi(14) + 1", 9, "a regra deve resultar em booleano, mas resulta em número"},
+		{"operador inválido", "price = 1", 7, `operador inválido "=" (use == ou !=)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := rules.Compile(tt.source, traderbot.RuleSchema())
+			var ruleErr *rules.Error
+			if !errors.As(err, &ruleErr) {
+				t.Fatalf("Compile(%q) = %v, esperado *rules.Error", tt.source, err)
+			}
+			if ruleErr.Column != tt.column || ruleErr.Msg != tt.msg {
+				t.Errorf("erro = coluna %d %q, esperado coluna %d %q", ruleErr.Column, ruleErr.Msg, tt.column, tt.msg)
+			}
+		})
+	}
+}
+
+func TestRuleIntervals(t *testing.T) {
+	tests := []struct {
+		entry string
+		err   string // vazio = regra aceita
+	}{
+		{`rsi(14, "1h") < 30`, ""},
+		{`close("1w") > 0`, ""},
+		{`rsi(14, "1M") < 30`, `coluna 1: intervalo "1M" inválido em rsi`},
+		{`price > close("1M")`, `coluna 9: intervalo "1M" inválido em close`},
+		{`sma(9, "2d") > 0`, `coluna 1: intervalo "2d" inválido em sma`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			_, err := traderbot.CompileRuleStrategy(tt.entry, "pnl_pct >= 0.3")
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("CompileRuleStrategy: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("erro = %v, esperado %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -1,8 +1,32 @@
 package traderbot
 
-// GetPrices retorna o histórico de preços
+import (
+	"context"
+	"log/slog"
+)
+
+// GetPrices retorna o histórico de preços de fechamento
 func (t *BTCTrader) GetPrices() []float64 {
-	return t.prices
+	return t.closes()
+}
+
+// GetCandles retorna uma cópia dos candles do intervalo dos sinais
+func (t *BTCTrader) GetCandles() []Candle {
+	t.candlesMutex.RLock()
+	defer t.candlesMutex.RUnlock()
+	return t.candles.Candles()
+}
+
+// GetLastCandle retorna o candle mais recente do intervalo dos sinais
+func (t *BTCTrader) GetLastCandle() (Candle, bool) {
+	t.candlesMutex.RLock()
+	defer t.candlesMutex.RUnlock()
+	return t.candles.Last()
+}
+
+// GetCandleCount retorna quantos candles do intervalo dos sinais estão armazenados
+func (t *BTCTrader) GetCandleCount() int {
+	return t.candleCount()
 }
 
 // GetMAShortPeriod retorna o período da média móvel curta
@@ -45,9 +69,10 @@ func (t *BTCTrader) GetTradeHistory() []Trade {
 	return t.tradeHistory
 }
 
-// GetBalances retorna os saldos atuais de BTC e USDT
+// GetBalances retorna os saldos de BTC e USDT, consultados há no máximo 5 segundos.
+// É a atualização da TUI: perto do limite de requisições a consulta é descartada.
 func (t *BTCTrader) GetBalances() (btc float64, usdt float64, err error) {
-	return t.getBalances()
+	return t.cachedBalances(WithPriority(context.Background(), PriorityUI))
 }
 
 // SetLogger configura o logger do trader
@@ -58,4 +83,13 @@ func (t *BTCTrader) SetLogger(logger *Logger) {
 // GetLogger retorna o logger do trader
 func (t *BTCTrader) GetLogger() interface{} {
 	return t.logger
-} 
\ No newline at end of file
+}
+
+// GetRecentLogs retorna as entradas de log mantidas em memória, de todos os níveis
+// registrados; quem exibe filtra pelo nível (vazio sem logger)
+func (t *BTCTrader) GetRecentLogs() []LogEntry {
+	if t.logger == nil {
+		return nil
+	}
+	return t.logger.GetRecentLogs(slog.LevelDebug)
+}
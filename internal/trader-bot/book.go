@@ -0,0 +1,181 @@
+package traderbot
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+)
+
+// DepthUpdate é uma atualização do livro de ofertas do BTCUSDT
+type DepthUpdate struct {
+	Time     int64 // recebimento, em milissegundos
+	Bids     []binance.Bid
+	Asks     []binance.Ask
+	Snapshot bool // substitui o livro inteiro (depth parcial); senão altera só os níveis informados (diff)
+}
+
+// BookLevel é um nível de preço do livro
+type BookLevel struct {
+	Price    float64 `json:"price"`
+	Quantity float64 `json:"quantity"`
+}
+
+// OrderBook é uma cópia local do livro de ofertas do BTCUSDT montada a partir dos
+// eventos de depth. Níveis com quantidade zero são removidos.
+type OrderBook struct {
+	mu      sync.RWMutex
+	bids    map[float64]float64 // preço -> quantidade
+	asks    map[float64]float64
+	updated int64
+}
+
+// NewOrderBook cria um livro vazio
+func NewOrderBook() *OrderBook {
+	return &OrderBook{
+		bids: make(map[float64]float64),
+		asks: make(map[float64]float64),
+	}
+}
+
+// Apply aplica uma atualização ao livro
+func (b *OrderBook) Apply(update DepthUpdate) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if update.Snapshot {
+		clear(b.bids)
+		clear(b.asks)
+	}
+	bids := applyLevels(b.bids, update.Bids)
+	asks := applyLevels(b.asks, update.Asks)
+	if !update.Snapshot {
+		// Sem o snapshot inicial um diff pode deixar níveis antigos que cruzam o livro:
+		// os níveis recém-atualizados prevalecem sobre os do outro lado
+		for _, price := range bids {
+			for ask := range b.asks {
+				if ask <= price {
+					delete(b.asks, ask)
+				}
+			}
+		}
+		for _, price := range asks {
+			for bid := range b.bids {
+				if bid >= price {
+					delete(b.bids, bid)
+				}
+			}
+		}
+	}
+	b.updated = update.Time
+}
+
+// applyLevels atualiza um lado do livro e retorna os preços com quantidade positiva
+func applyLevels(side map[float64]float64, levels []binance.Bid) []float64 {
+	var live []float64
+	for _, level := range levels {
+		price, err := strconv.ParseFloat(level.Price, 64)
+		if err != nil {
+			continue
+		}
+		quantity, err := strconv.ParseFloat(level.Quantity, 64)
+		if err != nil {
+			continue
+		}
+		if quantity == 0 {
+			delete(side, price)
+		} else {
+			side[price] = quantity
+			live = append(live, price)
+		}
+	}
+	return live
+}
+
+// UpdatedAt retorna o instante da última atualização (zero se nunca recebeu)
+func (b *OrderBook) UpdatedAt() time.Time {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.updated == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(b.updated)
+}
+
+// Best retorna a melhor oferta de compra e de venda; ok é false com algum lado vazio
+func (b *OrderBook) Best() (bid, ask float64, ok bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for p := range b.bids {
+		bid = max(bid, p)
+	}
+	for p := range b.asks {
+		if ask == 0 || p < ask {
+			ask = p
+		}
+	}
+	return bid, ask, bid > 0 && ask > 0
+}
+
+// Top retorna os n melhores níveis de cada lado, do melhor para o pior
+func (b *OrderBook) Top(n int) (bids, asks []BookLevel) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, p := range sortedPrices(b.bids, true) {
+		if len(bids) == n {
+			break
+		}
+		bids = append(bids, BookLevel{Price: p, Quantity: b.bids[p]})
+	}
+	for _, p := range sortedPrices(b.asks, false) {
+		if len(asks) == n {
+			break
+		}
+		asks = append(asks, BookLevel{Price: p, Quantity: b.asks[p]})
+	}
+	return bids, asks
+}
+
+func sortedPrices(side map[float64]float64, descending bool) []float64 {
+	prices := make([]float64, 0, len(side))
+	for p := range side {
+		prices = append(prices, p)
+	}
+	if descending {
+		sort.Sort(sort.Reverse(sort.Float64Slice(prices)))
+	} else {
+		sort.Float64s(prices)
+	}
+	return prices
+}
+
+// FillPrice estima o preço médio de uma ordem a mercado de quantity BTC percorrendo
+// os níveis do lado oposto a partir de ref (último preço negociado). Níveis do lado
+// errado de ref são ignorados, pois podem ter ficado desatualizados. ok é false
+// quando o livro não tem quantidade suficiente.
+func (b *OrderBook) FillPrice(side binance.SideType, quantity, ref float64) (price float64, ok bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	levels := b.asks
+	prices := sortedPrices(levels, false)
+	if side == binance.SideTypeSell {
+		levels = b.bids
+		prices = sortedPrices(levels, true)
+	}
+
+	remaining, cost := quantity, 0.0
+	for _, p := range prices {
+		if (side == binance.SideTypeBuy && p < ref) || (side == binance.SideTypeSell && p > ref) {
+			continue
+		}
+		take := min(remaining, levels[p])
+		cost += take * p
+		remaining -= take
+		if remaining <= 1e-12 {
+			return cost / quantity, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,163 @@
+package traderbot
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/casarotto/binance-bot/internal/config"
+)
+
+// ErrCircuitOpen indica uma compra não enviada porque o circuit breaker está aberto
+var ErrCircuitOpen = errors.New("circuit breaker aberto")
+
+// BreakerStatus é o estado do circuit breaker das ordens
+type BreakerStatus struct {
+	Enabled   bool          `json:"enabled"` // conta as falhas seguidas (BREAKER_THRESHOLD > 0)
+	Open      bool          `json:"open"`
+	Failures  int           `json:"failures"` // falhas seguidas no envio de ordens
+	Threshold int           `json:"threshold"`
+	OpenedAt  time.Time     `json:"opened_at,omitempty"`
+	RetryAt   time.Time     `json:"retry_at,omitempty"` // nova tentativa automática (zero = só rearme manual)
+	Category  ErrorCategory `json:"category,omitempty"` // categoria da última falha
+	Reason    string        `json:"reason,omitempty"`   // última falha
+}
+
+// circuitBreaker suspende as compras após Threshold falhas seguidas no envio de ordens,
+// ou na hora para os erros que exigem intervenção (ActionHalt). As vendas continuam
+// liberadas para o stop loss e o fechamento da posição. Depois do Cooldown uma compra
+// é tentada de novo: se for aceita o circuito fecha, se falhar ele reabre.
+type circuitBreaker struct {
+	mu     sync.Mutex
+	cfg    config.BreakerConfig
+	status BreakerStatus
+}
+
+func newCircuitBreaker(cfg config.BreakerConfig) *circuitBreaker {
+	return &circuitBreaker{
+		cfg:    cfg,
+		status: BreakerStatus{Enabled: cfg.Threshold > 0, Threshold: cfg.Threshold},
+	}
+}
+
+// allow retorna ErrCircuitOpen para as compras com o circuito aberto antes da nova tentativa
+func (b *circuitBreaker) allow(side binance.SideType, now time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.status.Open || side != binance.SideTypeBuy {
+		return nil
+	}
+	if !b.status.RetryAt.IsZero() && !now.Before(b.status.RetryAt) {
+		return nil
+	}
+	return fmt.Errorf("%w desde %s: %s", ErrCircuitOpen, b.status.OpenedAt.Format("15:04:05"), b.status.Reason)
+}
+
+// success registra uma ordem aceita; retorna true se o circuito estava aberto
+func (b *circuitBreaker) success() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	wasOpen := b.status.Open
+	b.status = BreakerStatus{Enabled: b.status.Enabled, Threshold: b.status.Threshold}
+	return wasOpen
+}
+
+// failure registra uma falha no envio; retorna true quando ela abre o circuito, o
+// reabre após a nova tentativa ou passa a exigir o rearme manual
+func (b *circuitBreaker) failure(err *ExchangeError, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// Sem o limite de falhas seguidas só os erros que exigem intervenção abrem o circuito
+	halt := err.Category.Action() == ActionHalt
+	if !b.status.Enabled && !halt {
+		return false
+	}
+	b.status.Failures++
+	b.status.Category = err.Category
+	b.status.Reason = err.Error()
+
+	if !halt && b.status.Failures < b.cfg.Threshold {
+		return false
+	}
+	// Um circuito que já espera o rearme manual continua assim
+	wasOpen, retrying := b.status.Open, !b.status.RetryAt.IsZero()
+	manual := halt || b.cfg.Cooldown == 0 || (wasOpen && !retrying)
+	opened := !wasOpen || (retrying && (halt || !now.Before(b.status.RetryAt)))
+	if opened {
+		b.status.OpenedAt = now
+	}
+	b.status.Open = true
+	b.status.RetryAt = time.Time{}
+	if !manual {
+		b.status.RetryAt = now.Add(b.cfg.Cooldown)
+	}
+	return opened
+}
+
+// reset fecha o circuito manualmente
+func (b *circuitBreaker) reset() bool {
+	return b.success()
+}
+
+func (b *circuitBreaker) get() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.status
+}
+
+// SetBreakerConfig configura o circuit breaker das ordens. Sem efeito com a corretora
+// simulada, cujas recusas não são falhas transitórias. Deve ser chamado antes de Start.
+func (t *BTCTrader) SetBreakerConfig(cfg config.BreakerConfig) {
+	if t.client == nil {
+		return
+	}
+	t.breaker = newCircuitBreaker(cfg)
+	if cfg.Threshold > 0 {
+		t.logImportant("🔌 Circuit breaker ativo - abre após %d falha(s) seguida(s) no envio de ordens", cfg.Threshold)
+	}
+}
+
+// recordOrderResult contabiliza o resultado do envio de uma ordem no circuit breaker
+func (t *BTCTrader) recordOrderResult(err error) {
+	if err == nil {
+		if t.breaker.success() {
+			t.logImportant("🔌 Circuit breaker fechado - ordem aceita pela corretora")
+		}
+		return
+	}
+	if errors.Is(err, ErrCircuitOpen) {
+		return
+	}
+
+	classified := ClassifyError(err)
+	t.metrics.apiErrors.Inc(string(classified.Category))
+	if t.breaker.failure(classified, t.now()) {
+		status := t.breaker.get()
+		if status.RetryAt.IsZero() {
+			t.logError("🔌 Circuit breaker aberto após %d falha(s) - compras suspensas até o rearme manual: %v", status.Failures, classified)
+		} else {
+			t.logError("🔌 Circuit breaker aberto após %d falha(s) - compras suspensas até %s: %v",
+				status.Failures, status.RetryAt.Format("15:04:05"), classified)
+		}
+		t.notifyError("circuit breaker aberto, compras suspensas", classified)
+	}
+}
+
+// entriesBlocked indica se as compras estão suspensas pelo circuit breaker
+func (t *BTCTrader) entriesBlocked() bool {
+	return t.breaker.allow(binance.SideTypeBuy, t.now()) != nil
+}
+
+// GetBreaker retorna o estado do circuit breaker das ordens
+func (t *BTCTrader) GetBreaker() BreakerStatus {
+	return t.breaker.get()
+}
+
+// ResetBreaker fecha o circuit breaker e libera as compras
+func (t *BTCTrader) ResetBreaker() {
+	if t.breaker.reset() {
+		t.logImportant("🔌 Circuit breaker rearmado pelo operador")
+	}
+}
@@ -0,0 +1,153 @@
+package traderbot
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+)
+
+// Candle representa um kline completo (OHLCV)
+type Candle struct {
+	OpenTime  int64   `json:"open_time"`  // abertura em milissegundos
+	CloseTime int64   `json:"close_time"` // fechamento em milissegundos
+	Open      float64 `json:"open"`
+	High      float64 `json:"high"`
+	Low       float64 `json:"low"`
+	Close     float64 `json:"close"`
+	Volume    float64 `json:"volume"`
+	Final     bool    `json:"final"` // candle já fechado
+}
+
+// CandleFromWsKline converte um kline recebido pelo WebSocket
+func CandleFromWsKline(k binance.WsKline) (Candle, error) {
+	return parseCandle(k.StartTime, k.EndTime, k.Open, k.High, k.Low, k.Close, k.Volume, k.IsFinal)
+}
+
+// CandleFromKline converte um kline retornado pela API REST (o último pode estar em andamento)
+func CandleFromKline(k *binance.Kline) (Candle, error) {
+	final := k.CloseTime < time.Now().UnixMilli()
+	return parseCandle(k.OpenTime, k.CloseTime, k.Open, k.High, k.Low, k.Close, k.Volume, final)
+}
+
+func parseCandle(openTime, closeTime int64, open, high, low, close, volume string, final bool) (Candle, error) {
+	c := Candle{OpenTime: openTime, CloseTime: closeTime, Final: final}
+	fields := []struct {
+		name  string
+		value string
+		dest  *float64
+	}{
+		{"open", open, &c.Open},
+		{"high", high, &c.High},
+		{"low", low, &c.Low},
+		{"close", close, &c.Close},
+		{"volume", volume, &c.Volume},
+	}
+	for _, f := range fields {
+		v, err := strconv.ParseFloat(f.value, 64)
+		if err != nil {
+			return Candle{}, fmt.Errorf("erro ao converter %s do candle: %v", f.name, err)
+		}
+		*f.dest = v
+	}
+	return c, nil
+}
+
+// CandleSeries é um buffer circular de candles com capacidade fixa. Quando cheio,
+// o candle mais antigo é sobrescrito sem realocar o slice. Não é seguro para uso
+// concorrente; quem o possui deve proteger o acesso.
+type CandleSeries struct {
+	candles []Candle
+	start   int // índice do candle mais antigo
+	size    int
+}
+
+// NewCandleSeries cria uma série com a capacidade informada
+func NewCandleSeries(capacity int) *CandleSeries {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &CandleSeries{candles: make([]Candle, capacity)}
+}
+
+// Len retorna o número de candles armazenados
+func (s *CandleSeries) Len() int {
+	return s.size
+}
+
+// Cap retorna a capacidade da série
+func (s *CandleSeries) Cap() int {
+	return len(s.candles)
+}
+
+// At retorna o i-ésimo candle, sendo 0 o mais antigo
+func (s *CandleSeries) At(i int) Candle {
+	return s.candles[(s.start+i)%len(s.candles)]
+}
+
+// Last retorna o candle mais recente
+func (s *CandleSeries) Last() (Candle, bool) {
+	if s.size == 0 {
+		return Candle{}, false
+	}
+	return s.At(s.size - 1), true
+}
+
+// Add adiciona um candle. Um candle com a mesma abertura do último o substitui
+// (atualização do candle em andamento) e candles mais antigos que o último são ignorados.
+func (s *CandleSeries) Add(c Candle) bool {
+	if last, ok := s.Last(); ok {
+		if c.OpenTime == last.OpenTime {
+			s.candles[(s.start+s.size-1)%len(s.candles)] = c
+			return true
+		}
+		if c.OpenTime < last.OpenTime {
+			return false
+		}
+	}
+
+	if s.size < len(s.candles) {
+		s.candles[(s.start+s.size)%len(s.candles)] = c
+		s.size++
+		return true
+	}
+	s.candles[s.start] = c
+	s.start = (s.start + 1) % len(s.candles)
+	return true
+}
+
+// Candles retorna uma cópia dos candles em ordem cronológica
+func (s *CandleSeries) Candles() []Candle {
+	out := make([]Candle, s.size)
+	for i := range out {
+		out[i] = s.At(i)
+	}
+	return out
+}
+
+// Closes retorna os preços de fechamento em ordem cronológica
+func (s *CandleSeries) Closes() []float64 {
+	out := make([]float64, s.size)
+	for i := range out {
+		out[i] = s.At(i).Close
+	}
+	return out
+}
+
+// IntervalDuration converte um intervalo de kline da Binance ("1s", "15m", "4h", "1d", "1w")
+func IntervalDuration(interval string) (time.Duration, error) {
+	if len(interval) < 2 {
+		return 0, fmt.Errorf("intervalo inválido: %q", interval)
+	}
+	n, err := strconv.Atoi(interval[:len(interval)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("intervalo inválido: %q", interval)
+	}
+	units := map[byte]time.Duration{'s': time.Second, 'm': time.Minute, 'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	unit, ok := units[interval[len(interval)-1]]
+	if !ok {
+		return 0, fmt.Errorf("intervalo não suportado: %q", interval)
+	}
+	return time.Duration(n) * unit, nil
+}
@@ -0,0 +1,121 @@
+package traderbot
+
+import (
+	"context"
+	"time"
+
+	"github.com/casarotto/binance-bot/internal/config"
+)
+
+// clockSyncer é implementada pelas corretoras que assinam as requisições com o
+// horário local (a Binance). A corretora simulada não precisa de sincronização.
+type clockSyncer interface {
+	// SyncTime consulta o horário do servidor e passa a corrigir o timestamp das
+	// requisições assinadas. offset é o relógio local menos o do servidor.
+	SyncTime(ctx context.Context) (offset, rtt time.Duration, err error)
+	// SetRecvWindow define a validade das requisições assinadas
+	SetRecvWindow(window time.Duration)
+	// TimestampErrors recebe um aviso quando uma requisição é recusada pelo timestamp (-1021)
+	TimestampErrors() <-chan struct{}
+}
+
+// ClockStatus é o estado da sincronização com o horário da Binance
+type ClockStatus struct {
+	Enabled bool          `json:"enabled"`
+	Offset  time.Duration `json:"offset"` // relógio local menos o do servidor (positivo = adiantado)
+	RTT     time.Duration `json:"rtt"`    // tempo de ida e volta da última consulta
+	Synced  time.Time     `json:"synced"` // última sincronização bem-sucedida
+	Drift   bool          `json:"drift"`  // diferença acima de MAX_CLOCK_DRIFT
+	Error   string        `json:"error,omitempty"`
+}
+
+// SetClockConfig configura o recvWindow e sincroniza o relógio com a Binance antes
+// das primeiras ordens. Sem efeito com a corretora simulada. Deve ser chamado antes de Start.
+func (t *BTCTrader) SetClockConfig(cfg config.ClockConfig) {
+	t.clockConfig = cfg
+	syncer, ok := t.exchange.(clockSyncer)
+	if !ok {
+		return
+	}
+	syncer.SetRecvWindow(cfg.RecvWindow)
+	t.clockMutex.Lock()
+	t.clock.Enabled = true
+	t.clockMutex.Unlock()
+	t.syncClock(syncer)
+}
+
+// SyncClock ressincroniza o relógio no intervalo configurado e sempre que a Binance
+// recusa o timestamp de uma requisição, até ctx ser cancelado
+func (t *BTCTrader) SyncClock(ctx context.Context) {
+	syncer, ok := t.exchange.(clockSyncer)
+	if !ok {
+		return
+	}
+
+	// Sem intervalo só os erros de timestamp disparam uma nova sincronização
+	var tick <-chan time.Time
+	if t.clockConfig.SyncInterval > 0 {
+		ticker := time.NewTicker(t.clockConfig.SyncInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+			t.syncClock(syncer)
+		case <-syncer.TimestampErrors():
+			t.logWarn("⏰ Binance recusou o timestamp de uma requisição (-1021) - ressincronizando o relógio")
+			t.syncClock(syncer)
+		}
+	}
+}
+
+// syncClock consulta o horário do servidor e avisa quando o relógio local passa do
+// limite de diferença ou volta a ficar dentro dele
+func (t *BTCTrader) syncClock(syncer clockSyncer) {
+	ctx, cancel := context.WithTimeout(WithPriority(context.Background(), PriorityCritical), 10*time.Second)
+	defer cancel()
+	offset, rtt, err := syncer.SyncTime(ctx)
+
+	t.clockMutex.Lock()
+	defer t.clockMutex.Unlock()
+	if err != nil {
+		t.clock.Error = err.Error()
+		t.logWarn("⚠️ Não foi possível sincronizar o relógio com a Binance: %v", err)
+		return
+	}
+
+	drift := offset.Abs() > t.clockConfig.MaxDrift
+	switch {
+	case drift && !t.clock.Drift:
+		t.logWarn("⏰ Relógio local %s em relação à Binance (limite de %s) - o timestamp das ordens é corrigido",
+			formatClockOffset(offset), t.clockConfig.MaxDrift)
+	case !drift && t.clock.Drift:
+		t.logImportant("⏰ Relógio local voltou a ficar sincronizado com a Binance (%s)", formatClockOffset(offset))
+	default:
+		t.log("Relógio sincronizado com a Binance: %s, RTT de %s", formatClockOffset(offset), rtt)
+	}
+	t.clock.Offset, t.clock.RTT, t.clock.Drift = offset, rtt, drift
+	t.clock.Synced = time.Now()
+	t.clock.Error = ""
+
+	t.metrics.clockOffset.Set(offset.Seconds())
+	t.metrics.clockDrift.Set(boolToFloat(drift))
+}
+
+// formatClockOffset descreve a diferença do relógio local, ex. "1.2s adiantado"
+func formatClockOffset(offset time.Duration) string {
+	if offset < 0 {
+		return offset.Abs().Round(time.Millisecond).String() + " atrasado"
+	}
+	return offset.Round(time.Millisecond).String() + " adiantado"
+}
+
+// GetClock retorna o estado da sincronização do relógio com a Binance
+func (t *BTCTrader) GetClock() ClockStatus {
+	t.clockMutex.Lock()
+	defer t.clockMutex.Unlock()
+	return t.clock
+}
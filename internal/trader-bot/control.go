@@ -0,0 +1,170 @@
+package traderbot
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/casarotto/binance-bot/internal/notify"
+)
+
+// Status é uma fotografia do estado do trader para consumo externo (API, clientes remotos)
+type Status struct {
+	Time          time.Time     `json:"time"`
+	Price         float64       `json:"price"`
+	Candle        *Candle       `json:"candle,omitempty"`
+	Interval      string        `json:"interval"`
+	Strategy      string        `json:"strategy"`
+	RSI           float64       `json:"rsi"`
+	MAShort       float64       `json:"ma_short"`
+	MALong        float64       `json:"ma_long"`
+	InPosition    bool          `json:"in_position"`
+	EntryPrice    float64       `json:"entry_price,omitempty"`
+	Quantity      float64       `json:"quantity,omitempty"`
+	PnLPct        float64       `json:"pnl_pct,omitempty"`
+	StopLossPrice float64       `json:"stop_loss_price,omitempty"`
+	BTCBalance    float64       `json:"btc_balance"`
+	USDTBalance   float64       `json:"usdt_balance"`
+	BalanceError  string        `json:"balance_error,omitempty"`
+	SignalsPaused bool          `json:"signals_paused"`
+	Breaker       BreakerStatus `json:"breaker"`
+	RiskPerTrade  float64       `json:"risk_per_trade"`
+	StopLoss      float64       `json:"stop_loss"`
+	Trades        int           `json:"trades"`
+}
+
+// Snapshot monta o Status atual a partir do estado do trader
+func (t *BTCTrader) Snapshot() Status {
+	status := Status{
+		Time:          time.Now(),
+		Interval:      t.signalInterval,
+		Strategy:      t.GetStrategy().Name(),
+		RSI:           t.calculateRSI(),
+		MAShort:       t.calculateMA(t.maShort),
+		MALong:        t.calculateMA(t.maLong),
+		SignalsPaused: t.signalsPaused.Load(),
+		Breaker:       t.GetBreaker(),
+	}
+	if t.gridConfig.Enabled {
+		status.Strategy = "grid"
+	}
+	if candle, ok := t.GetLastCandle(); ok {
+		status.Candle = &candle
+		status.Price = candle.Close
+	}
+
+	t.tradeMutex.Lock()
+	status.InPosition = t.inPosition
+	status.RiskPerTrade = t.riskPerTrade
+	status.StopLoss = t.stopLoss
+	if t.inPosition {
+		status.EntryPrice = t.positions["BTC"]
+		status.Quantity = t.lastBuyQuantity
+		status.StopLossPrice = status.EntryPrice * (1 - t.stopLoss)
+		if status.EntryPrice > 0 && status.Price > 0 {
+			status.PnLPct = (status.Price - status.EntryPrice) / status.EntryPrice * 100
+		}
+	}
+	t.tradeMutex.Unlock()
+
+	btc, usdt, err := t.cachedBalances(WithPriority(context.Background(), PriorityUI))
+	if err != nil {
+		status.BalanceError = err.Error()
+	}
+	status.BTCBalance, status.USDTBalance = btc, usdt
+
+	t.historyMutex.Lock()
+	status.Trades = len(t.tradeHistory)
+	t.historyMutex.Unlock()
+
+	return status
+}
+
+// PauseSignals suspende a avaliação dos sinais de entrada e saída. Os candles
+// continuam sendo coletados e o stop loss continua valendo.
+func (t *BTCTrader) PauseSignals() {
+	if !t.signalsPaused.Swap(true) {
+		t.logImportant("⏸️ Avaliação de sinais pausada")
+	}
+}
+
+// ResumeSignals retoma a avaliação dos sinais
+func (t *BTCTrader) ResumeSignals() {
+	if t.signalsPaused.Swap(false) {
+		t.logImportant("▶️ Avaliação de sinais retomada")
+	}
+}
+
+// IsSignalsPaused retorna se a avaliação de sinais está pausada
+func (t *BTCTrader) IsSignalsPaused() bool {
+	return t.signalsPaused.Load()
+}
+
+// ForceClose vende a posição atual a mercado, independentemente dos sinais
+func (t *BTCTrader) ForceClose() error {
+	if t.gridConfig.Enabled {
+		return fmt.Errorf("fechamento forçado não é suportado na estratégia de grid")
+	}
+
+	t.tradeMutex.Lock()
+	defer t.tradeMutex.Unlock()
+
+	if !t.inPosition {
+		return fmt.Errorf("não há posição aberta")
+	}
+	candle, ok := t.GetLastCandle()
+	if !ok {
+		return fmt.Errorf("preço atual ainda não disponível")
+	}
+
+	// Posição reconciliada na inicialização não tem a quantidade da compra
+	if t.lastBuyQuantity == 0 {
+		btc, _, err := t.getBalances(WithPriority(context.Background(), PriorityCritical))
+		if err != nil {
+			return err
+		}
+		t.lastBuyQuantity = math.Floor(btc*100000) / 100000
+	}
+
+	t.logImportant("🚨 Fechamento forçado da posição solicitado")
+	trade, err := t.executeTrade("sell", candle.Close, tradeSignal{source: SourceManual})
+	if err != nil {
+		t.notifyError("erro no fechamento forçado", err)
+		return err
+	}
+	t.notifyTrade(notify.EventKillSwitch, trade, map[string]any{
+		"reason": fmt.Sprintf("Fechamento forçado - Preço: $%.2f, Quantidade: %.5f BTC, Lucro: %.2f%%",
+			trade.Price, trade.Quantity, trade.ProfitLoss),
+	})
+	return nil
+}
+
+// SetRiskPerTrade altera a fração do capital usada em cada entrada
+func (t *BTCTrader) SetRiskPerTrade(risk float64) error {
+	if risk <= 0 || risk > 1 {
+		return fmt.Errorf("risco por trade deve estar entre 0 e 1")
+	}
+	t.tradeMutex.Lock()
+	t.riskPerTrade = risk
+	t.tradeMutex.Unlock()
+	t.logImportant("⚙️ Risco por trade alterado para %.2f%%", risk*100)
+	return nil
+}
+
+// GetStopLoss retorna a queda máxima tolerada sobre o preço de entrada
+func (t *BTCTrader) GetStopLoss() float64 {
+	return t.stopLoss
+}
+
+// SetStopLoss altera a queda máxima tolerada sobre o preço de entrada
+func (t *BTCTrader) SetStopLoss(stopLoss float64) error {
+	if stopLoss <= 0 || stopLoss >= 1 {
+		return fmt.Errorf("stop loss deve estar entre 0 e 1")
+	}
+	t.tradeMutex.Lock()
+	t.stopLoss = stopLoss
+	t.tradeMutex.Unlock()
+	t.logImportant("⚙️ Stop loss alterado para %.2f%%", stopLoss*100)
+	return nil
+}
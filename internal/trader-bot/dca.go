@@ -0,0 +1,307 @@
+package traderbot
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/casarotto/binance-bot/internal/config"
+)
+
+// Estados possíveis de uma ordem de segurança
+const (
+	SafetyOrderPending = "pendente"
+	SafetyOrderFilled  = "executada"
+	SafetyOrderBlocked = "bloqueada"
+)
+
+// Uma ordem de segurança recusada espera safetyOrderRetryDelay antes de uma nova
+// tentativa e é bloqueada após maxSafetyOrderFailures falhas seguidas
+const (
+	safetyOrderRetryDelay  = time.Minute
+	maxSafetyOrderFailures = 3
+)
+
+// SafetyOrder representa um degrau da escada de DCA
+type SafetyOrder struct {
+	Level        int     // número da ordem de segurança (1..N)
+	Deviation    float64 // queda acumulada em relação à entrada base
+	TriggerPrice float64 // preço que dispara a ordem
+	Amount       float64 // valor planejado em USDT
+	Status       string
+	FillPrice    float64
+	Quantity     float64
+}
+
+// dcaCycle guarda o estado do ciclo de DCA em andamento
+type dcaCycle struct {
+	baseEntry    float64       // preço da ordem base
+	totalQty     float64       // quantidade total de BTC acumulada
+	totalCost    float64       // custo total em USDT (sem taxas)
+	safetyOrders []SafetyOrder // escada de ordens de segurança
+	failures     int           // falhas seguidas da próxima ordem de segurança
+	retryAt      time.Time     // nova tentativa após uma falha
+}
+
+// SetDCAConfig habilita/configura o modo de preço médio com ordens de segurança
+func (t *BTCTrader) SetDCAConfig(cfg config.DCAConfig) {
+	t.dcaMutex.Lock()
+	defer t.dcaMutex.Unlock()
+	t.dcaConfig = cfg
+	if cfg.Enabled {
+		t.logImportant("🪜 Modo DCA ativo - %d ordens de segurança, desvio %.2f%%, take-profit %.2f%%",
+			cfg.MaxSafetyOrders, cfg.PriceDeviation*100, cfg.TakeProfit*100)
+	}
+}
+
+// IsDCAEnabled retorna se o modo DCA está ativo
+func (t *BTCTrader) IsDCAEnabled() bool {
+	return t.dcaConfig.Enabled
+}
+
+// GetDCALadder retorna uma cópia da escada de ordens de segurança do ciclo atual
+func (t *BTCTrader) GetDCALadder() []SafetyOrder {
+	t.dcaMutex.Lock()
+	defer t.dcaMutex.Unlock()
+	if t.dca == nil {
+		return nil
+	}
+	ladder := make([]SafetyOrder, len(t.dca.safetyOrders))
+	copy(ladder, t.dca.safetyOrders)
+	return ladder
+}
+
+// GetDCATakeProfitPrice retorna o preço alvo calculado sobre o preço médio
+func (t *BTCTrader) GetDCATakeProfitPrice() float64 {
+	t.dcaMutex.Lock()
+	defer t.dcaMutex.Unlock()
+	if t.dca == nil || t.dca.totalQty == 0 {
+		return 0
+	}
+	return t.dca.totalCost / t.dca.totalQty * (1 + t.dcaConfig.TakeProfit)
+}
+
+// GetDCACommitted retorna o capital já comprometido no ciclo atual
+func (t *BTCTrader) GetDCACommitted() float64 {
+	t.dcaMutex.Lock()
+	defer t.dcaMutex.Unlock()
+	if t.dca == nil {
+		return 0
+	}
+	return t.dca.totalCost
+}
+
+// startDCACycle monta a escada de ordens de segurança a partir da ordem base
+func (t *BTCTrader) startDCACycle(entryPrice, quantity float64) {
+	t.dcaMutex.Lock()
+	defer t.dcaMutex.Unlock()
+
+	baseAmount := entryPrice * quantity
+	if baseAmount == 0 {
+		// Posição configurada manualmente sem quantidade conhecida
+		baseAmount = t.funds * t.riskPerTrade
+	}
+
+	cycle := &dcaCycle{
+		baseEntry: entryPrice,
+		totalQty:  quantity,
+		totalCost: entryPrice * quantity,
+	}
+
+	deviation := 0.0
+	step := t.dcaConfig.PriceDeviation
+	amount := baseAmount * t.dcaConfig.SafetyOrderRatio
+	for i := 1; i <= t.dcaConfig.MaxSafetyOrders; i++ {
+		deviation += step
+		if deviation >= 1 {
+			break
+		}
+		cycle.safetyOrders = append(cycle.safetyOrders, SafetyOrder{
+			Level:        i,
+			Deviation:    deviation,
+			TriggerPrice: entryPrice * (1 - deviation),
+			Amount:       amount,
+			Status:       SafetyOrderPending,
+		})
+		step *= t.dcaConfig.StepScale
+		amount *= t.dcaConfig.VolumeScale
+	}
+
+	t.dca = cycle
+	t.log("Escada DCA criada com %d ordens de segurança a partir de $%.2f", len(cycle.safetyOrders), entryPrice)
+}
+
+// endDCACycle descarta o ciclo atual após a venda
+func (t *BTCTrader) endDCACycle() {
+	t.dcaMutex.Lock()
+	defer t.dcaMutex.Unlock()
+	t.dca = nil
+}
+
+// dcaLadderExhausted indica se não restam ordens de segurança a executar
+func (t *BTCTrader) dcaLadderExhausted() bool {
+	t.dcaMutex.Lock()
+	defer t.dcaMutex.Unlock()
+	if t.dca == nil {
+		return true
+	}
+	for _, so := range t.dca.safetyOrders {
+		if so.Status == SafetyOrderPending {
+			return false
+		}
+	}
+	return true
+}
+
+// dcaShouldSell verifica se o preço atingiu o take-profit sobre o preço médio
+func (t *BTCTrader) dcaShouldSell(price float64) bool {
+	target := t.GetDCATakeProfitPrice()
+	if target == 0 || price < target {
+		return false
+	}
+	t.logImportant("✅ Take-profit DCA atingido - Preço: $%.2f, Alvo: $%.2f", price, target)
+	return true
+}
+
+// checkSafetyOrders executa a próxima ordem de segurança cujo preço de disparo foi atingido
+func (t *BTCTrader) checkSafetyOrders(price float64) {
+	if !t.dcaConfig.Enabled || !t.inPosition {
+		return
+	}
+
+	t.dcaMutex.Lock()
+	if t.dca == nil {
+		t.dcaMutex.Unlock()
+		return
+	}
+	next := -1
+	for i, so := range t.dca.safetyOrders {
+		if so.Status == SafetyOrderPending {
+			next = i
+			break
+		}
+	}
+	if next < 0 || price > t.dca.safetyOrders[next].TriggerPrice || t.now().Before(t.dca.retryAt) {
+		t.dcaMutex.Unlock()
+		return
+	}
+	if t.entriesBlocked() {
+		t.dcaMutex.Unlock()
+		t.log("Ordem de segurança aguardando o circuit breaker")
+		return
+	}
+
+	so := t.dca.safetyOrders[next]
+	amount := so.Amount
+	if t.dcaConfig.MaxCapital > 0 && t.dca.totalCost+amount > t.dcaConfig.MaxCapital {
+		amount = t.dcaConfig.MaxCapital - t.dca.totalCost
+	}
+	t.dcaMutex.Unlock()
+
+	quantity := t.calculateSafetyOrderQuantity(amount, price)
+	if quantity == 0 {
+		t.setSafetyOrderStatus(next, SafetyOrderBlocked, 0, 0)
+		t.logWarn("⚠️ Ordem de segurança #%d bloqueada (limite de capital ou saldo insuficiente)", so.Level)
+		return
+	}
+
+	if err := t.executeSafetyOrder(next, so.Level, price, quantity); err != nil {
+		t.logError("❌ Erro ao executar ordem de segurança #%d: %v", so.Level, err)
+		t.notifyError(fmt.Sprintf("erro ao executar ordem de segurança #%d", so.Level), err)
+		t.safetyOrderFailed(next, so.Level)
+	}
+}
+
+// safetyOrderFailed adia a próxima tentativa da ordem de segurança ou a bloqueia
+// depois de maxSafetyOrderFailures falhas seguidas
+func (t *BTCTrader) safetyOrderFailed(index, level int) {
+	t.dcaMutex.Lock()
+	if t.dca == nil {
+		t.dcaMutex.Unlock()
+		return
+	}
+	t.dca.failures++
+	failures := t.dca.failures
+	t.dca.retryAt = t.now().Add(safetyOrderRetryDelay)
+	if failures >= maxSafetyOrderFailures {
+		// A contagem recomeça para o próximo degrau
+		t.dca.failures = 0
+	}
+	t.dcaMutex.Unlock()
+
+	if failures >= maxSafetyOrderFailures {
+		t.setSafetyOrderStatus(index, SafetyOrderBlocked, 0, 0)
+		t.logWarn("⚠️ Ordem de segurança #%d bloqueada após %d falhas seguidas", level, failures)
+		return
+	}
+	t.log("Ordem de segurança #%d será tentada de novo em %s", level, safetyOrderRetryDelay)
+}
+
+// calculateSafetyOrderQuantity converte o valor da ordem de segurança em quantidade de BTC
+func (t *BTCTrader) calculateSafetyOrderQuantity(amount, price float64) float64 {
+	minOrderValue := 11.0
+
+	_, usdtBalance, err := t.getBalances(WithPriority(context.Background(), PriorityCritical))
+	if err == nil && usdtBalance < amount {
+		amount = usdtBalance
+	}
+
+	if amount < minOrderValue {
+		return 0
+	}
+
+	quantity := math.Floor(amount/price*100000) / 100000
+	if quantity*price < minOrderValue {
+		return 0
+	}
+	return quantity
+}
+
+func (t *BTCTrader) executeSafetyOrder(index, level int, price, quantity float64) error {
+	fill, err := t.placeMarketOrder(binance.SideTypeBuy, quantity, price)
+	if err != nil {
+		return err
+	}
+
+	t.setSafetyOrderStatus(index, SafetyOrderFilled, fill.Price, fill.Quantity)
+
+	t.dcaMutex.Lock()
+	t.dca.failures = 0
+	t.dca.retryAt = time.Time{}
+	t.dca.totalQty += fill.Quantity
+	t.dca.totalCost += fill.Price * fill.Quantity
+	average := t.dca.totalCost / t.dca.totalQty
+	totalQty := t.dca.totalQty
+	t.dcaMutex.Unlock()
+
+	// A posição passa a ser representada pelo preço médio e quantidade total
+	t.positions["BTC"] = average
+	t.lastBuyQuantity = totalQty
+
+	t.recordFill(fill, 0, tradeSignal{source: SourceDCA})
+
+	t.logImportant("🪜 Ordem de segurança #%d executada - Preço: $%.2f, Quantidade: %.5f BTC, Novo preço médio: $%.2f",
+		level, fill.Price, fill.Quantity, average)
+	return nil
+}
+
+func (t *BTCTrader) setSafetyOrderStatus(index int, status string, price, quantity float64) {
+	t.dcaMutex.Lock()
+	defer t.dcaMutex.Unlock()
+	if t.dca == nil || index >= len(t.dca.safetyOrders) {
+		return
+	}
+	t.dca.safetyOrders[index].Status = status
+	t.dca.safetyOrders[index].FillPrice = price
+	t.dca.safetyOrders[index].Quantity = quantity
+}
+
+// capDCABaseAmount limita o valor da ordem base ao capital máximo do ciclo
+func (t *BTCTrader) capDCABaseAmount(amount float64) float64 {
+	if t.dcaConfig.Enabled && t.dcaConfig.MaxCapital > 0 && amount > t.dcaConfig.MaxCapital {
+		return t.dcaConfig.MaxCapital
+	}
+	return amount
+}
@@ -0,0 +1,140 @@
+package traderbot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/casarotto/binance-bot/internal/config"
+)
+
+// Idade máxima do livro para o filtro de liquidez; depois disso as entradas são bloqueadas
+const depthMaxAge = 5 * time.Second
+
+// Níveis de cada lado exibidos na TUI
+const depthLadderLevels = 5
+
+// DepthStatus resume o livro de ofertas e o filtro de liquidez para a TUI
+type DepthStatus struct {
+	Enabled     bool        `json:"enabled"`
+	Live        bool        `json:"live"` // o depth stream está assinado
+	Bids        []BookLevel `json:"bids,omitempty"`
+	Asks        []BookLevel `json:"asks,omitempty"`
+	SpreadBps   float64     `json:"spread_bps"`
+	SlippageBps float64     `json:"slippage_bps"` // estimado para o valor da próxima compra
+	Updated     time.Time   `json:"updated"`
+	Blocked     string      `json:"blocked,omitempty"` // motivo do bloqueio das entradas, se houver
+}
+
+// SetDepthConfig habilita o livro de ofertas e o filtro de spread e liquidez das
+// entradas. Deve ser chamado antes de Start.
+func (t *BTCTrader) SetDepthConfig(cfg config.DepthConfig) {
+	t.depthConfig = cfg
+	if !cfg.Enabled {
+		return
+	}
+	t.book = NewOrderBook()
+	t.logImportant("📚 Filtro de liquidez ativo - Spread máx.: %s | Deslizamento máx.: %s",
+		formatBpsLimit(cfg.MaxSpreadBps), formatBpsLimit(cfg.MaxSlippageBps))
+}
+
+func formatBpsLimit(bps float64) string {
+	if bps == 0 {
+		return "sem limite"
+	}
+	return fmt.Sprintf("%.1f bps", bps)
+}
+
+// startDepthStream assina o livro de ofertas quando o filtro está habilitado. Sem o
+// depth (ex. backtest com candles) o filtro fica desligado e as entradas seguem liberadas.
+func (t *BTCTrader) startDepthStream(errHandler binance.ErrHandler) []wsStream {
+	if !t.depthConfig.Enabled {
+		return nil
+	}
+	doneC, stopC, err := t.exchange.DepthStream(t.book.Apply, errHandler)
+	if err != nil {
+		t.depthLive.Store(false)
+		t.logWarn("⚠️ Livro de ofertas indisponível, filtro de liquidez desligado: %v", err)
+		return nil
+	}
+	t.depthLive.Store(true)
+	return []wsStream{{interval: "depth", done: doneC, stop: stopC}}
+}
+
+// checkLiquidity verifica o spread e o deslizamento estimado de uma compra de quantity
+// BTC no livro atual. Retorna o motivo do bloqueio, registrado no log quando muda.
+func (t *BTCTrader) checkLiquidity(quantity float64) error {
+	if !t.depthConfig.Enabled || !t.depthLive.Load() {
+		return nil
+	}
+	err := t.liquidityError(quantity)
+
+	t.depthMutex.Lock()
+	defer t.depthMutex.Unlock()
+	switch {
+	case err != nil && err.Error() != t.depthBlocked:
+		t.logWarn("🚧 Entrada bloqueada pelo filtro de liquidez: %v", err)
+		t.depthBlocked = err.Error()
+	case err == nil && t.depthBlocked != "":
+		t.logImportant("📚 Filtro de liquidez liberou as entradas")
+		t.depthBlocked = ""
+	}
+	return err
+}
+
+func (t *BTCTrader) liquidityError(quantity float64) error {
+	updated := t.book.UpdatedAt()
+	if updated.IsZero() {
+		return fmt.Errorf("livro de ofertas ainda não recebido")
+	}
+	if age := t.now().Sub(updated); age > depthMaxAge {
+		return fmt.Errorf("livro de ofertas desatualizado há %s", age.Round(time.Second))
+	}
+
+	spread, slippage, err := t.bookCosts(quantity)
+	if err != nil {
+		return err
+	}
+	if limit := t.depthConfig.MaxSpreadBps; limit > 0 && spread > limit {
+		return fmt.Errorf("spread de %.1f bps acima do limite de %.1f bps", spread, limit)
+	}
+	if limit := t.depthConfig.MaxSlippageBps; limit > 0 && slippage > limit {
+		return fmt.Errorf("deslizamento estimado de %.1f bps para %.5f BTC acima do limite de %.1f bps", slippage, quantity, limit)
+	}
+	return nil
+}
+
+// bookCosts calcula o spread e o deslizamento de uma compra a mercado de quantity BTC
+// sobre a melhor oferta de venda, em pontos-base
+func (t *BTCTrader) bookCosts(quantity float64) (spread, slippage float64, err error) {
+	bid, ask, ok := t.book.Best()
+	if !ok {
+		return 0, 0, fmt.Errorf("livro de ofertas sem compradores ou vendedores")
+	}
+	spread = (ask - bid) / ((ask + bid) / 2) * 10000
+	if quantity <= 0 {
+		return spread, 0, nil
+	}
+	fill, ok := t.book.FillPrice(binance.SideTypeBuy, quantity, ask)
+	if !ok {
+		return spread, 0, fmt.Errorf("livro sem profundidade para comprar %.5f BTC", quantity)
+	}
+	return spread, (fill - ask) / ask * 10000, nil
+}
+
+// GetDepth retorna os melhores níveis do livro e o estado do filtro de liquidez
+func (t *BTCTrader) GetDepth() DepthStatus {
+	status := DepthStatus{Enabled: t.depthConfig.Enabled, Live: t.depthLive.Load()}
+	if !status.Enabled {
+		return status
+	}
+	status.Bids, status.Asks = t.book.Top(depthLadderLevels)
+	status.Updated = t.book.UpdatedAt()
+	if _, ask, ok := t.book.Best(); ok {
+		status.SpreadBps, status.SlippageBps, _ = t.bookCosts(t.GetNextTradeAmount() / ask)
+	}
+	t.depthMutex.Lock()
+	status.Blocked = t.depthBlocked
+	t.depthMutex.Unlock()
+	return status
+}
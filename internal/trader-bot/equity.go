@@ -0,0 +1,140 @@
+package traderbot
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// quantidade de pontos do patrimônio mantidos em memória para a TUI
+const maxEquityPoints = 1000
+
+// EquityPoint é uma marcação a mercado do patrimônio: USDT + BTC × preço
+type EquityPoint struct {
+	Timestamp   int64   `json:"timestamp"` // segundos, como em Trade
+	Price       float64 `json:"price"`
+	BTCBalance  float64 `json:"btc_balance"`
+	USDTBalance float64 `json:"usdt_balance"`
+	Equity      float64 `json:"equity"`
+	InPosition  bool    `json:"in_position"`
+}
+
+// SetEquityTracking define o arquivo (JSON lines) e o intervalo das marcações do
+// patrimônio e carrega os pontos mais recentes já gravados. interval <= 0 desabilita.
+func (t *BTCTrader) SetEquityTracking(path string, interval time.Duration) {
+	t.equityFile = path
+	t.equityInterval = interval
+
+	points, err := LoadEquityCurve(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			t.logWarn("⚠️ Não foi possível carregar o histórico de patrimônio: %v", err)
+		}
+		return
+	}
+	if len(points) > maxEquityPoints {
+		points = points[len(points)-maxEquityPoints:]
+	}
+	t.equityMutex.Lock()
+	t.equity = points
+	t.equityMutex.Unlock()
+}
+
+// LoadEquityCurve lê um arquivo de patrimônio gravado pelo bot (um ponto JSON por linha)
+func LoadEquityCurve(path string) ([]EquityPoint, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var points []EquityPoint
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var point EquityPoint
+		if err := json.Unmarshal(scanner.Bytes(), &point); err != nil {
+			return nil, fmt.Errorf("linha %d de %s: %v", line, path, err)
+		}
+		points = append(points, point)
+	}
+	return points, scanner.Err()
+}
+
+// RecordEquity marca o patrimônio a mercado no intervalo configurado até ctx ser cancelado
+func (t *BTCTrader) RecordEquity(ctx context.Context) {
+	if t.equityInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(t.equityInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := t.recordEquitySnapshot(); err != nil {
+				t.log("Marcação do patrimônio ignorada: %v", err)
+			}
+		}
+	}
+}
+
+func (t *BTCTrader) recordEquitySnapshot() error {
+	candle, ok := t.GetLastCandle()
+	if !ok {
+		return fmt.Errorf("preço atual ainda não disponível")
+	}
+	btc, usdt, err := t.cachedBalances(context.Background())
+	if err != nil {
+		return err
+	}
+
+	point := EquityPoint{
+		Timestamp:   t.now().Unix(),
+		Price:       candle.Close,
+		BTCBalance:  btc,
+		USDTBalance: usdt,
+		Equity:      usdt + btc*candle.Close,
+		InPosition:  t.IsInPosition(),
+	}
+
+	t.equityMutex.Lock()
+	t.equity = append(t.equity, point)
+	if len(t.equity) > maxEquityPoints {
+		t.equity = t.equity[len(t.equity)-maxEquityPoints:]
+	}
+	t.equityMutex.Unlock()
+
+	return t.appendEquityPoint(point)
+}
+
+func (t *BTCTrader) appendEquityPoint(point EquityPoint) error {
+	if t.equityFile == "" {
+		return nil
+	}
+	data, err := json.Marshal(point)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(t.equityFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir arquivo de patrimônio: %v", err)
+	}
+	defer file.Close()
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// GetEquityCurve retorna uma cópia das marcações mais recentes do patrimônio
+func (t *BTCTrader) GetEquityCurve() []EquityPoint {
+	t.equityMutex.Lock()
+	defer t.equityMutex.Unlock()
+	return append([]EquityPoint(nil), t.equity...)
+}
@@ -0,0 +1,170 @@
+package traderbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/adshao/go-binance/v2/common"
+)
+
+// ErrorCategory classifica os erros da corretora pela reação do bot
+type ErrorCategory string
+
+const (
+	CategoryInsufficientBalance ErrorCategory = "insufficient_balance" // saldo livre menor que a ordem
+	CategoryMinNotional         ErrorCategory = "min_notional"         // valor da ordem abaixo do mínimo
+	CategoryInvalidQuantity     ErrorCategory = "invalid_quantity"     // quantidade fora do LOT_SIZE ou da precisão
+	CategoryRateLimit           ErrorCategory = "rate_limit"           // 429 ou descartada pelo limitador
+	CategoryIPBan               ErrorCategory = "ip_ban"               // 418: IP banido por excesso de requisições
+	CategoryTimestamp           ErrorCategory = "timestamp"            // -1021: fora do recvWindow
+	CategoryAuth                ErrorCategory = "auth"                 // chave de API ou assinatura recusada
+	CategoryNetwork             ErrorCategory = "network"              // timeout ou falha de conexão
+	CategoryUnknownOutcome      ErrorCategory = "unknown_outcome"      // a ordem pode ter sido executada
+	CategoryRejected            ErrorCategory = "rejected"             // demais recusas da API
+	CategoryOther               ErrorCategory = "other"
+)
+
+// ErrorAction é a reação do bot a uma categoria de erro
+type ErrorAction string
+
+const (
+	// ActionResize reenvia a ordem uma vez com a quantidade ajustada ao saldo ou aos filtros
+	ActionResize ErrorAction = "resize"
+	// ActionBackoff espera antes de novas requisições: o limitador respeita o Retry-After,
+	// o relógio é ressincronizado e as falhas de rede passam pelas novas tentativas
+	ActionBackoff ErrorAction = "backoff"
+	// ActionHalt abre o circuit breaker na hora, até o rearme manual
+	ActionHalt ErrorAction = "halt"
+	// ActionReject descarta a ordem
+	ActionReject ErrorAction = "reject"
+)
+
+// Action retorna a reação do bot à categoria
+func (c ErrorCategory) Action() ErrorAction {
+	switch c {
+	case CategoryInsufficientBalance, CategoryInvalidQuantity:
+		return ActionResize
+	case CategoryRateLimit, CategoryTimestamp, CategoryNetwork:
+		return ActionBackoff
+	case CategoryIPBan, CategoryAuth, CategoryUnknownOutcome:
+		return ActionHalt
+	}
+	return ActionReject
+}
+
+// ExchangeError é um erro da corretora classificado, com o código da Binance quando houver
+type ExchangeError struct {
+	Category ErrorCategory
+	Code     int64 // código da Binance (0 para erros fora da API)
+	Err      error
+}
+
+func (e *ExchangeError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Category, e.Err)
+}
+
+func (e *ExchangeError) Unwrap() error {
+	return e.Err
+}
+
+// HTTPStatusError é uma resposta de erro da Binance sem o código da API
+type HTTPStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPStatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("HTTP %d", e.StatusCode)
+	}
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+}
+
+// ClassifyError envolve err em um ExchangeError com a categoria do erro. Retorna nil
+// para err nil e o próprio erro se ele já estiver classificado.
+func ClassifyError(err error) *ExchangeError {
+	if err == nil {
+		return nil
+	}
+	var classified *ExchangeError
+	if errors.As(err, &classified) {
+		return classified
+	}
+
+	e := &ExchangeError{Category: CategoryOther, Err: err}
+	var apiErr *common.APIError
+	var statusErr *HTTPStatusError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, ErrOrderUnknown):
+		e.Category = CategoryUnknownOutcome
+	case errors.Is(err, ErrRateLimited):
+		e.Category = CategoryRateLimit
+	// Antes de net.Error: o http.Client entrega o erro do transporte num *url.Error
+	case errors.As(err, &statusErr):
+		e.Category = httpStatusCategory(statusErr.StatusCode)
+		// Sem o *url.Error, que traz a URL assinada da requisição
+		e.Err = statusErr
+	case errors.As(err, &apiErr):
+		e.Code = apiErr.Code
+		e.Category = apiErrorCategory(apiErr)
+	case errors.As(err, &netErr), errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		e.Category = CategoryNetwork
+	}
+	return e
+}
+
+// apiErrorCategory mapeia os códigos de erro da Binance. Alguns códigos agrupam
+// motivos diferentes que só a mensagem distingue.
+func apiErrorCategory(err *common.APIError) ErrorCategory {
+	message := strings.ToLower(err.Message)
+	switch err.Code {
+	case -2010:
+		if strings.Contains(message, "insufficient balance") {
+			return CategoryInsufficientBalance
+		}
+	case -1013:
+		switch {
+		case strings.Contains(message, "notional"):
+			return CategoryMinNotional
+		case strings.Contains(message, "lot_size"), strings.Contains(message, "quantity"):
+			return CategoryInvalidQuantity
+		}
+	case -1111:
+		return CategoryInvalidQuantity
+	case -1003:
+		if strings.Contains(message, "banned") {
+			return CategoryIPBan
+		}
+		return CategoryRateLimit
+	case -1015:
+		return CategoryRateLimit
+	case -1021:
+		return CategoryTimestamp
+	case -1022, -2014, -2015:
+		return CategoryAuth
+	case -1001, -1007:
+		return CategoryNetwork
+	}
+	return CategoryRejected
+}
+
+// httpStatusCategory classifica uma resposta de erro sem o código da Binance. Só
+// os erros do servidor (5xx) deixam o resultado da requisição em aberto.
+func httpStatusCategory(status int) ErrorCategory {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return CategoryRateLimit
+	case status == http.StatusTeapot:
+		return CategoryIPBan
+	case status >= http.StatusInternalServerError:
+		return CategoryNetwork
+	}
+	return CategoryRejected
+}
@@ -0,0 +1,188 @@
+package traderbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/common"
+)
+
+// MarketData é a fonte dos klines: a Binance ao vivo ou uma gravação em replay
+type MarketData interface {
+	// Klines retorna o histórico recente de um intervalo
+	Klines(ctx context.Context, interval string, limit int) ([]*binance.Kline, error)
+	// KlineStream assina os klines do BTCUSDT no intervalo, como binance.WsKlineServe
+	KlineStream(interval string, handler binance.WsKlineHandler, errHandler binance.ErrHandler) (done, stop chan struct{}, err error)
+	// DepthStream assina as atualizações do livro de ofertas do BTCUSDT
+	DepthStream(handler func(DepthUpdate), errHandler binance.ErrHandler) (done, stop chan struct{}, err error)
+	// AggTradeStream assina os negócios agregados do BTCUSDT, como binance.WsAggTradeServe
+	AggTradeStream(handler binance.WsAggTradeHandler, errHandler binance.ErrHandler) (done, stop chan struct{}, err error)
+}
+
+// Exchange é a corretora usada pelo trader (sempre no par BTCUSDT): a Binance ou
+// a corretora simulada (PaperExchange)
+type Exchange interface {
+	MarketData
+	Account(ctx context.Context) (*binance.Account, error)
+	CreateOrder(ctx context.Context, order OrderRequest) (*binance.CreateOrderResponse, error)
+	GetOrder(ctx context.Context, orderID int64) (*binance.Order, error)
+	// GetOrderByClientID consulta uma ordem pelo newClientOrderId informado no envio
+	GetOrderByClientID(ctx context.Context, clientOrderID string) (*binance.Order, error)
+	CancelOrder(ctx context.Context, orderID int64) error
+	OpenOrders(ctx context.Context) ([]*binance.Order, error)
+	ListTrades(ctx context.Context, limit int) ([]*binance.TradeV3, error)
+	ExchangeInfo(ctx context.Context) (*binance.ExchangeInfo, error)
+}
+
+// OrderRequest descreve uma ordem a mercado ou limitada (GTC)
+type OrderRequest struct {
+	Side     binance.SideType
+	Type     binance.OrderType
+	Quantity float64
+	Price    float64 // só para ordens limitadas
+
+	ClientOrderID string // newClientOrderId (vazio = gerado pela corretora)
+}
+
+// binanceExchange implementa Exchange com o cliente REST e os WebSockets da Binance
+type binanceExchange struct {
+	client       atomic.Pointer[binance.Client] // substituído por uma cópia com o novo TimeOffset a cada sincronização
+	recvWindow   atomic.Int64                   // recvWindow das requisições assinadas, em ms (0 = padrão da Binance)
+	timestampErr chan struct{}                  // avisado quando uma requisição é recusada pelo timestamp
+}
+
+// NewBinanceExchange retorna a Exchange que opera na Binance com o cliente informado
+func NewBinanceExchange(client *binance.Client) Exchange {
+	b := &binanceExchange{timestampErr: make(chan struct{}, 1)}
+	b.client.Store(client)
+	return b
+}
+
+func (b *binanceExchange) api() *binance.Client {
+	return b.client.Load()
+}
+
+// SyncTime mede a diferença entre o relógio local e o do servidor, considerando metade
+// do tempo de ida e volta, e passa a descontá-la do timestamp das requisições assinadas
+func (b *binanceExchange) SyncTime(ctx context.Context) (offset, rtt time.Duration, err error) {
+	client := b.api()
+	start := time.Now()
+	serverTime, err := client.NewServerTimeService().Do(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	rtt = time.Since(start)
+	offset = start.Add(rtt / 2).Sub(time.UnixMilli(serverTime))
+
+	// O TimeOffset é lido sem trava pelo cliente; uma cópia evita alterar o
+	// cliente usado pelas requisições em andamento
+	synced := *client
+	synced.TimeOffset = offset.Milliseconds()
+	b.client.Store(&synced)
+	return offset, rtt, nil
+}
+
+func (b *binanceExchange) SetRecvWindow(window time.Duration) {
+	b.recvWindow.Store(window.Milliseconds())
+}
+
+func (b *binanceExchange) TimestampErrors() <-chan struct{} {
+	return b.timestampErr
+}
+
+// signed retorna as opções das requisições assinadas
+func (b *binanceExchange) signed() []binance.RequestOption {
+	if window := b.recvWindow.Load(); window > 0 {
+		return []binance.RequestOption{binance.WithRecvWindow(window)}
+	}
+	return nil
+}
+
+// checkTimestamp avisa quando a Binance recusa o timestamp de uma requisição
+// assinada (-1021: fora do recvWindow), para o relógio ser ressincronizado
+func (b *binanceExchange) checkTimestamp(err error) error {
+	var apiErr *common.APIError
+	if errors.As(err, &apiErr) && apiErr.Code == -1021 {
+		select {
+		case b.timestampErr <- struct{}{}:
+		default:
+		}
+	}
+	return err
+}
+
+func (b *binanceExchange) Klines(ctx context.Context, interval string, limit int) ([]*binance.Kline, error) {
+	return b.api().NewKlinesService().Symbol("BTCUSDT").Interval(interval).Limit(limit).Do(ctx)
+}
+
+func (b *binanceExchange) KlineStream(interval string, handler binance.WsKlineHandler, errHandler binance.ErrHandler) (chan struct{}, chan struct{}, error) {
+	return binance.WsKlineServe("BTCUSDT", interval, handler, errHandler)
+}
+
+// DepthStream assina os 20 melhores níveis de cada lado a cada 100ms; cada evento
+// substitui o livro inteiro
+func (b *binanceExchange) DepthStream(handler func(DepthUpdate), errHandler binance.ErrHandler) (chan struct{}, chan struct{}, error) {
+	return binance.WsPartialDepthServe100Ms("BTCUSDT", "20", func(event *binance.WsPartialDepthEvent) {
+		handler(DepthUpdate{Time: time.Now().UnixMilli(), Bids: event.Bids, Asks: event.Asks, Snapshot: true})
+	}, errHandler)
+}
+
+func (b *binanceExchange) AggTradeStream(handler binance.WsAggTradeHandler, errHandler binance.ErrHandler) (chan struct{}, chan struct{}, error) {
+	return binance.WsAggTradeServe("BTCUSDT", handler, errHandler)
+}
+
+func (b *binanceExchange) Account(ctx context.Context) (*binance.Account, error) {
+	account, err := b.api().NewGetAccountService().Do(ctx, b.signed()...)
+	return account, b.checkTimestamp(err)
+}
+
+func (b *binanceExchange) CreateOrder(ctx context.Context, order OrderRequest) (*binance.CreateOrderResponse, error) {
+	service := b.api().NewCreateOrderService().
+		Symbol("BTCUSDT").
+		Side(order.Side).
+		Type(order.Type).
+		Quantity(fmt.Sprintf("%.5f", order.Quantity))
+	if order.Type == binance.OrderTypeLimit {
+		service = service.
+			TimeInForce(binance.TimeInForceTypeGTC).
+			Price(fmt.Sprintf("%.2f", order.Price))
+	}
+	if order.ClientOrderID != "" {
+		service = service.NewClientOrderID(order.ClientOrderID)
+	}
+	response, err := service.Do(ctx, b.signed()...)
+	return response, b.checkTimestamp(err)
+}
+
+func (b *binanceExchange) GetOrder(ctx context.Context, orderID int64) (*binance.Order, error) {
+	order, err := b.api().NewGetOrderService().Symbol("BTCUSDT").OrderID(orderID).Do(ctx, b.signed()...)
+	return order, b.checkTimestamp(err)
+}
+
+func (b *binanceExchange) GetOrderByClientID(ctx context.Context, clientOrderID string) (*binance.Order, error) {
+	order, err := b.api().NewGetOrderService().Symbol("BTCUSDT").OrigClientOrderID(clientOrderID).Do(ctx, b.signed()...)
+	return order, b.checkTimestamp(err)
+}
+
+func (b *binanceExchange) CancelOrder(ctx context.Context, orderID int64) error {
+	_, err := b.api().NewCancelOrderService().Symbol("BTCUSDT").OrderID(orderID).Do(ctx, b.signed()...)
+	return b.checkTimestamp(err)
+}
+
+func (b *binanceExchange) OpenOrders(ctx context.Context) ([]*binance.Order, error) {
+	orders, err := b.api().NewListOpenOrdersService().Symbol("BTCUSDT").Do(ctx, b.signed()...)
+	return orders, b.checkTimestamp(err)
+}
+
+func (b *binanceExchange) ListTrades(ctx context.Context, limit int) ([]*binance.TradeV3, error) {
+	trades, err := b.api().NewListTradesService().Symbol("BTCUSDT").Limit(limit).Do(ctx, b.signed()...)
+	return trades, b.checkTimestamp(err)
+}
+
+func (b *binanceExchange) ExchangeInfo(ctx context.Context) (*binance.ExchangeInfo, error) {
+	return b.api().NewExchangeInfoService().Symbol("BTCUSDT").Do(ctx)
+}
@@ -0,0 +1,91 @@
+package traderbot
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// tempo que os filtros do símbolo ficam em cache antes de serem consultados de novo
+const filtersTTL = time.Hour
+
+// symbolFilters são os limites de ordem do BTCUSDT definidos pela corretora
+type symbolFilters struct {
+	minQty      float64
+	maxQty      float64
+	stepSize    float64
+	minNotional float64
+	fetchedAt   time.Time
+}
+
+// getSymbolFilters retorna os filtros do BTCUSDT, consultando a corretora se o cache venceu
+func (t *BTCTrader) getSymbolFilters() (symbolFilters, error) {
+	t.filtersMutex.Lock()
+	defer t.filtersMutex.Unlock()
+
+	if time.Since(t.filters.fetchedAt) < filtersTTL {
+		return t.filters, nil
+	}
+
+	info, err := t.exchange.ExchangeInfo(context.Background())
+	if err != nil {
+		return symbolFilters{}, fmt.Errorf("erro ao consultar filtros do símbolo: %v", err)
+	}
+	if len(info.Symbols) == 0 {
+		return symbolFilters{}, fmt.Errorf("símbolo BTCUSDT não encontrado na corretora")
+	}
+
+	symbol := info.Symbols[0]
+	var filters symbolFilters
+	if lot := symbol.LotSizeFilter(); lot != nil {
+		filters.minQty, _ = strconv.ParseFloat(lot.MinQuantity, 64)
+		filters.maxQty, _ = strconv.ParseFloat(lot.MaxQuantity, 64)
+		filters.stepSize, _ = strconv.ParseFloat(lot.StepSize, 64)
+	}
+	if notional := symbol.NotionalFilter(); notional != nil && notional.ApplyMinToMarket {
+		filters.minNotional, _ = strconv.ParseFloat(notional.MinNotional, 64)
+	}
+	filters.fetchedAt = time.Now()
+
+	t.filters = filters
+	return filters, nil
+}
+
+// invalidateSymbolFilters força uma nova consulta dos filtros na próxima ordem
+func (t *BTCTrader) invalidateSymbolFilters() {
+	t.filtersMutex.Lock()
+	defer t.filtersMutex.Unlock()
+	t.filters.fetchedAt = time.Time{}
+}
+
+// checkFilters verifica se uma ordem a mercado de quantity ao preço price seria aceita
+func (t *BTCTrader) checkFilters(quantity, price float64) error {
+	if quantity <= 0 {
+		return fmt.Errorf("quantidade calculada é zero (saldo insuficiente ou abaixo do valor mínimo)")
+	}
+
+	filters, err := t.getSymbolFilters()
+	if err != nil {
+		return err
+	}
+
+	if filters.minQty > 0 && quantity < filters.minQty {
+		return fmt.Errorf("quantidade %.8f abaixo do mínimo do símbolo (%.8f)", quantity, filters.minQty)
+	}
+	if filters.maxQty > 0 && quantity > filters.maxQty {
+		return fmt.Errorf("quantidade %.8f acima do máximo do símbolo (%.8f)", quantity, filters.maxQty)
+	}
+	if filters.stepSize > 0 {
+		// tolerância para a imprecisão do ponto flutuante
+		steps := quantity / filters.stepSize
+		if math.Abs(steps-math.Round(steps)) > 1e-6 {
+			return fmt.Errorf("quantidade %.8f não é múltipla do passo do símbolo (%.8f)", quantity, filters.stepSize)
+		}
+	}
+	if filters.minNotional > 0 && quantity*price < filters.minNotional {
+		return fmt.Errorf("valor da ordem %.2f USDT abaixo do mínimo do símbolo (%.2f USDT)", quantity*price, filters.minNotional)
+	}
+	return nil
+}
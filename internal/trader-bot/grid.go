@@ -0,0 +1,349 @@
+package traderbot
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/casarotto/binance-bot/internal/config"
+	"github.com/casarotto/binance-bot/internal/notify"
+)
+
+// GridLevel representa um grid (par de níveis de compra e venda adjacentes)
+type GridLevel struct {
+	Index      int
+	BuyPrice   float64 // nível inferior, onde fica a ordem de compra
+	SellPrice  float64 // nível superior, onde fica a ordem de venda
+	Quantity   float64 // quantidade de BTC negociada neste grid
+	Side       string  // próxima ordem do grid: "buy" ou "sell"
+	OrderID    int64   // ordem em aberto na corretora (0 se nenhuma)
+	EntryPrice float64 // preço executado da última compra
+	Profit     float64 // lucro realizado acumulado em USDT (já descontadas as taxas)
+	Trips      int     // ciclos compra/venda completos
+}
+
+// Espera antes de tentar montar o grid de novo após uma falha (ex. a compra inicial)
+const gridInitRetryDelay = time.Minute
+
+// gridState guarda o estado da estratégia de grid. Os níveis só são alterados
+// pela goroutine do WebSocket; gridMutex protege as leituras feitas pela TUI.
+type gridState struct {
+	levels      []GridLevel
+	initialized bool
+	paused      bool
+	lastPoll    time.Time
+	retryAt     time.Time // nova tentativa de initGrid após uma falha
+}
+
+// SetGridConfig habilita/configura a estratégia de grid
+func (t *BTCTrader) SetGridConfig(cfg config.GridConfig) {
+	t.gridMutex.Lock()
+	defer t.gridMutex.Unlock()
+	t.gridConfig = cfg
+	if cfg.Enabled {
+		t.logImportant("🔲 Estratégia de grid ativa - Faixa: $%.2f - $%.2f, %d níveis, $%.2f por ordem",
+			cfg.LowerPrice, cfg.UpperPrice, cfg.Levels, cfg.OrderAmount)
+	}
+}
+
+// IsGridEnabled retorna se a estratégia de grid está ativa
+func (t *BTCTrader) IsGridEnabled() bool {
+	return t.gridConfig.Enabled
+}
+
+// IsGridPaused retorna se o grid está pausado por o preço estar fora da faixa
+func (t *BTCTrader) IsGridPaused() bool {
+	t.gridMutex.Lock()
+	defer t.gridMutex.Unlock()
+	return t.grid.paused
+}
+
+// GetGridRange retorna os limites da faixa do grid
+func (t *BTCTrader) GetGridRange() (lower, upper float64) {
+	return t.gridConfig.LowerPrice, t.gridConfig.UpperPrice
+}
+
+// GetGridLevels retorna uma cópia dos grids e seus estados
+func (t *BTCTrader) GetGridLevels() []GridLevel {
+	t.gridMutex.Lock()
+	defer t.gridMutex.Unlock()
+	levels := make([]GridLevel, len(t.grid.levels))
+	copy(levels, t.grid.levels)
+	return levels
+}
+
+// GetGridProfit retorna o lucro realizado somado de todos os grids
+func (t *BTCTrader) GetGridProfit() float64 {
+	t.gridMutex.Lock()
+	defer t.gridMutex.Unlock()
+	total := 0.0
+	for _, level := range t.grid.levels {
+		total += level.Profit
+	}
+	return total
+}
+
+// updateGridLevel altera um grid protegendo a leitura concorrente da TUI
+func (t *BTCTrader) updateGridLevel(i int, update func(level *GridLevel)) {
+	t.gridMutex.Lock()
+	defer t.gridMutex.Unlock()
+	update(&t.grid.levels[i])
+}
+
+func (t *BTCTrader) setGridPaused(paused bool) {
+	t.gridMutex.Lock()
+	defer t.gridMutex.Unlock()
+	t.grid.paused = paused
+}
+
+// onGridTick é chamado a cada preço recebido quando a estratégia de grid está ativa
+func (t *BTCTrader) onGridTick(price float64) {
+	if !t.grid.initialized {
+		if t.now().Before(t.grid.retryAt) {
+			return
+		}
+		if err := t.initGrid(price); err != nil {
+			t.grid.retryAt = t.now().Add(gridInitRetryDelay)
+			t.logError("❌ Erro ao iniciar grid (nova tentativa em %s): %v", gridInitRetryDelay, err)
+		}
+		return
+	}
+
+	outOfRange := price < t.gridConfig.LowerPrice || price > t.gridConfig.UpperPrice
+	if outOfRange && !t.grid.paused {
+		t.logImportant("⏸️ Preço $%.2f fora da faixa do grid - pausando e cancelando ordens", price)
+		t.cancelGridOrders()
+		t.setGridPaused(true)
+		return
+	}
+	if t.grid.paused {
+		if outOfRange {
+			return
+		}
+		t.logImportant("▶️ Preço $%.2f de volta à faixa do grid - retomando", price)
+		t.setGridPaused(false)
+	}
+
+	if t.now().Sub(t.grid.lastPoll) < t.gridConfig.PollInterval {
+		return
+	}
+	t.grid.lastPoll = t.now()
+
+	t.pollGridOrders()
+	t.placeGridOrders(price)
+}
+
+// initGrid monta os grids e compra o BTC necessário para as vendas acima do preço atual
+func (t *BTCTrader) initGrid(price float64) error {
+	cfg := t.gridConfig
+	step := (cfg.UpperPrice - cfg.LowerPrice) / float64(cfg.Levels-1)
+
+	levels := make([]GridLevel, 0, cfg.Levels-1)
+	inventory := 0.0
+	for i := 0; i < cfg.Levels-1; i++ {
+		buyPrice := math.Round((cfg.LowerPrice+step*float64(i))*100) / 100
+		sellPrice := math.Round((cfg.LowerPrice+step*float64(i+1))*100) / 100
+		level := GridLevel{
+			Index:     i,
+			BuyPrice:  buyPrice,
+			SellPrice: sellPrice,
+			Quantity:  t.gridLevelQuantity(buyPrice),
+			Side:      "buy",
+		}
+		// Grids inteiramente acima do preço começam vendidos: precisam de BTC em carteira
+		if buyPrice >= price {
+			level.Side = "sell"
+			inventory += level.Quantity
+		}
+		levels = append(levels, level)
+	}
+
+	if inventory > 0 {
+		fill, err := t.placeMarketOrder(binance.SideTypeBuy, inventory, price)
+		if err != nil {
+			return err
+		}
+		t.recordFill(fill, 0, tradeSignal{source: SourceGrid})
+		t.logImportant("💰 Compra inicial do grid - Preço: $%.2f, Quantidade: %.5f BTC", fill.Price, fill.Quantity)
+		for i := range levels {
+			if levels[i].Side == "sell" {
+				levels[i].EntryPrice = fill.Price
+			}
+		}
+	}
+
+	t.gridMutex.Lock()
+	t.grid.levels = levels
+	t.grid.initialized = true
+	t.gridMutex.Unlock()
+
+	t.placeGridOrders(price)
+	t.grid.lastPoll = t.now()
+	return nil
+}
+
+// gridLevelQuantity retorna a quantidade de BTC de um grid com compra em buyPrice
+func (t *BTCTrader) gridLevelQuantity(buyPrice float64) float64 {
+	return math.Floor(t.gridConfig.OrderAmount/buyPrice*100000) / 100000
+}
+
+// placeGridOrders coloca as ordens que faltam, respeitando o lado correto do preço atual
+func (t *BTCTrader) placeGridOrders(price float64) {
+	for i, level := range t.grid.levels {
+		if level.OrderID != 0 {
+			continue
+		}
+
+		var side binance.SideType
+		var orderPrice float64
+		if level.Side == "buy" {
+			if level.BuyPrice >= price {
+				continue // aguardar o preço voltar acima do nível para não executar a mercado
+			}
+			if t.entriesBlocked() {
+				continue // compras do grid suspensas pelo circuit breaker
+			}
+			side, orderPrice = binance.SideTypeBuy, level.BuyPrice
+		} else {
+			if level.SellPrice <= price {
+				continue
+			}
+			side, orderPrice = binance.SideTypeSell, level.SellPrice
+		}
+
+		orderID, err := t.placeLimitOrder(side, level.Quantity, orderPrice)
+		if err != nil {
+			t.logError("❌ Erro ao colocar ordem do grid #%d (%s a $%.2f): %v", level.Index, level.Side, orderPrice, err)
+			t.notifyError(fmt.Sprintf("erro ao colocar ordem do grid #%d", level.Index), err)
+			continue
+		}
+		t.updateGridLevel(i, func(l *GridLevel) { l.OrderID = orderID })
+		t.log("Grid #%d: ordem de %s colocada a $%.2f (ID %d)", level.Index, level.Side, orderPrice, orderID)
+	}
+}
+
+// pollGridOrders verifica as ordens que saíram do livro e trata as execuções
+func (t *BTCTrader) pollGridOrders() {
+	openOrders, err := t.exchange.OpenOrders(context.Background())
+	if err != nil {
+		t.log("Erro ao listar ordens abertas do grid: %v", err)
+		return
+	}
+	open := make(map[int64]bool, len(openOrders))
+	for _, order := range openOrders {
+		open[order.OrderID] = true
+	}
+
+	for i, level := range t.grid.levels {
+		if level.OrderID == 0 || open[level.OrderID] {
+			continue
+		}
+
+		fill, status, err := t.queryExecution(level.OrderID)
+		if err != nil {
+			t.log("%v", err)
+			continue
+		}
+		if orderOpen(status) {
+			continue
+		}
+		if fill == nil {
+			// Ordem cancelada/expirada/rejeitada: será recolocada no próximo ciclo
+			t.logWarn("⚠️ Ordem do grid #%d encerrada sem execução (%s)", level.Index, status)
+			t.updateGridLevel(i, func(l *GridLevel) { l.OrderID = 0 })
+			continue
+		}
+
+		t.handleGridFill(i, fill)
+	}
+}
+
+// orderOpen indica se a ordem ainda está no livro
+func orderOpen(status binance.OrderStatusType) bool {
+	return status == binance.OrderStatusTypeNew || status == binance.OrderStatusTypePartiallyFilled
+}
+
+// handleGridFill registra a execução de uma ordem encerrada do grid, completa ou
+// parcial (cancelada após executar parte), e define a próxima ordem do grid. Após
+// uma compra o grid vende a quantidade comprada; após uma venda parcial continua
+// vendendo o restante.
+func (t *BTCTrader) handleGridFill(i int, fill *OrderFill) {
+	level := t.grid.levels[i]
+
+	if fill.Side == binance.SideTypeBuy {
+		trade := t.recordFill(fill, 0, tradeSignal{source: SourceGrid})
+		t.notifyTrade(notify.EventEntry, trade, map[string]any{"grid": level.Index})
+		t.updateGridLevel(i, func(l *GridLevel) {
+			l.OrderID = 0
+			l.Side = "sell"
+			l.EntryPrice = fill.Price
+			l.Quantity = fill.Quantity
+		})
+		t.logImportant("🔲 Grid #%d: compra de %.5f BTC executada a $%.2f, vendendo a $%.2f",
+			level.Index, fill.Quantity, fill.Price, level.SellPrice)
+		return
+	}
+
+	fees := (level.EntryPrice + fill.Price) * fill.Quantity * t.takerFee
+	profit := (fill.Price-level.EntryPrice)*fill.Quantity - fees
+	profitPct := 0.0
+	if level.EntryPrice > 0 {
+		profitPct = (fill.Price - level.EntryPrice) / level.EntryPrice * 100
+	}
+	trade := t.recordFill(fill, profitPct, tradeSignal{source: SourceGrid})
+	t.notifyTrade(notify.EventExit, trade, map[string]any{"grid": level.Index, "profit": profit})
+
+	remaining := math.Round((level.Quantity-fill.Quantity)*100000) / 100000
+	if remaining > 0 {
+		t.updateGridLevel(i, func(l *GridLevel) {
+			l.OrderID = 0
+			l.Quantity = remaining
+			l.Profit += profit
+		})
+		t.logImportant("🔲 Grid #%d: venda parcial de %.5f BTC a $%.2f, lucro $%.4f, restam %.5f BTC a vender",
+			level.Index, fill.Quantity, fill.Price, profit, remaining)
+		return
+	}
+	t.updateGridLevel(i, func(l *GridLevel) {
+		l.OrderID = 0
+		l.Side = "buy"
+		l.Quantity = t.gridLevelQuantity(l.BuyPrice)
+		l.Profit += profit
+		l.Trips++
+	})
+	t.logImportant("🔲 Grid #%d: venda executada a $%.2f, lucro $%.4f, recomprando a $%.2f",
+		level.Index, fill.Price, profit, level.BuyPrice)
+}
+
+// cancelGridOrders cancela todas as ordens em aberto do grid. O que foi executado
+// antes do cancelamento é contabilizado; nenhuma ordem nova é colocada, o grid é
+// recolocado por placeGridOrders na retomada. Uma ordem que não pôde ser cancelada
+// continua registrada e é conferida pelas consultas do grid.
+func (t *BTCTrader) cancelGridOrders() {
+	for i, level := range t.grid.levels {
+		if level.OrderID == 0 {
+			continue
+		}
+
+		// Falha também quando a ordem já foi executada; o estado final decide
+		if err := t.cancelOrder(level.OrderID); err != nil {
+			t.log("%v", err)
+		}
+		fill, status, err := t.queryExecution(level.OrderID)
+		if err != nil {
+			t.logWarn("⚠️ Ordem do grid #%d (ID %d) em estado desconhecido após o cancelamento: %v", level.Index, level.OrderID, err)
+			continue
+		}
+		if orderOpen(status) {
+			t.logWarn("⚠️ Ordem do grid #%d (ID %d) continua aberta (%s)", level.Index, level.OrderID, status)
+			continue
+		}
+		if fill != nil {
+			t.handleGridFill(i, fill)
+			continue
+		}
+		t.updateGridLevel(i, func(l *GridLevel) { l.OrderID = 0 })
+	}
+}
@@ -0,0 +1,51 @@
+package traderbot
+
+// movingAverage calcula a média simples dos últimos period valores
+func movingAverage(values []float64, period int) float64 {
+	if period <= 0 || len(values) < period {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values[len(values)-period:] {
+		sum += v
+	}
+	return sum / float64(period)
+}
+
+// relativeStrengthIndex calcula o RSI dos últimos period fechamentos, retornando
+// também a soma dos ganhos e das perdas. Retorna 50 (neutro) sem dados suficientes.
+func relativeStrengthIndex(closes []float64, period int) (rsi, gains, losses float64) {
+	if period <= 0 || len(closes) <= period {
+		return 50.0, 0, 0
+	}
+
+	for i := 1; i < period+1; i++ {
+		change := closes[len(closes)-i] - closes[len(closes)-i-1]
+		if change >= 0 {
+			gains += change
+		} else {
+			losses -= change
+		}
+	}
+
+	if losses == 0 {
+		return 100.0, gains, losses
+	}
+
+	rs := gains / losses
+	return 100.0 - (100.0 / (1.0 + rs)), gains, losses
+}
+
+// exponentialMovingAverage calcula a média exponencial, iniciada pela média simples
+// dos primeiros period valores
+func exponentialMovingAverage(values []float64, period int) float64 {
+	if period <= 0 || len(values) < period {
+		return 0
+	}
+	ema := movingAverage(values[:period], period)
+	k := 2.0 / float64(period+1)
+	for _, v := range values[period:] {
+		ema = v*k + ema*(1-k)
+	}
+	return ema
+}
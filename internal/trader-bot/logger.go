@@ -1,63 +1,162 @@
 package traderbot
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
+	"unicode"
+
+	"github.com/casarotto/binance-bot/internal/config"
 )
 
+// Field é um par chave-valor anexado a uma entrada de log
+type Field struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
 type LogEntry struct {
-	Timestamp time.Time
-	Message   string
+	Timestamp time.Time  `json:"timestamp"`
+	Level     slog.Level `json:"level"`
+	Message   string     `json:"message"`
+	Fields    []Field    `json:"fields,omitempty"`
 }
 
+// String retorna a mensagem seguida dos campos no formato chave=valor
+func (e LogEntry) String() string {
+	if len(e.Fields) == 0 {
+		return e.Message
+	}
+	var b strings.Builder
+	b.WriteString(e.Message)
+	for _, f := range e.Fields {
+		fmt.Fprintf(&b, " %s=%s", f.Key, f.Value)
+	}
+	return b.String()
+}
+
+// Logger é o logger estruturado do bot, com níveis (debug, info, warn, error).
+// As entradas vão para o arquivo com rotação, opcionalmente para o stdout, e as
+// que atingem o nível configurado ficam num buffer em memória lido pela TUI e pela API.
 type Logger struct {
-	*log.Logger
-	file      *os.File
-	logs      []LogEntry
-	maxLogs   int
-	logsLock  sync.RWMutex
+	file     *rotatingFile
+	slog     *slog.Logger
+	stdout   *slog.Logger // saída estruturada (JSON) no modo headless
+	level    slog.Level
+	logs     []LogEntry
+	maxLogs  int
+	logsLock sync.RWMutex
 }
 
-func NewLogger(historyDir string) (*Logger, error) {
+func NewLogger(historyDir string, cfg config.LogConfig) (*Logger, error) {
 	if err := os.MkdirAll(historyDir, 0755); err != nil {
 		return nil, fmt.Errorf("erro ao criar diretório de logs: %v", err)
 	}
 
-	logFile := filepath.Join(historyDir, "bot.log")
-	file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	file, err := newRotatingFile(
+		filepath.Join(historyDir, "bot.log"),
+		int64(cfg.MaxSizeMB)*1024*1024,
+		cfg.RotateEvery,
+		cfg.MaxBackups,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao criar arquivo de log: %v", err)
+		return nil, err
 	}
 
+	level := parseLevel(cfg.Level)
 	return &Logger{
-		Logger:   log.New(file, "", log.LstdFlags),
-		file:     file,
-		logs:     make([]LogEntry, 0),
-		maxLogs:  100, // manter apenas os últimos 100 logs
+		file:    file,
+		slog:    slog.New(newHandler(file, cfg.Format, level)),
+		level:   level,
+		logs:    make([]LogEntry, 0),
+		maxLogs: 500, // manter apenas os últimos 500 logs
 	}, nil
 }
 
+func newHandler(w io.Writer, format string, level slog.Level) slog.Handler {
+	opts := &slog.HandlerOptions{Level: level}
+	if format == "json" {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
+
+// parseLevel converte debug/info/warn/error; valores desconhecidos viram info
+func parseLevel(name string) slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// EnableStdout passa a escrever as entradas também no stdout, uma linha JSON por
+// entrada, para systemd e containers sem terminal
+func (l *Logger) EnableStdout() {
+	l.stdout = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: l.level}))
+}
+
 func (l *Logger) Close() error {
 	return l.file.Close()
 }
 
-// LogImportant registra apenas mensagens importantes
-func (l *Logger) LogImportant(format string, v ...interface{}) {
-	msg := fmt.Sprintf(format, v...)
-	l.Logger.Printf(msg) // Salvar no arquivo
+// Debug, Info, Warn e Error registram uma mensagem com campos chave-valor, como no slog
+func (l *Logger) Debug(msg string, args ...any) { l.log(slog.LevelDebug, msg, args...) }
+func (l *Logger) Info(msg string, args ...any)  { l.log(slog.LevelInfo, msg, args...) }
+func (l *Logger) Warn(msg string, args ...any)  { l.log(slog.LevelWarn, msg, args...) }
+func (l *Logger) Error(msg string, args ...any) { l.log(slog.LevelError, msg, args...) }
+
+// Debugf, Infof, Warnf e Errorf registram uma mensagem formatada sem campos
+func (l *Logger) Debugf(format string, v ...any) { l.logf(slog.LevelDebug, format, v...) }
+func (l *Logger) Infof(format string, v ...any)  { l.logf(slog.LevelInfo, format, v...) }
+func (l *Logger) Warnf(format string, v ...any)  { l.logf(slog.LevelWarn, format, v...) }
+func (l *Logger) Errorf(format string, v ...any) { l.logf(slog.LevelError, format, v...) }
+
+// Fatal registra um erro e encerra o processo
+func (l *Logger) Fatal(msg string, args ...any) {
+	l.Error(msg, args...)
+	l.Close()
+	os.Exit(1)
+}
+
+func (l *Logger) logf(level slog.Level, format string, v ...any) {
+	if level < l.level {
+		return
+	}
+	l.log(level, fmt.Sprintf(format, v...))
+}
+
+func (l *Logger) log(level slog.Level, msg string, args ...any) {
+	if level < l.level {
+		return
+	}
+
+	// Emojis ficam só na TUI; arquivo e stdout recebem o texto limpo
+	plain := stripEmoji(msg)
+	l.slog.Log(context.Background(), level, plain, args...)
+	if l.stdout != nil {
+		l.stdout.Log(context.Background(), level, plain, args...)
+	}
+
+	entry := LogEntry{Timestamp: time.Now(), Level: level, Message: msg}
+	record := slog.NewRecord(entry.Timestamp, level, msg, 0)
+	record.Add(args...)
+	record.Attrs(func(a slog.Attr) bool {
+		entry.Fields = append(entry.Fields, Field{Key: a.Key, Value: a.Value.String()})
+		return true
+	})
 
 	l.logsLock.Lock()
 	defer l.logsLock.Unlock()
 
 	// Adicionar ao buffer circular
-	l.logs = append(l.logs, LogEntry{
-		Timestamp: time.Now(),
-		Message:   msg,
-	})
+	l.logs = append(l.logs, entry)
 
 	// Manter apenas os últimos maxLogs
 	if len(l.logs) > l.maxLogs {
@@ -65,10 +164,25 @@ func (l *Logger) LogImportant(format string, v ...interface{}) {
 	}
 }
 
-// GetRecentLogs retorna os logs mais recentes
-func (l *Logger) GetRecentLogs() []LogEntry {
+// stripEmoji remove emojis e símbolos do início da mensagem
+func stripEmoji(msg string) string {
+	return strings.TrimLeftFunc(msg, func(r rune) bool {
+		return unicode.Is(unicode.So, r) || unicode.Is(unicode.Sk, r) || unicode.IsSpace(r) ||
+			r == '\uFE0F' || r == '\u200D'
+	})
+}
+
+// GetRecentLogs retorna os logs mais recentes com nível igual ou acima de minLevel
+func (l *Logger) GetRecentLogs(minLevel slog.Level) []LogEntry {
 	l.logsLock.RLock()
 	defer l.logsLock.RUnlock()
-	
-	return l.logs
-} 
\ No newline at end of file
+
+	// Cópia para que o chamador não compartilhe o buffer com quem escreve
+	logs := make([]LogEntry, 0, len(l.logs))
+	for _, entry := range l.logs {
+		if entry.Level >= minLevel {
+			logs = append(logs, entry)
+		}
+	}
+	return logs
+}
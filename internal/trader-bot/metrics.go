@@ -0,0 +1,135 @@
+package traderbot
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/adshao/go-binance/v2/common"
+	"github.com/casarotto/binance-bot/internal/metrics"
+)
+
+// traderMetrics reúne as métricas exportadas no /metrics. Os gauges de estado são
+// atualizados a partir de um único Snapshot no momento da coleta.
+type traderMetrics struct {
+	registry *metrics.Registry
+
+	price         *metrics.Gauge
+	rsi           *metrics.Gauge
+	maShort       *metrics.Gauge
+	maLong        *metrics.Gauge
+	inPosition    *metrics.Gauge
+	unrealizedPnL *metrics.Gauge
+	balance       *metrics.GaugeVec
+	signalsPaused *metrics.Gauge
+
+	ordersPlaced *metrics.CounterVec
+	ordersFailed *metrics.CounterVec
+	wsReconnects *metrics.Counter
+	eventLag     *metrics.Gauge
+	usedWeight   *metrics.Gauge
+	orderCount   *metrics.Gauge
+	restBackoff  *metrics.Gauge
+	throttled    *metrics.CounterVec
+	orderRetries *metrics.CounterVec
+	apiErrors    *metrics.CounterVec
+	circuitOpen  *metrics.Gauge
+	clockOffset  *metrics.Gauge
+	clockDrift   *metrics.Gauge
+}
+
+func newTraderMetrics(t *BTCTrader) *traderMetrics {
+	r := metrics.NewRegistry()
+	m := &traderMetrics{
+		registry:      r,
+		price:         r.NewGauge("binance_bot_price", "Último preço de fechamento do BTCUSDT"),
+		rsi:           r.NewGauge("binance_bot_rsi", "RSI atual no intervalo dos sinais"),
+		maShort:       r.NewGauge("binance_bot_ma_short", "Média móvel curta"),
+		maLong:        r.NewGauge("binance_bot_ma_long", "Média móvel longa"),
+		inPosition:    r.NewGauge("binance_bot_in_position", "1 se há posição aberta"),
+		unrealizedPnL: r.NewGauge("binance_bot_unrealized_pnl_percent", "Lucro/prejuízo não realizado da posição em %"),
+		balance:       r.NewGaugeVec("binance_bot_balance", "Saldo livre por ativo", "asset"),
+		signalsPaused: r.NewGauge("binance_bot_signals_paused", "1 se a avaliação de sinais está pausada"),
+		ordersPlaced:  r.NewCounterVec("binance_bot_orders_placed_total", "Ordens aceitas pela corretora", "side", "type"),
+		ordersFailed:  r.NewCounterVec("binance_bot_orders_failed_total", "Ordens recusadas ou com erro", "side", "type", "reason"),
+		wsReconnects:  r.NewCounter("binance_bot_ws_reconnects_total", "Reconexões dos WebSockets feitas pelo supervisor"),
+		eventLag:      r.NewGauge("binance_bot_event_lag_seconds", "Atraso do último kline (agora menos o horário do evento)"),
+		usedWeight:    r.NewGauge("binance_bot_rest_used_weight_1m", "Peso de requisições REST usado no último minuto (X-MBX-USED-WEIGHT-1M)"),
+		orderCount:    r.NewGauge("binance_bot_rest_order_count_10s", "Ordens enviadas nos últimos 10 segundos (X-MBX-ORDER-COUNT-10S)"),
+		restBackoff:   r.NewGauge("binance_bot_rest_backoff_seconds", "Tempo restante da espera pedida pela Binance após um 429/418"),
+		throttled:     r.NewCounterVec("binance_bot_rest_throttled_total", "Requisições atrasadas (wait) ou descartadas (shed) pelo limitador", "priority", "action"),
+		orderRetries:  r.NewCounterVec("binance_bot_order_retries_total", "Ordens sem resposta definitiva: encontradas pelo ID, reenviadas ou sem solução", "result"),
+		apiErrors:     r.NewCounterVec("binance_bot_order_errors_total", "Falhas no envio de ordens por categoria do erro", "category"),
+		circuitOpen:   r.NewGauge("binance_bot_circuit_open", "1 se o circuit breaker está aberto (compras suspensas)"),
+		clockOffset:   r.NewGauge("binance_bot_clock_offset_seconds", "Relógio local menos o horário do servidor da Binance"),
+		clockDrift:    r.NewGauge("binance_bot_clock_drift", "1 se a diferença para o servidor passa de MAX_CLOCK_DRIFT"),
+	}
+
+	r.OnCollect(func() {
+		status := t.Snapshot()
+		m.price.Set(status.Price)
+		m.rsi.Set(status.RSI)
+		m.maShort.Set(status.MAShort)
+		m.maLong.Set(status.MALong)
+		m.inPosition.Set(boolToFloat(status.InPosition))
+		m.unrealizedPnL.Set(status.PnLPct)
+		m.signalsPaused.Set(boolToFloat(status.SignalsPaused))
+		m.circuitOpen.Set(boolToFloat(status.Breaker.Open))
+		if status.BalanceError == "" {
+			m.balance.Set(status.BTCBalance, "BTC")
+			m.balance.Set(status.USDTBalance, "USDT")
+		}
+		if t.limiter != nil {
+			weight, orders, backoff := t.limiter.status()
+			m.usedWeight.Set(float64(weight))
+			m.orderCount.Set(float64(orders))
+			m.restBackoff.Set(backoff.Seconds())
+		}
+	})
+	return m
+}
+
+// GetMetrics retorna o registro das métricas do trader, servido em /metrics
+func (t *BTCTrader) GetMetrics() *metrics.Registry {
+	return t.metrics.registry
+}
+
+// recordOrder contabiliza o resultado do envio de uma ordem
+func (m *traderMetrics) recordOrder(side, orderType string, err error) {
+	if err != nil {
+		m.ordersFailed.Inc(side, orderType, orderFailureReason(err))
+		return
+	}
+	m.ordersPlaced.Inc(side, orderType)
+}
+
+// orderFailureReason resume o erro para uso como label (cardinalidade baixa)
+func orderFailureReason(err error) string {
+	var apiErr *common.APIError
+	if errors.As(err, &apiErr) {
+		return fmt.Sprintf("api_%d", apiErr.Code)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return "timeout"
+		}
+		return "network"
+	}
+	return "other"
+}
+
+// recordEventTime atualiza o atraso entre o horário do evento (ms) e agora
+func (m *traderMetrics) recordEventTime(eventTime int64) {
+	if eventTime > 0 {
+		m.eventLag.Set(time.Since(time.UnixMilli(eventTime)).Seconds())
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,217 @@
+package traderbot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/casarotto/binance-bot/internal/config"
+)
+
+// TimeframeStatus resume o estado de um timeframe de confirmação para a TUI
+type TimeframeStatus struct {
+	Interval string
+	Close    float64
+	Candles  int
+	Rules    []RuleStatus
+}
+
+// RuleStatus é o resultado da avaliação de uma regra de confirmação
+type RuleStatus struct {
+	Rule   string
+	Ready  bool // há candles suficientes para avaliar a regra
+	Passed bool
+	Detail string
+}
+
+// SetTimeframes configura o intervalo dos sinais e as regras de confirmação em timeframes maiores
+func (t *BTCTrader) SetTimeframes(signalInterval string, rules []config.TimeframeRule) {
+	t.tfMutex.Lock()
+	defer t.tfMutex.Unlock()
+
+	t.signalInterval = signalInterval
+	t.confirmations = rules
+	for _, rule := range rules {
+		// Guardar candles suficientes para a média atual e a anterior (inclinação)
+		t.ensureTimeframeLocked(rule.Interval, rule.Period+2)
+	}
+
+	if len(rules) > 0 {
+		t.logImportant("⏱️ Confirmação multi-timeframe ativa: %d regra(s) sobre sinais de %s", len(rules), signalInterval)
+	}
+}
+
+// ensureTimeframe garante uma série para o intervalo com pelo menos a capacidade informada.
+// Deve ser chamado antes de Start, quando os WebSockets são abertos.
+func (t *BTCTrader) ensureTimeframe(interval string, capacity int) {
+	t.tfMutex.Lock()
+	defer t.tfMutex.Unlock()
+	t.ensureTimeframeLocked(interval, capacity)
+}
+
+func (t *BTCTrader) ensureTimeframeLocked(interval string, capacity int) {
+	if capacity < 100 {
+		capacity = 100
+	}
+	series, ok := t.timeframes[interval]
+	if ok && series.Cap() >= capacity {
+		return
+	}
+	grown := NewCandleSeries(capacity)
+	if ok {
+		for _, candle := range series.Candles() {
+			grown.Add(candle)
+		}
+	}
+	t.timeframes[interval] = grown
+}
+
+// GetSignalInterval retorna o intervalo dos candles que geram os sinais
+func (t *BTCTrader) GetSignalInterval() string {
+	return t.signalInterval
+}
+
+// GetTimeframeStatus retorna o estado de cada timeframe de confirmação
+func (t *BTCTrader) GetTimeframeStatus() []TimeframeStatus {
+	t.tfMutex.RLock()
+	defer t.tfMutex.RUnlock()
+
+	var statuses []TimeframeStatus
+	seen := make(map[string]int)
+	for _, rule := range t.confirmations {
+		series := t.timeframes[rule.Interval]
+		idx, ok := seen[rule.Interval]
+		if !ok {
+			status := TimeframeStatus{Interval: rule.Interval, Candles: series.Len()}
+			if last, ok := series.Last(); ok {
+				status.Close = last.Close
+			}
+			statuses = append(statuses, status)
+			idx = len(statuses) - 1
+			seen[rule.Interval] = idx
+		}
+		statuses[idx].Rules = append(statuses[idx].Rules, evaluateTimeframeRule(series, rule))
+	}
+	return statuses
+}
+
+// higherTimeframesConfirm verifica se todas as regras de confirmação estão satisfeitas
+func (t *BTCTrader) higherTimeframesConfirm() bool {
+	t.tfMutex.RLock()
+	defer t.tfMutex.RUnlock()
+
+	for _, rule := range t.confirmations {
+		status := evaluateTimeframeRule(t.timeframes[rule.Interval], rule)
+		if !status.Passed {
+			t.log("Confirmação %s não satisfeita: %s", status.Rule, status.Detail)
+			return false
+		}
+	}
+	return true
+}
+
+// evaluateTimeframeRule avalia uma regra sobre a série do seu timeframe
+func evaluateTimeframeRule(series *CandleSeries, rule config.TimeframeRule) RuleStatus {
+	status := RuleStatus{Rule: rule.String()}
+	closes := series.Closes()
+
+	needed := rule.Period
+	if rule.Kind == "ma_slope_up" || rule.Kind == "ma_slope_down" {
+		needed = rule.Period + 1
+	}
+	if len(closes) < needed {
+		status.Detail = fmt.Sprintf("aguardando candles (%d/%d)", len(closes), needed)
+		return status
+	}
+	status.Ready = true
+
+	ma := movingAverage(closes, rule.Period)
+	switch rule.Kind {
+	case "ma_slope_up", "ma_slope_down":
+		prev := movingAverage(closes[:len(closes)-1], rule.Period)
+		if rule.Kind == "ma_slope_up" {
+			status.Passed = ma > prev
+		} else {
+			status.Passed = ma < prev
+		}
+		status.Detail = fmt.Sprintf("MA%d %.2f → %.2f", rule.Period, prev, ma)
+	case "close_above_ma", "close_below_ma":
+		last := closes[len(closes)-1]
+		if rule.Kind == "close_above_ma" {
+			status.Passed = last > ma
+		} else {
+			status.Passed = last < ma
+		}
+		status.Detail = fmt.Sprintf("fechamento %.2f, MA%d %.2f", last, rule.Period, ma)
+	}
+	return status
+}
+
+// updateTimeframe aplica um candle à série do seu intervalo
+func (t *BTCTrader) updateTimeframe(interval string, candle Candle) {
+	t.tfMutex.Lock()
+	defer t.tfMutex.Unlock()
+	if series, ok := t.timeframes[interval]; ok {
+		series.Add(candle)
+	}
+}
+
+// backfillTimeframes carrega o histórico recente de cada timeframe de confirmação via REST
+func (t *BTCTrader) backfillTimeframes() {
+	t.tfMutex.RLock()
+	capacity := make(map[string]int, len(t.timeframes))
+	for interval, s := range t.timeframes {
+		capacity[interval] = s.Cap()
+	}
+	t.tfMutex.RUnlock()
+
+	for interval, limit := range capacity {
+		klines, err := t.exchange.Klines(context.Background(), interval, limit)
+		if err != nil {
+			t.logWarn("⚠️ Não foi possível carregar o histórico de %s: %v", interval, err)
+			continue
+		}
+		if t.recorder != nil {
+			t.recordEvent(StreamKlineHistory, RecordedKlines{Interval: interval, Klines: klines})
+		}
+		for _, k := range klines {
+			candle, err := CandleFromKline(k)
+			if err != nil {
+				continue
+			}
+			t.updateTimeframe(interval, candle)
+		}
+		t.log("Histórico de %s carregado: %d candles", interval, len(klines))
+	}
+}
+
+// startTimeframeStreams assina os klines dos timeframes de confirmação que não são o dos sinais
+func (t *BTCTrader) startTimeframeStreams(errHandler binance.ErrHandler) ([]wsStream, error) {
+	t.backfillTimeframes()
+
+	t.tfMutex.RLock()
+	intervals := make([]string, 0, len(t.timeframes))
+	for interval := range t.timeframes {
+		if interval != t.signalInterval {
+			intervals = append(intervals, interval)
+		}
+	}
+	t.tfMutex.RUnlock()
+
+	var streams []wsStream
+	for _, interval := range intervals {
+		handler := func(event *binance.WsKlineEvent) {
+			candle, err := CandleFromWsKline(event.Kline)
+			if err != nil {
+				return
+			}
+			t.updateTimeframe(event.Kline.Interval, candle)
+		}
+		doneC, stopC, err := t.klineStream(interval, handler, errHandler)
+		if err != nil {
+			return streams, fmt.Errorf("erro ao iniciar WebSocket de %s: %v", interval, err)
+		}
+		streams = append(streams, wsStream{interval: interval, done: doneC, stop: stopC})
+	}
+	return streams, nil
+}
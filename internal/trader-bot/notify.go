@@ -0,0 +1,40 @@
+package traderbot
+
+import (
+	"github.com/casarotto/binance-bot/internal/notify"
+)
+
+// SetNotifier define o notificador usado para avisar entradas, saídas, stops e erros
+func (t *BTCTrader) SetNotifier(n *notify.Notifier) {
+	t.notifier = n
+}
+
+// notify envia um aviso se houver notificador configurado
+func (t *BTCTrader) notify(event notify.Event, fields map[string]any) {
+	if t.notifier != nil {
+		t.notifier.Notify(event, fields)
+	}
+}
+
+// notifyTrade envia o aviso de uma operação registrada no histórico
+func (t *BTCTrader) notifyTrade(event notify.Event, trade Trade, extra map[string]any) {
+	fields := map[string]any{
+		"action":     trade.Action,
+		"price":      trade.Price,
+		"quantity":   trade.Quantity,
+		"profit_pct": trade.ProfitLoss,
+		"source":     trade.Source,
+	}
+	for k, v := range extra {
+		fields[k] = v
+	}
+	t.notify(event, fields)
+}
+
+// notifyError envia o aviso de um erro ao operar
+func (t *BTCTrader) notifyError(context string, err error) {
+	t.notify(notify.EventError, map[string]any{
+		"context": context,
+		"error":   context + ": " + err.Error(),
+	})
+}
@@ -0,0 +1,227 @@
+package traderbot
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/casarotto/binance-bot/internal/config"
+	"github.com/casarotto/binance-bot/internal/rules"
+)
+
+// OrderFlowStats resume o fluxo de ordens (negócios agregados) em uma janela
+type OrderFlowStats struct {
+	Window      time.Duration `json:"window"`
+	BuyVolume   float64       `json:"buy_volume"`  // BTC negociados com o comprador como agressor
+	SellVolume  float64       `json:"sell_volume"` // BTC negociados com o vendedor como agressor
+	Delta       float64       `json:"delta"`       // BuyVolume - SellVolume
+	CVD         float64       `json:"cvd"`         // delta acumulado desde o início do bot
+	Trades      int           `json:"trades"`
+	Intensity   float64       `json:"intensity"`    // negócios por segundo
+	LargeTrades int           `json:"large_trades"` // negócios com quantidade >= ORDERFLOW_LARGE_TRADE
+	LargeDelta  float64       `json:"large_delta"`  // delta só dos negócios grandes
+	Ready       bool          `json:"ready"`        // o stream cobre a janela inteira
+}
+
+// OrderFlowStatus é o estado do fluxo de ordens exibido na TUI
+type OrderFlowStatus struct {
+	Enabled bool `json:"enabled"`
+	Live    bool `json:"live"` // o stream de aggTrade está assinado
+	OrderFlowStats
+}
+
+// flowTrade é um negócio agregado guardado para as janelas
+type flowTrade struct {
+	time     int64 // milissegundos
+	quantity float64
+	buy      bool // o agressor foi o comprador
+}
+
+// orderFlow acumula os negócios agregados recentes
+type orderFlow struct {
+	mu         sync.Mutex
+	trades     []flowTrade // em ordem de chegada, até retention atrás do último
+	retention  time.Duration
+	largeTrade float64
+	cvd        float64
+	since      int64 // início da assinatura atual (ms)
+}
+
+func newOrderFlow(retention time.Duration, largeTrade float64) *orderFlow {
+	return &orderFlow{retention: retention, largeTrade: largeTrade}
+}
+
+// retain aumenta a janela de negócios guardados
+func (f *orderFlow) retain(window time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.retention = max(f.retention, window)
+}
+
+// reset marca o início de uma nova assinatura. Os negócios da anterior são
+// descartados, pois as janelas teriam um buraco; o CVD continua acumulando.
+func (f *orderFlow) reset(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.trades = nil
+	f.since = now.UnixMilli()
+}
+
+func (f *orderFlow) add(event *binance.WsAggTradeEvent) {
+	quantity, err := strconv.ParseFloat(event.Quantity, 64)
+	if err != nil || quantity <= 0 {
+		return
+	}
+	trade := flowTrade{time: event.TradeTime, quantity: quantity, buy: !event.IsBuyerMaker}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if trade.buy {
+		f.cvd += quantity
+	} else {
+		f.cvd -= quantity
+	}
+	f.trades = append(f.trades, trade)
+
+	cutoff := trade.time - f.retention.Milliseconds()
+	i := 0
+	for i < len(f.trades) && f.trades[i].time < cutoff {
+		i++
+	}
+	f.trades = f.trades[i:]
+}
+
+// stats calcula os indicadores dos negócios em (now - window, now]
+func (f *orderFlow) stats(window time.Duration, now time.Time) OrderFlowStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s := OrderFlowStats{Window: window, CVD: f.cvd}
+	end := now.UnixMilli()
+	start := end - window.Milliseconds()
+	s.Ready = f.since > 0 && f.since <= start
+
+	for i := len(f.trades) - 1; i >= 0 && f.trades[i].time > start; i-- {
+		trade := f.trades[i]
+		if trade.time > end {
+			continue
+		}
+		signed := trade.quantity
+		if trade.buy {
+			s.BuyVolume += trade.quantity
+		} else {
+			s.SellVolume += trade.quantity
+			signed = -signed
+		}
+		s.Trades++
+		if trade.quantity >= f.largeTrade {
+			s.LargeTrades++
+			s.LargeDelta += signed
+		}
+	}
+	s.Delta = s.BuyVolume - s.SellVolume
+	s.Intensity = float64(s.Trades) / window.Seconds()
+	return s
+}
+
+// SetOrderFlowConfig configura os indicadores de fluxo de ordens. Com Enabled o
+// stream de aggTrade é assinado em Start. Deve ser chamado antes de Start.
+func (t *BTCTrader) SetOrderFlowConfig(cfg config.OrderFlowConfig) {
+	t.flowConfig = cfg
+	if !cfg.Enabled {
+		return
+	}
+	t.ensureOrderFlow(cfg.Window)
+	if cfg.RequireDelta {
+		t.logImportant("🌊 Fluxo de ordens ativo - compras exigem delta positivo em %s", cfg.Window)
+	} else {
+		t.logImportant("🌊 Fluxo de ordens ativo - janela de %s", cfg.Window)
+	}
+}
+
+// ensureOrderFlow habilita o acúmulo de negócios guardando pelo menos a janela informada
+func (t *BTCTrader) ensureOrderFlow(window time.Duration) {
+	if t.flow == nil {
+		t.flow = newOrderFlow(window, t.flowConfig.LargeTrade)
+		return
+	}
+	t.flow.retain(window)
+}
+
+// startOrderFlowStream assina os negócios agregados quando o fluxo de ordens é usado.
+// Sem o stream (ex. backtest com candles) os indicadores ficam indisponíveis e a
+// exigência de delta positivo é ignorada.
+func (t *BTCTrader) startOrderFlowStream(errHandler binance.ErrHandler) []wsStream {
+	if t.flow == nil {
+		return nil
+	}
+	t.flow.reset(t.now())
+	doneC, stopC, err := t.exchange.AggTradeStream(t.flow.add, errHandler)
+	if err != nil {
+		t.flowLive.Store(false)
+		t.logWarn("⚠️ Negócios agregados indisponíveis, fluxo de ordens desligado: %v", err)
+		return nil
+	}
+	t.flowLive.Store(true)
+	return []wsStream{{interval: "aggTrade", done: doneC, stop: stopC}}
+}
+
+// orderFlowStats retorna os indicadores da janela; ok é false sem o stream
+func (t *BTCTrader) orderFlowStats(window time.Duration) (OrderFlowStats, bool) {
+	if t.flow == nil || !t.flowLive.Load() {
+		return OrderFlowStats{}, false
+	}
+	return t.flow.stats(window, t.now()), true
+}
+
+// orderFlowConfirms verifica a exigência de delta positivo para as compras
+func (t *BTCTrader) orderFlowConfirms() bool {
+	if !t.flowConfig.RequireDelta {
+		return true
+	}
+	stats, ok := t.orderFlowStats(t.flowConfig.Window)
+	if !ok {
+		return true
+	}
+	if !stats.Ready {
+		t.log("Sinal de compra bloqueado: fluxo de ordens ainda não cobre %s", stats.Window)
+		return false
+	}
+	if stats.Delta <= 0 {
+		t.log("Sinal de compra bloqueado pelo fluxo de ordens: delta de %.5f BTC em %s", stats.Delta, stats.Window)
+		return false
+	}
+	return true
+}
+
+// GetOrderFlow retorna os indicadores de fluxo de ordens na janela configurada
+func (t *BTCTrader) GetOrderFlow() OrderFlowStatus {
+	status := OrderFlowStatus{Enabled: t.flow != nil, Live: t.flowLive.Load()}
+	status.OrderFlowStats, _ = t.orderFlowStats(t.flowConfig.Window)
+	return status
+}
+
+// orderFlowValue calcula um indicador de fluxo de ordens usado nas regras
+func (t *BTCTrader) orderFlowValue(name string, window time.Duration) (float64, error) {
+	stats, ok := t.orderFlowStats(window)
+	if !ok {
+		return 0, fmt.Errorf("fluxo de ordens indisponível (sem o stream de aggTrade)")
+	}
+	if name == "cvd" {
+		return stats.CVD, nil
+	}
+	if !stats.Ready {
+		return 0, rules.ErrNotReady
+	}
+	switch name {
+	case "delta":
+		return stats.Delta, nil
+	case "trade_intensity":
+		return stats.Intensity, nil
+	case "large_trades":
+		return float64(stats.LargeTrades), nil
+	}
+	return 0, fmt.Errorf("função desconhecida %q", name)
+}
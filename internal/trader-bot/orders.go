@@ -0,0 +1,348 @@
+package traderbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/common"
+	"github.com/casarotto/binance-bot/internal/config"
+)
+
+// ErrOrderUnknown indica uma ordem cujo envio falhou sem resposta definitiva e que
+// não foi encontrada nem reenviada com segurança: ela pode ter sido executada
+var ErrOrderUnknown = errors.New("resultado da ordem desconhecido")
+
+// OrderFill representa a execução completa de uma ordem na corretora
+type OrderFill struct {
+	OrderID  int64
+	Side     binance.SideType
+	Price    float64 // preço médio de execução
+	Quantity float64 // quantidade executada
+}
+
+// Action retorna a ação ("buy"/"sell") usada no histórico de trades
+func (f *OrderFill) Action() string {
+	if f.Side == binance.SideTypeSell {
+		return "sell"
+	}
+	return "buy"
+}
+
+// placeMarketOrder envia uma ordem a mercado e retorna a execução. Uma ordem sem
+// quantidade executada é um erro; se a resposta não trouxer o valor executado, o
+// preço de referência é usado como preço médio.
+func (t *BTCTrader) placeMarketOrder(side binance.SideType, quantity, refPrice float64) (*OrderFill, error) {
+	req := OrderRequest{
+		Side:     side,
+		Type:     binance.OrderTypeMarket,
+		Quantity: quantity,
+	}
+	order, err := t.submitOrder(req)
+	if resized, ok := t.resizeOrder(req, refPrice, err); ok {
+		t.metrics.recordOrder(string(side), string(binance.OrderTypeMarket), err)
+		req = resized
+		order, err = t.submitOrder(req)
+	}
+	var executed float64
+	if err == nil {
+		executed, _ = strconv.ParseFloat(order.ExecutedQuantity, 64)
+		if executed <= 0 {
+			err = fmt.Errorf("ordem %d sem quantidade executada (%s)", order.OrderID, order.Status)
+		}
+	}
+	t.metrics.recordOrder(string(side), string(binance.OrderTypeMarket), err)
+	if err != nil {
+		return nil, err
+	}
+	t.log("Ordem: %+v", order)
+
+	fill := &OrderFill{
+		OrderID:  order.OrderID,
+		Side:     side,
+		Price:    refPrice,
+		Quantity: executed,
+	}
+	if quote, _ := strconv.ParseFloat(order.CummulativeQuoteQuantity, 64); quote > 0 {
+		fill.Price = quote / executed
+	}
+	return fill, nil
+}
+
+// placeLimitOrder envia uma ordem limitada (GTC) e retorna o ID da ordem
+func (t *BTCTrader) placeLimitOrder(side binance.SideType, quantity, price float64) (int64, error) {
+	order, err := t.submitOrder(OrderRequest{
+		Side:     side,
+		Type:     binance.OrderTypeLimit,
+		Quantity: quantity,
+		Price:    price,
+	})
+	t.metrics.recordOrder(string(side), string(binance.OrderTypeLimit), err)
+	if err != nil {
+		return 0, err
+	}
+	t.log("Ordem limitada: %+v", order)
+	return order.OrderID, nil
+}
+
+// resizeOrder ajusta uma ordem a mercado recusada por saldo insuficiente ou pela
+// quantidade (ActionResize): ao saldo livre atual, descontada a taxa nas compras, ou
+// ao passo do LOT_SIZE com os filtros consultados de novo. ok é false quando não há
+// um ajuste que a corretora aceitaria.
+func (t *BTCTrader) resizeOrder(req OrderRequest, price float64, err error) (OrderRequest, bool) {
+	if err == nil {
+		return req, false
+	}
+	classified := ClassifyError(err)
+	if classified.Category.Action() != ActionResize {
+		return req, false
+	}
+
+	quantity := req.Quantity
+	if classified.Category == CategoryInsufficientBalance {
+		btc, usdt, err := t.getBalances(WithPriority(context.Background(), PriorityCritical))
+		if err != nil {
+			return req, false
+		}
+		if req.Side == binance.SideTypeBuy {
+			quantity = min(quantity, usdt/(price*(1+t.takerFee)))
+		} else {
+			quantity = min(quantity, btc)
+		}
+	} else {
+		t.invalidateSymbolFilters()
+	}
+
+	filters, err := t.getSymbolFilters()
+	if err != nil {
+		return req, false
+	}
+	step := filters.stepSize
+	if step <= 0 {
+		step = 0.00001
+	}
+	quantity = math.Floor(quantity/step+1e-9) * step
+	if quantity <= 0 || quantity == req.Quantity {
+		return req, false
+	}
+	if err := t.checkFilters(quantity, price); err != nil {
+		t.log("Ordem não redimensionada: %v", err)
+		return req, false
+	}
+
+	t.logWarn("📐 Ordem de %s redimensionada de %.5f para %.5f BTC (%s)", req.Side, req.Quantity, quantity, classified.Category)
+	req.Quantity = quantity
+	return req, true
+}
+
+// SetOrderRetry define a política de novas tentativas das ordens com resultado desconhecido
+func (t *BTCTrader) SetOrderRetry(cfg config.OrderRetryConfig) {
+	t.retryConfig = cfg
+}
+
+// newClientOrderID gera o newClientOrderId de uma ordem a partir do relógio do trader
+// e de uma sequência, ex. "bb-B-1718900000000-3". O mesmo ID é usado em todas as
+// tentativas de envio, então a ordem é encontrada (e não duplicada) após uma falha.
+func (t *BTCTrader) newClientOrderID(side binance.SideType) string {
+	return fmt.Sprintf("bb-%c-%d-%d", side[0], t.now().UnixMilli(), t.orderSeq.Add(1))
+}
+
+// submitOrder envia a ordem se o circuit breaker permitir e retorna as falhas
+// classificadas (ExchangeError)
+func (t *BTCTrader) submitOrder(req OrderRequest) (*binance.CreateOrderResponse, error) {
+	if err := t.breaker.allow(req.Side, t.now()); err != nil {
+		return nil, err
+	}
+	response, err := t.sendOrder(req)
+	t.recordOrderResult(err)
+	if err != nil {
+		return nil, ClassifyError(err)
+	}
+	return response, nil
+}
+
+// sendOrder envia a ordem com um newClientOrderId. Recusas da API são definitivas e
+// retornadas na hora. Quando o resultado é desconhecido (timeout, erro de rede, 5xx)
+// a ordem é consultada pelo ID antes de cada reenvio; se continuar desconhecida
+// após as tentativas, retorna ErrOrderUnknown, que abre o circuit breaker para não
+// duplicar a operação.
+func (t *BTCTrader) sendOrder(req OrderRequest) (*binance.CreateOrderResponse, error) {
+	req.ClientOrderID = t.newClientOrderID(req.Side)
+	policy := t.retryConfig
+	backoff := policy.Backoff
+
+	// Os envios somados param em MaxWait, limitando o tempo com o tradeMutex travado;
+	// as consultas usam o próprio timeout, para que a última ainda confirme o resultado
+	sendCtx, cancelSend := context.WithTimeout(WithPriority(context.Background(), PriorityCritical), policy.MaxWait)
+	defer cancelSend()
+
+	var sendErr error
+	for attempt := 1; attempt <= policy.Attempts; attempt++ {
+		ctx, cancel := context.WithTimeout(sendCtx, policy.Timeout)
+		response, err := t.exchange.CreateOrder(ctx, req)
+		cancel()
+		if err == nil {
+			return response, nil
+		}
+		if !orderOutcomeUnknown(err) {
+			return nil, err
+		}
+		sendErr = err
+		t.logWarn("⚠️ Ordem %s sem resposta definitiva (tentativa %d de %d): %v", req.ClientOrderID, attempt, policy.Attempts, err)
+
+		// Dá tempo para a ordem aparecer na Binance e consulta pelo ID antes de reenviar
+		time.Sleep(backoff)
+		backoff *= 2
+		order, err := t.findClientOrder(req.ClientOrderID, policy.Timeout)
+		switch {
+		case err == nil:
+			t.metrics.orderRetries.Inc("found")
+			t.logImportant("🔎 Ordem %s encontrada na corretora (%s) após a falha no envio", req.ClientOrderID, order.Status)
+			if !orderAccepted(req, order) {
+				// Expirada, cancelada ou recusada sem execução: não reenvia nem conta como enviada
+				return nil, &ExchangeError{
+					Category: CategoryRejected,
+					Err:      fmt.Errorf("ordem %s encontrada com status %s e sem execução", req.ClientOrderID, order.Status),
+				}
+			}
+			return responseFromOrder(order), nil
+		case !orderNotFound(err):
+			// Sem confirmar que a ordem não existe, reenviar poderia duplicá-la
+			t.logError("❌ Não foi possível consultar a ordem %s: %v", req.ClientOrderID, err)
+			return nil, t.unresolvedOrder(req, sendErr)
+		}
+		if attempt == policy.Attempts {
+			break
+		}
+		if sendCtx.Err() != nil {
+			return nil, fmt.Errorf("ordem %s não enviada: tempo máximo de %s esgotado após %d tentativas: %w",
+				req.ClientOrderID, policy.MaxWait, attempt, sendErr)
+		}
+		t.metrics.orderRetries.Inc("resent")
+		t.log("Ordem %s não encontrada - reenviando", req.ClientOrderID)
+	}
+	// Nenhuma tentativa chegou à corretora: a ordem não existe
+	return nil, fmt.Errorf("ordem %s não enviada após %d tentativas: %w", req.ClientOrderID, policy.Attempts, sendErr)
+}
+
+// findClientOrder consulta uma ordem pelo newClientOrderId
+func (t *BTCTrader) findClientOrder(clientOrderID string, timeout time.Duration) (*binance.Order, error) {
+	ctx, cancel := context.WithTimeout(WithPriority(context.Background(), PriorityCritical), timeout)
+	defer cancel()
+	return t.exchange.GetOrderByClientID(ctx, clientOrderID)
+}
+
+// unresolvedOrder avisa o operador sobre uma ordem que pode ter sido executada
+func (t *BTCTrader) unresolvedOrder(req OrderRequest, sendErr error) error {
+	t.metrics.orderRetries.Inc("unresolved")
+	err := fmt.Errorf("%w: %s %s %.5f BTC (%v)", ErrOrderUnknown, req.ClientOrderID, req.Side, req.Quantity, sendErr)
+	t.logError("🚨 %v - confira a ordem na Binance antes de rearmar o circuit breaker", err)
+	return err
+}
+
+// orderOutcomeUnknown indica se a ordem pode ter chegado à corretora apesar do erro:
+// falhas de rede e timeouts, respostas 5xx sem código e os códigos em que a Binance
+// informa que o resultado é desconhecido (-1001, -1007). Recusas da API e do
+// limitador de requisições são definitivas.
+func orderOutcomeUnknown(err error) bool {
+	return ClassifyError(err).Category == CategoryNetwork
+}
+
+// orderAccepted indica se uma ordem encontrada após uma falha no envio conta como
+// enviada: executada, ao menos em parte, ou uma ordem limitada ainda no livro
+func orderAccepted(req OrderRequest, order *binance.Order) bool {
+	if executed, _ := strconv.ParseFloat(order.ExecutedQuantity, 64); executed > 0 {
+		return true
+	}
+	switch order.Status {
+	case binance.OrderStatusTypeFilled:
+		return true
+	case binance.OrderStatusTypeNew, binance.OrderStatusTypePartiallyFilled:
+		return req.Type == binance.OrderTypeLimit
+	}
+	return false
+}
+
+// orderNotFound indica a resposta da consulta para uma ordem inexistente (-2013)
+func orderNotFound(err error) bool {
+	var apiErr *common.APIError
+	return errors.As(err, &apiErr) && apiErr.Code == -2013
+}
+
+// responseFromOrder converte a consulta de uma ordem na resposta do envio
+func responseFromOrder(order *binance.Order) *binance.CreateOrderResponse {
+	return &binance.CreateOrderResponse{
+		Symbol:                   order.Symbol,
+		OrderID:                  order.OrderID,
+		ClientOrderID:            order.ClientOrderID,
+		TransactTime:             order.Time,
+		Price:                    order.Price,
+		OrigQuantity:             order.OrigQuantity,
+		ExecutedQuantity:         order.ExecutedQuantity,
+		CummulativeQuoteQuantity: order.CummulativeQuoteQuantity,
+		Status:                   order.Status,
+		TimeInForce:              order.TimeInForce,
+		Type:                     order.Type,
+		Side:                     order.Side,
+	}
+}
+
+// queryExecution consulta uma ordem e retorna o que já foi executado, mesmo que em
+// parte (nil sem execução)
+func (t *BTCTrader) queryExecution(orderID int64) (*OrderFill, binance.OrderStatusType, error) {
+	order, err := t.exchange.GetOrder(WithPriority(context.Background(), PriorityCritical), orderID)
+	if err != nil {
+		return nil, "", fmt.Errorf("erro ao consultar ordem %d: %v", orderID, err)
+	}
+
+	fill := &OrderFill{
+		OrderID: order.OrderID,
+		Side:    order.Side,
+	}
+	fill.Quantity, _ = strconv.ParseFloat(order.ExecutedQuantity, 64)
+	if fill.Quantity <= 0 {
+		return nil, order.Status, nil
+	}
+	quote, _ := strconv.ParseFloat(order.CummulativeQuoteQuantity, 64)
+	if quote > 0 {
+		fill.Price = quote / fill.Quantity
+	} else {
+		fill.Price, _ = strconv.ParseFloat(order.Price, 64)
+	}
+	return fill, order.Status, nil
+}
+
+// cancelOrder cancela uma ordem em aberto
+func (t *BTCTrader) cancelOrder(orderID int64) error {
+	if err := t.exchange.CancelOrder(WithPriority(context.Background(), PriorityCritical), orderID); err != nil {
+		return fmt.Errorf("erro ao cancelar ordem %d: %v", orderID, err)
+	}
+	return nil
+}
+
+// recordFill registra uma execução no histórico com os saldos atualizados e a origem
+func (t *BTCTrader) recordFill(fill *OrderFill, profitLoss float64, signal tradeSignal) Trade {
+	btcBalance, usdtBalance, err := t.getBalances(WithPriority(context.Background(), PriorityCritical))
+	if err != nil {
+		t.log("Aviso: Não foi possível obter saldos atualizados: %v", err)
+	}
+
+	trade := Trade{
+		Timestamp:   t.now().Unix(),
+		Action:      fill.Action(),
+		Price:       fill.Price,
+		Quantity:    fill.Quantity,
+		ProfitLoss:  profitLoss,
+		BTCBalance:  btcBalance,
+		USDTBalance: usdtBalance,
+		Source:      signal.source,
+		SignalID:    signal.id,
+	}
+	t.addTradeToHistory(trade)
+	t.log("Saldos após %s - BTC: %.8f, USDT: %.2f", trade.Action, btcBalance, usdtBalance)
+	return trade
+}
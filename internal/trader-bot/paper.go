@@ -0,0 +1,464 @@
+package traderbot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/common"
+	"github.com/casarotto/binance-bot/internal/config"
+)
+
+// PaperExchange é uma corretora simulada em memória. Ordens a mercado executam no
+// último preço recebido dos klines, ajustado pelo modelo de execução (deslizamento e
+// latência), e ordens limitadas quando o preço as cruza, ao preço limite. As taxas
+// são cobradas em USDT; o desconto em BNB só reduz o valor cobrado.
+type PaperExchange struct {
+	market MarketData
+	exec   config.PaperConfig
+	book   *OrderBook
+
+	mu         sync.Mutex
+	btc        float64 // saldos livres
+	usdt       float64
+	lockedBTC  float64 // reservados por ordens limitadas abertas
+	lockedUSDT float64
+	reserved   map[int64]float64 // USDT reservado por ordem limitada de compra
+	price      float64
+	ranges     map[string]candleRange // último candle de cada intervalo, para o deslizamento por volatilidade
+	now        func() time.Time
+	nextID     int64
+	orders     map[int64]*binance.Order
+	trades     []*binance.TradeV3
+}
+
+// candleRange é a amplitude relativa ((máxima - mínima) / fechamento) do último candle de um intervalo
+type candleRange struct {
+	duration time.Duration
+	value    float64
+}
+
+// pricePath é implementado pelas fontes de klines que conhecem os preços seguintes ao
+// relógio atual (backtests); a latência usa o preço do instante em que a ordem chega
+type pricePath interface {
+	PriceAt(t time.Time) (float64, bool)
+}
+
+// NewPaperExchange cria a corretora simulada com os saldos iniciais e o modelo de
+// execução. Os klines (e o preço das execuções) vêm de market: a Binance ao vivo,
+// uma gravação ou candles históricos.
+func NewPaperExchange(market MarketData, usdt, btc float64, exec config.PaperConfig) *PaperExchange {
+	return &PaperExchange{
+		market:   market,
+		exec:     exec,
+		book:     NewOrderBook(),
+		btc:      btc,
+		usdt:     usdt,
+		reserved: make(map[int64]float64),
+		ranges:   make(map[string]candleRange),
+		now:      time.Now,
+		nextID:   1,
+		orders:   make(map[int64]*binance.Order),
+	}
+}
+
+// SetClock define o relógio usado nos horários das ordens (o da gravação no replay)
+func (p *PaperExchange) SetClock(now func() time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.now = now
+}
+
+// SetPrice atualiza o último preço e executa as ordens limitadas cruzadas por ele
+func (p *PaperExchange) SetPrice(price float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.price = price
+	p.matchLimitOrders()
+}
+
+// TrackDepth assina o livro de ofertas de market só para o deslizamento "book",
+// quando o trader não assina o depth por conta própria
+func (p *PaperExchange) TrackDepth(errHandler binance.ErrHandler) error {
+	_, _, err := p.DepthStream(func(DepthUpdate) {}, errHandler)
+	return err
+}
+
+// Balances retorna os saldos totais (livres e reservados) e o último preço
+func (p *PaperExchange) Balances() (btc, usdt, price float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.btc + p.lockedBTC, p.usdt + p.lockedUSDT, p.price
+}
+
+func (p *PaperExchange) Klines(ctx context.Context, interval string, limit int) ([]*binance.Kline, error) {
+	return p.market.Klines(ctx, interval, limit)
+}
+
+// KlineStream repassa os klines de market atualizando o preço antes de cada evento
+func (p *PaperExchange) KlineStream(interval string, handler binance.WsKlineHandler, errHandler binance.ErrHandler) (chan struct{}, chan struct{}, error) {
+	duration, _ := IntervalDuration(interval)
+	return p.market.KlineStream(interval, func(event *binance.WsKlineEvent) {
+		if candle, err := CandleFromWsKline(event.Kline); err == nil && candle.Close > 0 {
+			p.mu.Lock()
+			p.ranges[interval] = candleRange{duration: duration, value: (candle.High - candle.Low) / candle.Close}
+			p.mu.Unlock()
+			p.SetPrice(candle.Close)
+		}
+		handler(event)
+	}, errHandler)
+}
+
+// DepthStream repassa o livro de ofertas de market, atualizando antes o livro usado
+// pelo deslizamento "book"
+func (p *PaperExchange) DepthStream(handler func(DepthUpdate), errHandler binance.ErrHandler) (chan struct{}, chan struct{}, error) {
+	return p.market.DepthStream(func(update DepthUpdate) {
+		p.book.Apply(update)
+		handler(update)
+	}, errHandler)
+}
+
+func (p *PaperExchange) AggTradeStream(handler binance.WsAggTradeHandler, errHandler binance.ErrHandler) (chan struct{}, chan struct{}, error) {
+	return p.market.AggTradeStream(handler, errHandler)
+}
+
+func (p *PaperExchange) Account(ctx context.Context) (*binance.Account, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return &binance.Account{
+		CanTrade:    true,
+		AccountType: "SPOT",
+		Balances: []binance.Balance{
+			{Asset: "BTC", Free: formatQty(p.btc), Locked: formatQty(p.lockedBTC)},
+			{Asset: "USDT", Free: formatQty(p.usdt), Locked: formatQty(p.lockedUSDT)},
+		},
+	}, nil
+}
+
+func (p *PaperExchange) CreateOrder(ctx context.Context, req OrderRequest) (*binance.CreateOrderResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if req.Quantity <= 0 {
+		return nil, &common.APIError{Code: -1013, Message: "Invalid quantity."}
+	}
+
+	// Como na Binance, o newClientOrderId não pode repetir o de uma ordem em aberto
+	clientOrderID := req.ClientOrderID
+	if clientOrderID == "" {
+		clientOrderID = fmt.Sprintf("paper-%d", p.nextID)
+	} else if existing := p.findClientOrder(clientOrderID); existing != nil && existing.Status == binance.OrderStatusTypeNew {
+		return nil, &common.APIError{Code: -2010, Message: "Duplicate order sent."}
+	}
+
+	order := &binance.Order{
+		Symbol:                   "BTCUSDT",
+		OrderID:                  p.nextID,
+		ClientOrderID:            clientOrderID,
+		OrigQuantity:             formatQty(req.Quantity),
+		ExecutedQuantity:         formatQty(0),
+		CummulativeQuoteQuantity: formatQty(0),
+		Status:                   binance.OrderStatusTypeNew,
+		Type:                     req.Type,
+		Side:                     req.Side,
+		Time:                     p.now().UnixMilli(),
+		UpdateTime:               p.now().UnixMilli(),
+		IsWorking:                true,
+	}
+
+	switch req.Type {
+	case binance.OrderTypeMarket:
+		if p.price <= 0 {
+			return nil, fmt.Errorf("corretora simulada sem preço de mercado")
+		}
+		if err := p.checkNotional(req.Quantity, p.price); err != nil {
+			return nil, err
+		}
+		price, at := p.marketFill(req.Side, req.Quantity)
+		if err := p.checkBalance(req.Side, req.Quantity, price); err != nil {
+			return nil, err
+		}
+		order.Price = formatQty(0)
+		p.fill(order, req.Quantity, price, false, at)
+	case binance.OrderTypeLimit:
+		if req.Price <= 0 {
+			return nil, &common.APIError{Code: -1013, Message: "Invalid price."}
+		}
+		if err := p.checkNotional(req.Quantity, req.Price); err != nil {
+			return nil, err
+		}
+		if err := p.checkBalance(req.Side, req.Quantity, req.Price); err != nil {
+			return nil, err
+		}
+		order.Price = strconv.FormatFloat(req.Price, 'f', 2, 64)
+		order.TimeInForce = binance.TimeInForceTypeGTC
+
+		// Uma ordem limitada que já cruza o preço executa na hora, como taker, ao preço de mercado
+		marketable := p.price > 0 && ((req.Side == binance.SideTypeBuy && p.price <= req.Price) ||
+			(req.Side == binance.SideTypeSell && p.price >= req.Price))
+		if marketable {
+			p.fill(order, req.Quantity, p.price, false, p.now())
+		} else if req.Side == binance.SideTypeBuy {
+			cost := req.Quantity * req.Price * (1 + p.exec.FeeRate(false))
+			p.usdt -= cost
+			p.lockedUSDT += cost
+			p.reserved[order.OrderID] = cost
+		} else {
+			p.btc -= req.Quantity
+			p.lockedBTC += req.Quantity
+		}
+	default:
+		return nil, &common.APIError{Code: -1116, Message: "Invalid orderType."}
+	}
+
+	p.nextID++
+	p.orders[order.OrderID] = order
+
+	return &binance.CreateOrderResponse{
+		Symbol:                   order.Symbol,
+		OrderID:                  order.OrderID,
+		ClientOrderID:            order.ClientOrderID,
+		TransactTime:             order.Time,
+		Price:                    order.Price,
+		OrigQuantity:             order.OrigQuantity,
+		ExecutedQuantity:         order.ExecutedQuantity,
+		CummulativeQuoteQuantity: order.CummulativeQuoteQuantity,
+		Status:                   order.Status,
+		TimeInForce:              order.TimeInForce,
+		Type:                     order.Type,
+		Side:                     order.Side,
+	}, nil
+}
+
+// marketFill calcula o preço e o instante de execução de uma ordem a mercado. Com
+// latência a ordem chega depois do sinal: se a fonte de klines conhece o preço desse
+// instante (backtests), ele substitui o último preço. O deslizamento é aplicado em seguida.
+func (p *PaperExchange) marketFill(side binance.SideType, quantity float64) (float64, time.Time) {
+	price, at := p.price, p.now()
+	if p.exec.Latency > 0 {
+		at = at.Add(p.exec.Latency)
+		if path, ok := p.market.(pricePath); ok {
+			if future, ok := path.PriceAt(at); ok {
+				price = future
+			}
+		}
+	}
+
+	slippage := 0.0
+	switch p.exec.Slippage {
+	case config.SlippageFixed:
+		slippage = p.exec.SlippageBps / 10000
+	case config.SlippageVolatility:
+		slippage = p.exec.VolFactor * p.shortestRange()
+	case config.SlippageBook:
+		if book, ok := p.book.FillPrice(side, quantity, price); ok {
+			return book, at
+		}
+		// Sem depth gravado (ou sem quantidade suficiente) usa o deslizamento fixo
+		slippage = p.exec.SlippageBps / 10000
+	}
+	if side == binance.SideTypeBuy {
+		return price * (1 + slippage), at
+	}
+	return price * (1 - slippage), at
+}
+
+// shortestRange retorna a amplitude relativa do último candle do menor intervalo recebido
+func (p *PaperExchange) shortestRange() float64 {
+	var best candleRange
+	for _, r := range p.ranges {
+		if best.duration == 0 || (r.duration > 0 && r.duration < best.duration) {
+			best = r
+		}
+	}
+	return best.value
+}
+
+// checkNotional recusa ordens abaixo do valor mínimo, como o filtro NOTIONAL da Binance
+func (p *PaperExchange) checkNotional(quantity, price float64) error {
+	if quantity*price < p.exec.MinNotional {
+		return &common.APIError{Code: -1013, Message: "Filter failure: NOTIONAL"}
+	}
+	return nil
+}
+
+// checkBalance recusa a ordem como a Binance quando o saldo livre não cobre a operação
+func (p *PaperExchange) checkBalance(side binance.SideType, quantity, price float64) error {
+	if side == binance.SideTypeBuy && quantity*price*(1+p.exec.FeeRate(false)) > p.usdt+1e-9 {
+		return &common.APIError{Code: -2010, Message: "Account has insufficient balance for requested action."}
+	}
+	if side == binance.SideTypeSell && quantity > p.btc+1e-12 {
+		return &common.APIError{Code: -2010, Message: "Account has insufficient balance for requested action."}
+	}
+	return nil
+}
+
+// fill executa a ordem inteira ao preço informado e atualiza os saldos. maker indica
+// uma ordem limitada que estava no livro, cobrada com a taxa de maker.
+func (p *PaperExchange) fill(order *binance.Order, quantity, price float64, maker bool, at time.Time) {
+	quote := quantity * price
+	fee := quote * p.exec.FeeRate(maker)
+	if order.Side == binance.SideTypeBuy {
+		if reserved, ok := p.reserved[order.OrderID]; ok {
+			// Libera a reserva; o valor executado é debitado abaixo
+			p.lockedUSDT -= reserved
+			p.usdt += reserved
+			delete(p.reserved, order.OrderID)
+		}
+		p.usdt -= quote + fee
+		p.btc += quantity
+	} else {
+		if maker {
+			p.lockedBTC -= quantity
+		} else {
+			p.btc -= quantity
+		}
+		p.usdt += quote - fee
+	}
+
+	order.ExecutedQuantity = formatQty(quantity)
+	order.CummulativeQuoteQuantity = formatQty(quote)
+	order.Status = binance.OrderStatusTypeFilled
+	order.IsWorking = false
+	order.UpdateTime = at.UnixMilli()
+
+	p.trades = append(p.trades, &binance.TradeV3{
+		ID:              int64(len(p.trades) + 1),
+		Symbol:          "BTCUSDT",
+		OrderID:         order.OrderID,
+		Price:           strconv.FormatFloat(price, 'f', 2, 64),
+		Quantity:        formatQty(quantity),
+		QuoteQuantity:   formatQty(quote),
+		Commission:      formatQty(fee),
+		CommissionAsset: "USDT",
+		Time:            order.UpdateTime,
+		IsBuyer:         order.Side == binance.SideTypeBuy,
+		IsMaker:         maker,
+	})
+}
+
+// matchLimitOrders executa, em ordem de criação, as ordens limitadas cruzadas pelo preço atual
+func (p *PaperExchange) matchLimitOrders() {
+	if p.price <= 0 {
+		return
+	}
+	for id := int64(1); id < p.nextID; id++ {
+		order, ok := p.orders[id]
+		if !ok || order.Status != binance.OrderStatusTypeNew || order.Type != binance.OrderTypeLimit {
+			continue
+		}
+		limit, _ := strconv.ParseFloat(order.Price, 64)
+		quantity, _ := strconv.ParseFloat(order.OrigQuantity, 64)
+		if (order.Side == binance.SideTypeBuy && p.price <= limit) ||
+			(order.Side == binance.SideTypeSell && p.price >= limit) {
+			p.fill(order, quantity, limit, true, p.now())
+		}
+	}
+}
+
+func (p *PaperExchange) GetOrder(ctx context.Context, orderID int64) (*binance.Order, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	order, ok := p.orders[orderID]
+	if !ok {
+		return nil, &common.APIError{Code: -2013, Message: "Order does not exist."}
+	}
+	copied := *order
+	return &copied, nil
+}
+
+func (p *PaperExchange) GetOrderByClientID(ctx context.Context, clientOrderID string) (*binance.Order, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	order := p.findClientOrder(clientOrderID)
+	if order == nil {
+		return nil, &common.APIError{Code: -2013, Message: "Order does not exist."}
+	}
+	copied := *order
+	return &copied, nil
+}
+
+// findClientOrder retorna a ordem mais recente com o clientOrderId. Chamado com p.mu travado.
+func (p *PaperExchange) findClientOrder(clientOrderID string) *binance.Order {
+	for id := p.nextID - 1; id >= 1; id-- {
+		if order, ok := p.orders[id]; ok && order.ClientOrderID == clientOrderID {
+			return order
+		}
+	}
+	return nil
+}
+
+func (p *PaperExchange) CancelOrder(ctx context.Context, orderID int64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	order, ok := p.orders[orderID]
+	if !ok || order.Status != binance.OrderStatusTypeNew {
+		return &common.APIError{Code: -2011, Message: "Unknown order sent."}
+	}
+
+	if order.Side == binance.SideTypeBuy {
+		reserved := p.reserved[orderID]
+		p.lockedUSDT -= reserved
+		p.usdt += reserved
+		delete(p.reserved, orderID)
+	} else {
+		quantity, _ := strconv.ParseFloat(order.OrigQuantity, 64)
+		p.lockedBTC -= quantity
+		p.btc += quantity
+	}
+	order.Status = binance.OrderStatusTypeCanceled
+	order.IsWorking = false
+	order.UpdateTime = p.now().UnixMilli()
+	return nil
+}
+
+func (p *PaperExchange) OpenOrders(ctx context.Context) ([]*binance.Order, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var open []*binance.Order
+	for id := int64(1); id < p.nextID; id++ {
+		if order, ok := p.orders[id]; ok && order.Status == binance.OrderStatusTypeNew {
+			copied := *order
+			open = append(open, &copied)
+		}
+	}
+	return open, nil
+}
+
+func (p *PaperExchange) ListTrades(ctx context.Context, limit int) ([]*binance.TradeV3, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	trades := p.trades
+	if limit > 0 && len(trades) > limit {
+		trades = trades[len(trades)-limit:]
+	}
+	return append([]*binance.TradeV3(nil), trades...), nil
+}
+
+// ExchangeInfo retorna filtros fixos equivalentes aos do BTCUSDT na Binance, com o
+// valor mínimo do modelo de execução
+func (p *PaperExchange) ExchangeInfo(ctx context.Context) (*binance.ExchangeInfo, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return &binance.ExchangeInfo{
+		Timezone:   "UTC",
+		ServerTime: p.now().UnixMilli(),
+		Symbols: []binance.Symbol{{
+			Symbol:     "BTCUSDT",
+			Status:     "TRADING",
+			BaseAsset:  "BTC",
+			QuoteAsset: "USDT",
+			Filters: []map[string]interface{}{
+				{"filterType": string(binance.SymbolFilterTypeLotSize), "minQty": "0.00001000", "maxQty": "9000.00000000", "stepSize": "0.00001000"},
+				{"filterType": string(binance.SymbolFilterTypeNotional), "minNotional": formatQty(p.exec.MinNotional), "applyMinToMarket": true},
+			},
+		}},
+	}, nil
+}
+
+func formatQty(v float64) string {
+	return strconv.FormatFloat(v, 'f', 8, 64)
+}
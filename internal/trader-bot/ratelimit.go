@@ -0,0 +1,281 @@
+package traderbot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/common"
+	"github.com/casarotto/binance-bot/internal/config"
+)
+
+// Priority é a prioridade de uma requisição REST. Perto dos limites da Binance as
+// de menor prioridade são descartadas ou atrasadas primeiro.
+type Priority int
+
+const (
+	PriorityUI       Priority = iota // atualizações da TUI e da API (saldos, fundos)
+	PriorityNormal                   // consultas do trader (histórico, filtros, ordens do grid)
+	PriorityCritical                 // ordens e as consultas feitas durante uma operação
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityUI:
+		return "ui"
+	case PriorityCritical:
+		return "critical"
+	}
+	return "normal"
+}
+
+// Fração do limite de peso por minuto que cada prioridade pode usar e quanto tempo
+// ela espera pela liberação antes de desistir (a UI nunca espera)
+var (
+	priorityWeightShare = map[Priority]float64{PriorityUI: 0.5, PriorityNormal: 0.8, PriorityCritical: 0.95}
+	priorityMaxWait     = map[Priority]time.Duration{PriorityUI: 0, PriorityNormal: 5 * time.Second, PriorityCritical: 15 * time.Second}
+)
+
+// Espera após um 429/418 sem o cabeçalho Retry-After
+const defaultRetryAfter = 60 * time.Second
+
+// ErrRateLimited indica uma requisição não enviada para respeitar os limites da Binance
+var ErrRateLimited = errors.New("limite de requisições da Binance")
+
+type priorityKey struct{}
+
+// WithPriority marca as requisições feitas com ctx com a prioridade informada
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+func priorityFrom(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+	return PriorityNormal
+}
+
+// requestWeights é o peso dos endpoints usados pelo bot (os demais pesam 1)
+var requestWeights = map[string]int{
+	"GET /api/v3/account":      20,
+	"GET /api/v3/myTrades":     20,
+	"GET /api/v3/exchangeInfo": 20,
+	"GET /api/v3/openOrders":   6,
+	"GET /api/v3/order":        4,
+	"GET /api/v3/klines":       2,
+}
+
+// requestCost retorna o peso da requisição e se ela cria uma ordem
+func requestCost(req *http.Request) (weight int, order bool) {
+	key := req.Method + " " + req.URL.Path
+	weight, ok := requestWeights[key]
+	if !ok {
+		weight = 1
+	}
+	return weight, key == "POST /api/v3/order"
+}
+
+// rateLimiter controla o peso por minuto e as ordens a cada 10 segundos. Antes de
+// cada requisição o peso conhecido do endpoint é reservado; os cabeçalhos
+// X-MBX-USED-WEIGHT-1M e X-MBX-ORDER-COUNT-10S da resposta corrigem a contagem.
+type rateLimiter struct {
+	mu           sync.Mutex
+	now          func() time.Time
+	weightLimit  int
+	orderLimit   int
+	weight       int
+	weightWindow time.Time // minuto atual
+	orders       int
+	orderWindow  time.Time // janela de 10 segundos atual
+	blockedUntil time.Time // fim da espera pedida por um 429/418
+
+	onThrottle func(p Priority, action string)            // "wait" ou "shed"
+	onBackoff  func(status int, retryAfter time.Duration) // 429/418 recebido
+}
+
+func newRateLimiter(limits config.RateLimitConfig) *rateLimiter {
+	return &rateLimiter{
+		now:         time.Now,
+		weightLimit: limits.Weight,
+		orderLimit:  limits.Orders,
+		onThrottle:  func(Priority, string) {},
+		onBackoff:   func(int, time.Duration) {},
+	}
+}
+
+func (l *rateLimiter) setLimits(limits config.RateLimitConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.weightLimit = limits.Weight
+	l.orderLimit = limits.Orders
+}
+
+// acquire reserva o peso da requisição, esperando a liberação se a prioridade permitir
+func (l *rateLimiter) acquire(ctx context.Context, p Priority, weight int, order bool) error {
+	deadline := l.now().Add(priorityMaxWait[p])
+	waited := false
+	for {
+		l.mu.Lock()
+		wait, reason := l.reserve(p, weight, order)
+		l.mu.Unlock()
+		if wait == 0 {
+			if waited {
+				l.onThrottle(p, "wait")
+			}
+			return nil
+		}
+		if l.now().Add(wait).After(deadline) {
+			l.onThrottle(p, "shed")
+			return fmt.Errorf("%w: %s (prioridade %s)", ErrRateLimited, reason, p)
+		}
+
+		waited = true
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve reserva o peso ou retorna quanto esperar e o motivo. Chamado com l.mu travado.
+func (l *rateLimiter) reserve(p Priority, weight int, order bool) (time.Duration, string) {
+	now := l.now()
+	l.roll(now)
+
+	if now.Before(l.blockedUntil) {
+		return l.blockedUntil.Sub(now), fmt.Sprintf("aguardando o Retry-After da Binance até %s", l.blockedUntil.Format("15:04:05"))
+	}
+	if limit := int(float64(l.weightLimit) * priorityWeightShare[p]); l.weight+weight > limit {
+		return l.weightWindow.Add(time.Minute).Sub(now), fmt.Sprintf("peso %d de %d usado no minuto", l.weight, l.weightLimit)
+	}
+	if order && l.orders >= l.orderLimit {
+		return l.orderWindow.Add(10 * time.Second).Sub(now), fmt.Sprintf("%d ordens nos últimos 10s", l.orders)
+	}
+
+	l.weight += weight
+	if order {
+		l.orders++
+	}
+	return 0, ""
+}
+
+// roll zera as contagens quando o minuto ou a janela de 10 segundos muda
+func (l *rateLimiter) roll(now time.Time) {
+	if window := now.Truncate(time.Minute); !window.Equal(l.weightWindow) {
+		l.weightWindow, l.weight = window, 0
+	}
+	if window := now.Truncate(10 * time.Second); !window.Equal(l.orderWindow) {
+		l.orderWindow, l.orders = window, 0
+	}
+}
+
+// observe atualiza as contagens com os cabeçalhos da resposta e inicia a espera de um 429/418
+func (l *rateLimiter) observe(resp *http.Response) {
+	l.mu.Lock()
+	now := l.now()
+	l.roll(now)
+	// Os cabeçalhos incluem o peso de outros processos no mesmo IP; as reservas de
+	// requisições ainda em andamento podem não estar neles
+	if used, err := strconv.Atoi(resp.Header.Get("X-Mbx-Used-Weight-1m")); err == nil {
+		l.weight = max(l.weight, used)
+	}
+	if orders, err := strconv.Atoi(resp.Header.Get("X-Mbx-Order-Count-10s")); err == nil {
+		l.orders = max(l.orders, orders)
+	}
+
+	var retryAfter time.Duration
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusTeapot {
+		retryAfter = defaultRetryAfter
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		if until := now.Add(retryAfter); until.After(l.blockedUntil) {
+			l.blockedUntil = until
+		}
+	}
+	l.mu.Unlock()
+
+	if retryAfter > 0 {
+		l.onBackoff(resp.StatusCode, retryAfter)
+	}
+}
+
+// status retorna o peso e as ordens contados nas janelas atuais e o tempo restante da espera
+func (l *rateLimiter) status() (weight, orders int, backoff time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.roll(now)
+	if now.Before(l.blockedUntil) {
+		backoff = l.blockedUntil.Sub(now)
+	}
+	return l.weight, l.orders, backoff
+}
+
+// weightTransport passa cada requisição REST pelo limitador e registra o peso e as
+// ordens informados pela Binance em cada resposta. Respostas de erro sem o código
+// da Binance viram HTTPStatusError, para serem classificadas pelo status.
+type weightTransport struct {
+	base    http.RoundTripper
+	metrics *traderMetrics
+	limiter *rateLimiter
+}
+
+func (w *weightTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	weight, order := requestCost(req)
+	if err := w.limiter.acquire(req.Context(), priorityFrom(req.Context()), weight, order); err != nil {
+		return nil, err
+	}
+	resp, err := w.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	w.limiter.observe(resp)
+	used, orders, _ := w.limiter.status()
+	w.metrics.usedWeight.Set(float64(used))
+	w.metrics.orderCount.Set(float64(orders))
+	if resp.StatusCode >= http.StatusBadRequest {
+		return checkErrorResponse(resp)
+	}
+	return resp, nil
+}
+
+// checkErrorResponse devolve a resposta de erro com o JSON {code, msg} da Binance
+// intacta para o go-binance e troca as demais (página HTML de um WAF ou proxy,
+// corpo vazio) por um HTTPStatusError
+func checkErrorResponse(resp *http.Response) (*http.Response, error) {
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var apiErr common.APIError
+	if json.Unmarshal(data, &apiErr) == nil && apiErr.Code != 0 {
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+		return resp, nil
+	}
+	body := strings.TrimSpace(string(data))
+	if len(body) > 200 {
+		body = body[:200] + "..."
+	}
+	return nil, &HTTPStatusError{StatusCode: resp.StatusCode, Body: body}
+}
+
+// SetRateLimits ajusta os limites de peso e de ordens usados pelo limitador de requisições
+func (t *BTCTrader) SetRateLimits(limits config.RateLimitConfig) {
+	if t.limiter != nil {
+		t.limiter.setLimits(limits)
+	}
+}
@@ -0,0 +1,207 @@
+package traderbot
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+)
+
+// Streams que podem ser gravados: os klines usados pelo trader e, opcionalmente,
+// o livro de ofertas (depth) e os negócios agregados (aggTrade) do BTCUSDT
+const (
+	StreamKline    = "kline"
+	StreamDepth    = "depth"
+	StreamAggTrade = "aggTrade"
+)
+
+// Eventos gravados no início da execução para o replay partir do mesmo estado:
+// o histórico de klines carregado via REST e os saldos da conta
+const (
+	StreamKlineHistory = "klineHistory"
+	StreamAccount      = "account"
+)
+
+// RecordedKlines é o histórico de um intervalo carregado via REST (backfill dos timeframes)
+type RecordedKlines struct {
+	Interval string           `json:"interval"`
+	Klines   []*binance.Kline `json:"klines"`
+}
+
+// RecordedAccount são os saldos livres da conta no início da gravação
+type RecordedAccount struct {
+	BTC  float64 `json:"btc"`
+	USDT float64 `json:"usdt"`
+}
+
+// RecordedEvent é uma linha da gravação: o evento do WebSocket e o instante do recebimento
+type RecordedEvent struct {
+	Time   int64           `json:"time"` // recebimento, em milissegundos
+	Stream string          `json:"stream"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// Recorder grava eventos de mercado em um arquivo JSON lines comprimido (gzip)
+type Recorder struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	gz   *gzip.Writer
+}
+
+// NewRecorder cria um arquivo de gravação novo em dir, nomeado pela data de início
+func NewRecorder(dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de gravações: %v", err)
+	}
+	path := filepath.Join(dir, "btcusdt-"+time.Now().Format("20060102-150405")+".jsonl.gz")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar gravação: %v", err)
+	}
+	return &Recorder{path: path, file: file, gz: gzip.NewWriter(file)}, nil
+}
+
+// Path retorna o caminho do arquivo de gravação
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+// Record grava um evento. Cada linha é descarregada no arquivo para que uma
+// gravação interrompida continue legível até o último evento.
+func (r *Recorder) Record(stream string, event any) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(RecordedEvent{Time: time.Now().UnixMilli(), Stream: stream, Data: data})
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.gz == nil {
+		return fmt.Errorf("gravação encerrada")
+	}
+	if _, err := r.gz.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return r.gz.Flush()
+}
+
+// Close finaliza o arquivo comprimido
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.gz == nil {
+		return nil
+	}
+	err := r.gz.Close()
+	if cerr := r.file.Close(); err == nil {
+		err = cerr
+	}
+	r.gz = nil
+	return err
+}
+
+// SetRecorder grava os klines recebidos pelo trader em r (nil desabilita). Os saldos
+// da conta são gravados na hora, como saldo inicial do replay.
+func (t *BTCTrader) SetRecorder(r *Recorder) {
+	t.recorder = r
+	if r == nil {
+		return
+	}
+	btc, usdt, err := t.getBalances(WithPriority(context.Background(), PriorityNormal))
+	if err != nil {
+		t.logWarn("⚠️ Saldos não gravados, o replay usará os saldos informados: %v", err)
+		return
+	}
+	t.recordEvent(StreamAccount, RecordedAccount{BTC: btc, USDT: usdt})
+}
+
+// klineStream assina os klines do intervalo na Exchange, gravando os eventos se houver gravação
+func (t *BTCTrader) klineStream(interval string, handler binance.WsKlineHandler, errHandler binance.ErrHandler) (chan struct{}, chan struct{}, error) {
+	if t.recorder != nil {
+		next := handler
+		handler = func(event *binance.WsKlineEvent) {
+			if err := t.recorder.Record(StreamKline, event); err != nil {
+				t.logWarn("⚠️ Erro ao gravar kline: %v", err)
+			}
+			next(event)
+		}
+	}
+	return t.exchange.KlineStream(interval, handler, errHandler)
+}
+
+// RecordMarketStreams grava os streams de depth e/ou aggTrade do BTCUSDT até ctx ser
+// cancelado, reconectando quando um WebSocket cai. Os klines são gravados pelo trader.
+func (t *BTCTrader) RecordMarketStreams(ctx context.Context, streams []string) {
+	if t.recorder == nil {
+		return
+	}
+	for _, stream := range streams {
+		var serve func(binance.ErrHandler) (chan struct{}, chan struct{}, error)
+		switch stream {
+		case StreamDepth:
+			serve = func(errHandler binance.ErrHandler) (chan struct{}, chan struct{}, error) {
+				return binance.WsDepthServe100Ms("BTCUSDT", func(event *binance.WsDepthEvent) {
+					t.recordEvent(StreamDepth, event)
+				}, errHandler)
+			}
+		case StreamAggTrade:
+			serve = func(errHandler binance.ErrHandler) (chan struct{}, chan struct{}, error) {
+				return binance.WsAggTradeServe("BTCUSDT", func(event *binance.WsAggTradeEvent) {
+					t.recordEvent(StreamAggTrade, event)
+				}, errHandler)
+			}
+		default:
+			continue
+		}
+		go t.serveRecording(ctx, stream, serve)
+	}
+}
+
+func (t *BTCTrader) recordEvent(stream string, event any) {
+	if err := t.recorder.Record(stream, event); err != nil {
+		t.logWarn("⚠️ Erro ao gravar %s: %v", stream, err)
+	}
+}
+
+// serveRecording mantém um stream gravado aberto, reconectando com backoff
+func (t *BTCTrader) serveRecording(ctx context.Context, stream string, serve func(binance.ErrHandler) (chan struct{}, chan struct{}, error)) {
+	errHandler := func(err error) {
+		t.log("Erro no WebSocket de %s gravado: %v", stream, err)
+	}
+	backoff := supervisorMinBackoff
+	for {
+		doneC, stopC, err := serve(errHandler)
+		if err == nil {
+			backoff = supervisorMinBackoff
+			select {
+			case <-ctx.Done():
+				close(stopC)
+				return
+			case <-doneC:
+			}
+			err = fmt.Errorf("WebSocket encerrado")
+		}
+		t.logWarn("⚠️ Gravação de %s interrompida: %v - reconectando em %s", stream, err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > supervisorMaxBackoff {
+			backoff = supervisorMaxBackoff
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package traderbot
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+)
+
+// Player reproduz uma gravação do Recorder como fonte de klines (MarketData). Os
+// eventos são entregues em ordem, numa única goroutine, respeitando os intervalos
+// gravados divididos por speed (speed <= 0 entrega o mais rápido possível).
+type Player struct {
+	path    string
+	speed   float64
+	primary string // intervalo dos sinais: a reprodução começa quando ele é assinado
+
+	mu        sync.Mutex
+	handlers  map[string]binance.WsKlineHandler
+	depth     []func(DepthUpdate)         // assinantes dos eventos de depth gravados
+	aggTrades []binance.WsAggTradeHandler // assinantes dos negócios agregados gravados
+	streams   []chan struct{}             // canais done entregues aos assinantes
+	stopC     chan struct{}
+	started   bool
+
+	history map[string][]*binance.Kline // histórico REST gravado por intervalo
+	account *RecordedAccount            // saldos gravados no início (nil em gravações antigas)
+
+	clock    atomic.Int64 // instante do evento atual, em milissegundos
+	events   atomic.Int64
+	finished chan struct{}
+	err      error
+}
+
+// NewPlayer abre a gravação em path. primary é o intervalo dos sinais do trader,
+// assinado por último em Start depois dos timeframes de confirmação.
+func NewPlayer(path string, speed float64, primary string) (*Player, error) {
+	p := &Player{
+		path:     path,
+		speed:    speed,
+		primary:  primary,
+		handlers: make(map[string]binance.WsKlineHandler),
+		history:  make(map[string][]*binance.Kline),
+		stopC:    make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+
+	// Lê o início da gravação para validar o arquivo e iniciar o relógio. O histórico
+	// e os saldos são gravados antes do primeiro kline do WebSocket.
+	var decodeErr error
+	err := ReadRecording(path, func(event RecordedEvent) bool {
+		if p.clock.Load() == 0 {
+			p.clock.Store(event.Time)
+		}
+		switch event.Stream {
+		case StreamKlineHistory:
+			var history RecordedKlines
+			if decodeErr = json.Unmarshal(event.Data, &history); decodeErr != nil {
+				return false
+			}
+			// Um novo backfill (reconexão) não substitui o do início
+			if _, ok := p.history[history.Interval]; !ok {
+				p.history[history.Interval] = history.Klines
+			}
+		case StreamAccount:
+			var account RecordedAccount
+			if decodeErr = json.Unmarshal(event.Data, &account); decodeErr != nil {
+				return false
+			}
+			if p.account == nil {
+				p.account = &account
+			}
+		case StreamKline:
+			return false
+		}
+		return true
+	})
+	if err == nil && decodeErr != nil {
+		err = fmt.Errorf("início da gravação inválido: %v", decodeErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.clock.Load() == 0 {
+		return nil, fmt.Errorf("gravação vazia: %s", path)
+	}
+	return p, nil
+}
+
+// Now retorna o instante do evento em reprodução; usado como relógio do trader
+func (p *Player) Now() time.Time {
+	return time.UnixMilli(p.clock.Load())
+}
+
+// Events retorna quantos klines já foram entregues
+func (p *Player) Events() int64 {
+	return p.events.Load()
+}
+
+// Done é fechado quando a gravação termina ou a reprodução é interrompida
+func (p *Player) Done() <-chan struct{} {
+	return p.finished
+}
+
+// Err retorna o erro de leitura que encerrou a reprodução, se houver
+func (p *Player) Err() error {
+	<-p.finished
+	return p.err
+}
+
+// DepthStream entrega os eventos de depth gravados (RECORD_STREAMS=depth), na ordem
+// em que chegaram. São diffs: sem snapshot, o livro se forma com os níveis que mudam.
+// Deve ser assinado antes do intervalo dos sinais.
+func (p *Player) DepthStream(handler func(DepthUpdate), errHandler binance.ErrHandler) (chan struct{}, chan struct{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.started {
+		return nil, nil, fmt.Errorf("o depth deve ser assinado antes do início da reprodução")
+	}
+	p.depth = append(p.depth, handler)
+	done, stop := p.addStream()
+	return done, stop, nil
+}
+
+// AggTradeStream entrega os negócios agregados gravados (RECORD_STREAMS=aggTrade).
+// Deve ser assinado antes do intervalo dos sinais.
+func (p *Player) AggTradeStream(handler binance.WsAggTradeHandler, errHandler binance.ErrHandler) (chan struct{}, chan struct{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.started {
+		return nil, nil, fmt.Errorf("o aggTrade deve ser assinado antes do início da reprodução")
+	}
+	p.aggTrades = append(p.aggTrades, handler)
+	done, stop := p.addStream()
+	return done, stop, nil
+}
+
+// Account retorna os saldos da conta no início da gravação (false em gravações sem eles)
+func (p *Player) Account() (RecordedAccount, bool) {
+	if p.account == nil {
+		return RecordedAccount{}, false
+	}
+	return *p.account, true
+}
+
+// Klines retorna o histórico carregado via REST no início da gravação, para os
+// timeframes começarem como na execução gravada. Gravações sem histórico começam vazias.
+func (p *Player) Klines(ctx context.Context, interval string, limit int) ([]*binance.Kline, error) {
+	klines := p.history[interval]
+	if limit > 0 && len(klines) > limit {
+		klines = klines[len(klines)-limit:]
+	}
+	return klines, nil
+}
+
+// KlineStream registra o handler do intervalo. A reprodução começa quando o
+// intervalo dos sinais é assinado; todos os canais done fecham no fim da gravação.
+func (p *Player) KlineStream(interval string, handler binance.WsKlineHandler, errHandler binance.ErrHandler) (chan struct{}, chan struct{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.started && interval == p.primary {
+		return nil, nil, fmt.Errorf("a gravação só pode ser reproduzida uma vez")
+	}
+
+	p.handlers[interval] = handler
+	done, stop := p.addStream()
+	if interval == p.primary {
+		p.started = true
+		go p.play(errHandler)
+	}
+	return done, stop, nil
+}
+
+// addStream cria os canais de um assinante: done fecha no fim da reprodução e fechar
+// stop a interrompe. Chamado com p.mu travado.
+func (p *Player) addStream() (done, stop chan struct{}) {
+	done = make(chan struct{})
+	stop = make(chan struct{})
+	p.streams = append(p.streams, done)
+	go func() {
+		select {
+		case <-stop:
+			p.halt()
+		case <-p.finished:
+		}
+	}()
+	return done, stop
+}
+
+// halt interrompe a reprodução (Stop do trader fecha o stop de algum stream)
+func (p *Player) halt() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	select {
+	case <-p.stopC:
+	default:
+		close(p.stopC)
+	}
+}
+
+func (p *Player) play(errHandler binance.ErrHandler) {
+	var prev int64
+	err := ReadRecording(p.path, func(event RecordedEvent) bool {
+		p.mu.Lock()
+		depthHandlers, tradeHandlers := p.depth, p.aggTrades
+		p.mu.Unlock()
+
+		// Decodifica só os streams assinados; o evento é entregue depois da espera
+		var deliver func()
+		switch {
+		case event.Stream == StreamKline:
+			var kline binance.WsKlineEvent
+			if err := json.Unmarshal(event.Data, &kline); err != nil {
+				errHandler(fmt.Errorf("evento de kline inválido na gravação: %v", err))
+				return true
+			}
+			p.mu.Lock()
+			handler := p.handlers[kline.Kline.Interval]
+			p.mu.Unlock()
+			if handler == nil {
+				return true
+			}
+			deliver = func() {
+				handler(&kline)
+				p.events.Add(1)
+			}
+		case event.Stream == StreamDepth && len(depthHandlers) > 0:
+			var depth binance.WsDepthEvent
+			if err := json.Unmarshal(event.Data, &depth); err != nil {
+				errHandler(fmt.Errorf("evento de depth inválido na gravação: %v", err))
+				return true
+			}
+			update := DepthUpdate{Time: event.Time, Bids: depth.Bids, Asks: depth.Asks}
+			deliver = func() {
+				for _, handler := range depthHandlers {
+					handler(update)
+				}
+			}
+		case event.Stream == StreamAggTrade && len(tradeHandlers) > 0:
+			var trade binance.WsAggTradeEvent
+			if err := json.Unmarshal(event.Data, &trade); err != nil {
+				errHandler(fmt.Errorf("evento de aggTrade inválido na gravação: %v", err))
+				return true
+			}
+			deliver = func() {
+				for _, handler := range tradeHandlers {
+					handler(&trade)
+				}
+			}
+		default:
+			return true
+		}
+
+		if prev > 0 && p.speed > 0 && event.Time > prev {
+			wait := time.Duration(float64(time.Duration(event.Time-prev)*time.Millisecond) / p.speed)
+			select {
+			case <-p.stopC:
+				return false
+			case <-time.After(wait):
+			}
+		}
+		prev = event.Time
+
+		select {
+		case <-p.stopC:
+			return false
+		default:
+		}
+
+		p.clock.Store(event.Time)
+		deliver()
+		return true
+	})
+
+	p.err = err
+	p.mu.Lock()
+	for _, done := range p.streams {
+		close(done)
+	}
+	p.mu.Unlock()
+	close(p.finished)
+}
+
+// ReadRecording percorre os eventos de uma gravação até fn retornar false ou o arquivo acabar
+func ReadRecording(path string, fn func(RecordedEvent) bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir gravação: %v", err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir gravação: %v", err)
+	}
+	defer gz.Close()
+
+	reader := bufio.NewReaderSize(gz, 64*1024)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if len(data) > 1 {
+			var event RecordedEvent
+			if jerr := json.Unmarshal(data, &event); jerr != nil {
+				// Uma gravação interrompida pode terminar no meio de uma linha
+				if err == io.EOF || err == io.ErrUnexpectedEOF {
+					return nil
+				}
+				return fmt.Errorf("linha %d da gravação: %v", line, jerr)
+			}
+			if !fn(event) {
+				return nil
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			// Sem o rodapé do gzip (gravação interrompida) os eventos lidos continuam válidos
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("erro ao ler gravação: %v", err)
+		}
+	}
+}
+
+// SetClock substitui o relógio do trader; no replay é o instante do evento gravado
+func (t *BTCTrader) SetClock(now func() time.Time) {
+	t.now = now
+}
+
+// FlushTradeHistory grava o histórico de trades de forma síncrona (fim do replay)
+func (t *BTCTrader) FlushTradeHistory() {
+	t.saveTradeHistory()
+}
@@ -0,0 +1,168 @@
+package traderbot
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotatingFile é um io.Writer que troca o arquivo de log quando ele passa do tamanho
+// máximo ou quando o intervalo de rotação vence. O arquivo antigo é renomeado com a
+// data, compactado em .gz e só os maxBackups arquivos mais recentes são mantidos.
+type rotatingFile struct {
+	path        string
+	maxSize     int64         // 0 = sem limite de tamanho
+	rotateEvery time.Duration // 0 = sem rotação por tempo
+	maxBackups  int           // 0 = mantém todos
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	wg       sync.WaitGroup // compactações em andamento
+}
+
+func newRotatingFile(path string, maxSize int64, rotateEvery time.Duration, maxBackups int) (*rotatingFile, error) {
+	r := &rotatingFile{path: path, maxSize: maxSize, rotateEvery: rotateEvery, maxBackups: maxBackups}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("erro ao criar arquivo de log: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("erro ao consultar arquivo de log: %v", err)
+	}
+	r.file = file
+	r.size = info.Size()
+	// Um arquivo existente conta o tempo a partir da última modificação
+	r.openedAt = time.Now()
+	if r.size > 0 {
+		r.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.shouldRotate(int64(len(p))) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) shouldRotate(next int64) bool {
+	if r.size == 0 {
+		return false
+	}
+	if r.maxSize > 0 && r.size+next > r.maxSize {
+		return true
+	}
+	return r.rotateEvery > 0 && time.Since(r.openedAt) >= r.rotateEvery
+}
+
+// rotate renomeia o arquivo atual, abre um novo e compacta o antigo em segundo plano
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+
+	ext := filepath.Ext(r.path)
+	base := strings.TrimSuffix(r.path, ext)
+	archived := fmt.Sprintf("%s-%s%s", base, time.Now().Format("20060102-150405.000"), ext)
+	for i := 1; fileExists(archived) || fileExists(archived+".gz"); i++ {
+		archived = fmt.Sprintf("%s-%s-%d%s", base, time.Now().Format("20060102-150405.000"), i, ext)
+	}
+	if err := os.Rename(r.path, archived); err != nil {
+		return fmt.Errorf("erro ao rotacionar log: %v", err)
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		if err := compressFile(archived); err != nil {
+			fmt.Fprintf(os.Stderr, "erro ao compactar %s: %v\n", archived, err)
+		}
+		r.prune(base, ext)
+	}()
+	return nil
+}
+
+// prune remove os arquivos compactados mais antigos além de maxBackups
+func (r *rotatingFile) prune(base, ext string) {
+	if r.maxBackups <= 0 {
+		return
+	}
+	archives, err := filepath.Glob(base + "-*" + ext + ".gz")
+	if err != nil || len(archives) <= r.maxBackups {
+		return
+	}
+	// O nome contém a data no formato AAAAMMDD-HHMMSS.mmm, então a ordem alfabética é a cronológica
+	sort.Strings(archives)
+	for _, old := range archives[:len(archives)-r.maxBackups] {
+		os.Remove(old)
+	}
+}
+
+// Close fecha o arquivo atual e espera as compactações pendentes
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	err := r.file.Close()
+	r.mu.Unlock()
+	r.wg.Wait()
+	return err
+}
+
+func compressFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(out)
+	if _, err := io.Copy(gz, in); err != nil {
+		out.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
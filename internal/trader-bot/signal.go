@@ -0,0 +1,125 @@
+package traderbot
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/casarotto/binance-bot/internal/notify"
+)
+
+// Origens registradas no histórico de trades
+const (
+	SourceStrategy = "strategy"  // sinais da estratégia (rsi_ma ou rules)
+	SourceStopLoss = "stop_loss" // venda pelo stop loss
+	SourceManual   = "manual"    // fechamento forçado pela TUI ou pela API
+	SourceGrid     = "grid"      // execuções da estratégia de grid
+	SourceDCA      = "dca"       // ordens de segurança do DCA
+	SourceWebhook  = "webhook"   // alertas externos recebidos pelo webhook de sinais
+)
+
+// tradeSignal identifica a origem de uma operação
+type tradeSignal struct {
+	source   string  // origem registrada no histórico
+	id       string  // ID do alerta externo
+	sizePct  float64 // fração do capital usada na compra (0 = RISK_PER_TRADE)
+	quantity float64 // quantidade da compra já validada pelos filtros (0 = calcular pelo risco)
+}
+
+// ErrSignalRejected indica um sinal externo recusado pelas validações, sem envio de ordem
+var ErrSignalRejected = errors.New("sinal recusado")
+
+// Signal é um alerta externo (ex. TradingView) pedindo uma compra ou venda
+type Signal struct {
+	ID      string  `json:"id"`
+	Symbol  string  `json:"symbol"`
+	Action  string  `json:"action"`   // "buy" ou "sell"
+	SizePct float64 `json:"size_pct"` // fração do capital na compra (0 = RISK_PER_TRADE); ignorada na venda
+}
+
+func rejectSignal(format string, v ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrSignalRejected, fmt.Sprintf(format, v...))
+}
+
+// ExecuteSignal valida um alerta externo contra o estado do bot e os filtros da
+// corretora e o executa pelo mesmo caminho dos sinais internos. maxSizePct é o
+// maior tamanho aceito para uma compra.
+func (t *BTCTrader) ExecuteSignal(signal Signal, maxSizePct float64) (Trade, error) {
+	if signal.Symbol != "BTCUSDT" {
+		return Trade{}, rejectSignal("símbolo %q não operado (apenas BTCUSDT)", signal.Symbol)
+	}
+	if signal.Action != "buy" && signal.Action != "sell" {
+		return Trade{}, rejectSignal("ação inválida %q (use buy ou sell)", signal.Action)
+	}
+	if t.gridConfig.Enabled {
+		return Trade{}, rejectSignal("sinais externos não são aceitos na estratégia de grid")
+	}
+	if t.signalsPaused.Load() {
+		return Trade{}, rejectSignal("avaliação de sinais pausada")
+	}
+
+	t.tradeMutex.Lock()
+	defer t.tradeMutex.Unlock()
+
+	candle, ok := t.GetLastCandle()
+	if !ok {
+		return Trade{}, rejectSignal("preço atual ainda não disponível")
+	}
+	price := candle.Close
+
+	var quantity float64
+	if signal.Action == "buy" {
+		if t.inPosition {
+			return Trade{}, rejectSignal("já existe posição aberta")
+		}
+		if signal.SizePct < 0 || signal.SizePct > maxSizePct {
+			return Trade{}, rejectSignal("size_pct %.4f fora do limite (0 a %.4f)", signal.SizePct, maxSizePct)
+		}
+		risk := signal.SizePct
+		if risk == 0 {
+			risk = t.riskPerTrade
+		}
+		if risk > maxSizePct {
+			return Trade{}, rejectSignal("RISK_PER_TRADE %.4f acima do limite de sinais externos (%.4f)", risk, maxSizePct)
+		}
+		quantity = t.calculateTradeQuantity(price, risk)
+		if err := t.checkFilters(quantity, price); err != nil {
+			return Trade{}, rejectSignal("%v", err)
+		}
+		if err := t.checkLiquidity(quantity); err != nil {
+			return Trade{}, rejectSignal("%v", err)
+		}
+		if err := t.breaker.allow(binance.SideTypeBuy, t.now()); err != nil {
+			return Trade{}, rejectSignal("%v", err)
+		}
+	} else {
+		if !t.inPosition {
+			return Trade{}, rejectSignal("não há posição aberta")
+		}
+		if t.lastBuyQuantity == 0 {
+			return Trade{}, rejectSignal("quantidade da posição desconhecida; use o fechamento forçado")
+		}
+		if err := t.checkFilters(t.lastBuyQuantity, price); err != nil {
+			return Trade{}, rejectSignal("%v", err)
+		}
+	}
+
+	t.logImportant("📡 Sinal externo %s recebido: %s", signal.ID, signal.Action)
+	// A compra usa a quantidade validada acima, sem recalcular pelo saldo
+	trade, err := t.executeTrade(signal.Action, price, tradeSignal{
+		source:   SourceWebhook,
+		id:       signal.ID,
+		sizePct:  signal.SizePct,
+		quantity: quantity,
+	})
+	if err != nil {
+		t.notifyError("erro ao executar sinal externo "+signal.ID, err)
+		return Trade{}, err
+	}
+	if signal.Action == "buy" {
+		t.notifyTrade(notify.EventEntry, trade, nil)
+	} else {
+		t.notifyTrade(notify.EventExit, trade, nil)
+	}
+	return trade, nil
+}
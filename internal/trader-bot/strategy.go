@@ -0,0 +1,498 @@
+package traderbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/casarotto/binance-bot/internal/config"
+	"github.com/casarotto/binance-bot/internal/rules"
+)
+
+// Strategy decide as entradas e saídas do modo de sinais. Além da decisão,
+// retorna um resumo dos valores que a justificaram para o log.
+type Strategy interface {
+	Name() string
+	ShouldEnter(t *BTCTrader, price float64) (bool, string)
+	ShouldExit(t *BTCTrader, price float64) (bool, string)
+}
+
+// SetStrategy troca a estratégia de sinais e prepara os timeframes usados pelas regras
+func (t *BTCTrader) SetStrategy(strategy Strategy) {
+	t.strategy = strategy
+	if rs, ok := strategy.(*RuleStrategy); ok {
+		for interval, capacity := range rs.timeframes() {
+			if interval != t.signalInterval {
+				t.ensureTimeframe(interval, capacity)
+			}
+		}
+		if window, ok := rs.orderFlowWindow(t.flowConfig.Window); ok {
+			t.ensureOrderFlow(window)
+		}
+		t.logImportant("📜 Estratégia por regras ativa - Entrada: %s | Saída: %s", rs.Entry(), rs.Exit())
+	}
+}
+
+// GetStrategy retorna a estratégia de sinais ativa
+func (t *BTCTrader) GetStrategy() Strategy {
+	return t.strategy
+}
+
+// SetSignalParams ajusta os parâmetros da estratégia rsi_ma e o stop loss. Deve
+// ser chamado antes de Start.
+func (t *BTCTrader) SetSignalParams(p config.SignalParams) {
+	t.rsiPeriod = p.RSIPeriod
+	t.rsiBuy = p.RSIBuy
+	t.rsiSell = p.RSISell
+	t.maShort = p.MAShort
+	t.maLong = p.MALong
+	t.stopLoss = p.StopLoss
+	t.minProfit = p.MinProfit
+}
+
+// GetSignalParams retorna os parâmetros atuais da estratégia rsi_ma e do stop loss
+func (t *BTCTrader) GetSignalParams() config.SignalParams {
+	return config.SignalParams{
+		RSIPeriod: t.rsiPeriod,
+		RSIBuy:    t.rsiBuy,
+		RSISell:   t.rsiSell,
+		MAShort:   t.maShort,
+		MALong:    t.maLong,
+		StopLoss:  t.stopLoss,
+		MinProfit: t.minProfit,
+	}
+}
+
+// rsiMAStrategy é a estratégia padrão: RSI com cruzamento das médias (por padrão
+// RSI(14) abaixo de 30/acima de 70 e médias 9/21)
+type rsiMAStrategy struct{}
+
+func (rsiMAStrategy) Name() string {
+	return "rsi_ma"
+}
+
+func (rsiMAStrategy) indicators(t *BTCTrader) (rsi, maShort, maLong float64, ok bool) {
+	// Verificar se temos dados suficientes para todos os indicadores
+	if !t.hasEnoughData() {
+		n := t.candleCount()
+		t.log("Aguardando dados suficientes para indicadores (MA%d: %d/%d, RSI: %d/%d)",
+			t.maLong, n, t.maLong,
+			n, t.rsiPeriod+1)
+		return 0, 0, 0, false
+	}
+
+	rsi = t.calculateRSI()
+	maShort = t.calculateMA(t.maShort)
+	maLong = t.calculateMA(t.maLong)
+
+	// Se algum indicador retornou valor neutro/inválido, não operar
+	if rsi == 50.0 || maShort == 0 || maLong == 0 {
+		return 0, 0, 0, false
+	}
+	return rsi, maShort, maLong, true
+}
+
+func (s rsiMAStrategy) ShouldEnter(t *BTCTrader, price float64) (bool, string) {
+	rsi, maShort, maLong, ok := s.indicators(t)
+	if !ok {
+		return false, ""
+	}
+	if rsi < t.rsiBuy && maShort > maLong {
+		return true, fmt.Sprintf("RSI: %.2f, MA%d: %.2f, MA%d: %.2f", rsi, t.maShort, maShort, t.maLong, maLong)
+	}
+	return false, ""
+}
+
+func (s rsiMAStrategy) ShouldExit(t *BTCTrader, price float64) (bool, string) {
+	rsi, maShort, maLong, ok := s.indicators(t)
+	if !ok {
+		return false, ""
+	}
+
+	entryPrice := t.positions["BTC"]
+	// Calcular lucro considerando a quantidade correta
+	entryValue := entryPrice * t.lastBuyQuantity
+	currentValue := price * t.lastBuyQuantity
+	currentProfit := (currentValue - entryValue) / entryValue * 100
+
+	if price < t.calculateMinProfitablePrice(entryPrice) {
+		return false, ""
+	}
+
+	if (rsi > t.rsiSell || (maShort < maLong && rsi > 50)) && currentProfit >= t.minProfit {
+		return true, fmt.Sprintf("RSI: %.2f, MA%d: %.2f, MA%d: %.2f, Lucro: %.2f%%", rsi, t.maShort, maShort, t.maLong, maLong, currentProfit)
+	}
+	return false, ""
+}
+
+// RuleStrategy é uma estratégia escrita na linguagem de regras (pacote rules)
+type RuleStrategy struct {
+	entry *rules.Program
+	exit  *rules.Program
+}
+
+// RuleCheck é o resultado da avaliação de uma regra para exibição
+type RuleCheck struct {
+	Source string
+	Passed bool
+	Err    error
+}
+
+// RuleSchema lista as variáveis e funções disponíveis nas regras
+func RuleSchema() rules.Schema {
+	indicator := rules.Func{Params: []rules.Type{rules.Number, rules.String}, Optional: 1}
+	candle := rules.Func{Params: []rules.Type{rules.String}, Optional: 1}
+	flow := rules.Func{Params: []rules.Type{rules.Number}, Optional: 1}
+	return rules.Schema{
+		Vars: map[string]rules.Type{
+			"price":            rules.Number,
+			"in_position":      rules.Bool,
+			"entry_price":      rules.Number,
+			"quantity":         rules.Number,
+			"pnl_pct":          rules.Number,
+			"min_profit_price": rules.Number,
+			"btc_balance":      rules.Number,
+			"usdt_balance":     rules.Number,
+		},
+		Funcs: map[string]rules.Func{
+			"rsi":        indicator,
+			"sma":        indicator,
+			"ema":        indicator,
+			"change_pct": indicator,
+			"close":      candle,
+			"volume":     candle,
+
+			// Fluxo de ordens: janela opcional em segundos (padrão ORDERFLOW_WINDOW)
+			"delta":           flow,
+			"trade_intensity": flow,
+			"large_trades":    flow,
+			"cvd":             {},
+		},
+	}
+}
+
+// CompileRuleStrategy compila as regras de entrada e saída
+func CompileRuleStrategy(entry, exit string) (*RuleStrategy, error) {
+	schema := RuleSchema()
+	entryProgram, err := rules.Compile(entry, schema)
+	if err != nil {
+		return nil, fmt.Errorf("erro na regra de entrada, %v", err)
+	}
+	exitProgram, err := rules.Compile(exit, schema)
+	if err != nil {
+		return nil, fmt.Errorf("erro na regra de saída, %v", err)
+	}
+
+	if err := validateRuleCalls(entryProgram); err != nil {
+		return nil, fmt.Errorf("erro na regra de entrada, %v", err)
+	}
+	if err := validateRuleCalls(exitProgram); err != nil {
+		return nil, fmt.Errorf("erro na regra de saída, %v", err)
+	}
+
+	return &RuleStrategy{entry: entryProgram, exit: exitProgram}, nil
+}
+
+// validateRuleCalls verifica os períodos e intervalos passados aos indicadores
+func validateRuleCalls(program *rules.Program) error {
+	for _, call := range program.Calls() {
+		interval := ""
+		switch call.Name {
+		case "close", "volume":
+			if len(call.Args) > 0 {
+				interval = call.Args[0].Str
+			}
+		case "delta", "trade_intensity", "large_trades", "cvd":
+			if len(call.Args) > 0 {
+				window := call.Args[0].Num
+				if window < 1 || window > config.MaxOrderFlowWindow || window != float64(int(window)) {
+					return &rules.Error{Source: program.Source(), Column: call.Column,
+						Msg: fmt.Sprintf("janela de %s deve ser um inteiro de 1 a %d segundos", call.Name, config.MaxOrderFlowWindow)}
+				}
+			}
+		default:
+			period := call.Args[0].Num
+			if period < 1 || period != float64(int(period)) {
+				return &rules.Error{Source: program.Source(), Column: call.Column,
+					Msg: fmt.Sprintf("período de %s deve ser um inteiro positivo", call.Name)}
+			}
+			if len(call.Args) > 1 {
+				interval = call.Args[1].Str
+			}
+		}
+		if _, ok := validRuleIntervals[interval]; interval != "" && !ok {
+			return &rules.Error{Source: program.Source(), Column: call.Column,
+				Msg: fmt.Sprintf("intervalo %q inválido em %s", interval, call.Name)}
+		}
+	}
+	return nil
+}
+
+// Intervalos aceitos nas regras: os da Binance com duração fixa (IntervalDuration),
+// ou seja, sem o mensal "1M"
+var validRuleIntervals = map[string]struct{}{
+	"1s": {}, "1m": {}, "3m": {}, "5m": {}, "15m": {}, "30m": {},
+	"1h": {}, "2h": {}, "4h": {}, "6h": {}, "8h": {}, "12h": {},
+	"1d": {}, "3d": {}, "1w": {},
+}
+
+func (s *RuleStrategy) Name() string {
+	return "rules"
+}
+
+// Entry retorna o texto da regra de entrada
+func (s *RuleStrategy) Entry() string {
+	return s.entry.Source()
+}
+
+// Exit retorna o texto da regra de saída
+func (s *RuleStrategy) Exit() string {
+	return s.exit.Source()
+}
+
+func (s *RuleStrategy) ShouldEnter(t *BTCTrader, price float64) (bool, string) {
+	return s.evaluate(t, s.entry, price)
+}
+
+func (s *RuleStrategy) ShouldExit(t *BTCTrader, price float64) (bool, string) {
+	return s.evaluate(t, s.exit, price)
+}
+
+func (s *RuleStrategy) evaluate(t *BTCTrader, program *rules.Program, price float64) (bool, string) {
+	// Chamado pelo handler do WebSocket, que já segura o tradeMutex
+	ok, err := program.Eval(newRuleContext(t, price))
+	if err != nil {
+		if !errors.Is(err, rules.ErrNotReady) {
+			t.log("Erro ao avaliar regra %q: %v", program.Source(), err)
+		}
+		return false, ""
+	}
+	if !ok {
+		return false, ""
+	}
+	return true, fmt.Sprintf("regra: %s (preço $%.2f)", program.Source(), price)
+}
+
+// check avalia as regras de entrada e saída sem operar, para exibição na TUI
+func (s *RuleStrategy) check(ctx *ruleContext) (entry, exit RuleCheck) {
+	entry = RuleCheck{Source: s.entry.Source()}
+	entry.Passed, entry.Err = s.entry.Eval(ctx)
+	exit = RuleCheck{Source: s.exit.Source()}
+	exit.Passed, exit.Err = s.exit.Eval(ctx)
+	return entry, exit
+}
+
+// CheckRules avalia as regras da estratégia por regras no preço informado; ok é
+// false quando a estratégia ativa não é baseada em regras
+func (t *BTCTrader) CheckRules(price float64) (entry, exit RuleCheck, ok bool) {
+	rs, ok := t.GetStrategy().(*RuleStrategy)
+	if !ok {
+		return RuleCheck{}, RuleCheck{}, false
+	}
+	// Chamado pela TUI e pela TUI remota: copia a posição com o tradeMutex, como o Snapshot
+	t.tradeMutex.Lock()
+	ctx := newRuleContext(t, price)
+	t.tradeMutex.Unlock()
+	entry, exit = rs.check(ctx)
+	return entry, exit, true
+}
+
+// timeframes retorna os intervalos usados pelas regras e quantos candles cada um precisa
+func (s *RuleStrategy) timeframes() map[string]int {
+	needed := make(map[string]int)
+	for _, program := range []*rules.Program{s.entry, s.exit} {
+		for _, call := range program.Calls() {
+			interval := ""
+			period := 1
+			switch call.Name {
+			case "close", "volume":
+				if len(call.Args) > 0 {
+					interval = call.Args[0].Str
+				}
+			case "delta", "trade_intensity", "large_trades", "cvd":
+				continue
+			default:
+				period = int(call.Args[0].Num)
+				if len(call.Args) > 1 {
+					interval = call.Args[1].Str
+				}
+			}
+			if interval == "" {
+				continue
+			}
+			// Margem para o RSI (period+1) e para médias exponenciais
+			if capacity := period*2 + 2; capacity > needed[interval] {
+				needed[interval] = capacity
+			}
+		}
+	}
+	return needed
+}
+
+// CheckCandleCapacity verifica se a série do intervalo dos sinais (CANDLE_CAPACITY)
+// tem mais candles que o maior período usado pelas regras nesse intervalo
+func (s *RuleStrategy) CheckCandleCapacity(signalInterval string, capacity int) error {
+	for _, program := range []*rules.Program{s.entry, s.exit} {
+		for _, call := range program.Calls() {
+			switch call.Name {
+			case "close", "volume", "delta", "trade_intensity", "large_trades", "cvd":
+				continue
+			}
+			interval := signalInterval
+			if len(call.Args) > 1 {
+				interval = call.Args[1].Str
+			}
+			if period := int(call.Args[0].Num); interval == signalInterval && capacity <= period {
+				return fmt.Errorf("CANDLE_CAPACITY (%d) deve ser maior que o período de %s(%d) na regra %q",
+					capacity, call.Name, period, program.Source())
+			}
+		}
+	}
+	return nil
+}
+
+// orderFlowWindow retorna a maior janela dos indicadores de fluxo de ordens usados
+// pelas regras (fallback nas chamadas sem janela); ok é false se nenhum é usado
+func (s *RuleStrategy) orderFlowWindow(fallback time.Duration) (window time.Duration, ok bool) {
+	for _, program := range []*rules.Program{s.entry, s.exit} {
+		for _, call := range program.Calls() {
+			switch call.Name {
+			case "delta", "trade_intensity", "large_trades", "cvd":
+				w := fallback
+				if len(call.Args) > 0 {
+					w = time.Duration(call.Args[0].Num) * time.Second
+				}
+				window, ok = max(window, w), true
+			}
+		}
+	}
+	return window, ok
+}
+
+// ruleContext fornece às regras os dados do trader no momento da avaliação. A
+// posição é copiada na criação, com o tradeMutex travado pelo chamador.
+type ruleContext struct {
+	t          *BTCTrader
+	price      float64
+	inPosition bool
+	entryPrice float64
+	quantity   float64
+}
+
+// newRuleContext copia a posição do trader; deve ser chamado com o tradeMutex travado
+func newRuleContext(t *BTCTrader, price float64) *ruleContext {
+	return &ruleContext{
+		t:          t,
+		price:      price,
+		inPosition: t.inPosition,
+		entryPrice: t.positions["BTC"],
+		quantity:   t.lastBuyQuantity,
+	}
+}
+
+func (c *ruleContext) Var(name string) (rules.Value, error) {
+	t := c.t
+	entryPrice := c.entryPrice
+	switch name {
+	case "price":
+		return rules.Value{Num: c.price}, nil
+	case "in_position":
+		return rules.Value{Bool: c.inPosition}, nil
+	case "entry_price":
+		return rules.Value{Num: entryPrice}, nil
+	case "quantity":
+		return rules.Value{Num: c.quantity}, nil
+	case "pnl_pct":
+		if !c.inPosition || entryPrice == 0 {
+			return rules.Value{Num: 0}, nil
+		}
+		return rules.Value{Num: (c.price - entryPrice) / entryPrice * 100}, nil
+	case "min_profit_price":
+		return rules.Value{Num: t.calculateMinProfitablePrice(entryPrice)}, nil
+	case "btc_balance", "usdt_balance":
+		btc, usdt, err := t.cachedBalances(context.Background())
+		if err != nil {
+			return rules.Value{}, err
+		}
+		if name == "btc_balance" {
+			return rules.Value{Num: btc}, nil
+		}
+		return rules.Value{Num: usdt}, nil
+	}
+	return rules.Value{}, fmt.Errorf("variável desconhecida %q", name)
+}
+
+func (c *ruleContext) Call(name string, args []rules.Value) (float64, error) {
+	interval := ""
+	period := 0
+	switch name {
+	case "close", "volume":
+		if len(args) > 0 {
+			interval = args[0].Str
+		}
+	case "delta", "trade_intensity", "large_trades", "cvd":
+		window := c.t.flowConfig.Window
+		if len(args) > 0 {
+			window = time.Duration(args[0].Num) * time.Second
+		}
+		return c.t.orderFlowValue(name, window)
+	default:
+		period = int(args[0].Num)
+		if len(args) > 1 {
+			interval = args[1].Str
+		}
+	}
+
+	candles := c.t.seriesCandles(interval)
+	if len(candles) == 0 {
+		return 0, rules.ErrNotReady
+	}
+	closes := make([]float64, len(candles))
+	for i, candle := range candles {
+		closes[i] = candle.Close
+	}
+
+	switch name {
+	case "close":
+		return closes[len(closes)-1], nil
+	case "volume":
+		return candles[len(candles)-1].Volume, nil
+	case "rsi":
+		if len(closes) <= period+1 {
+			return 0, rules.ErrNotReady
+		}
+		rsi, _, _ := relativeStrengthIndex(closes, period)
+		return rsi, nil
+	case "sma":
+		if len(closes) < period {
+			return 0, rules.ErrNotReady
+		}
+		return movingAverage(closes, period), nil
+	case "ema":
+		if len(closes) < period {
+			return 0, rules.ErrNotReady
+		}
+		return exponentialMovingAverage(closes, period), nil
+	case "change_pct":
+		if len(closes) <= period {
+			return 0, rules.ErrNotReady
+		}
+		base := closes[len(closes)-1-period]
+		return (closes[len(closes)-1] - base) / base * 100, nil
+	}
+	return 0, fmt.Errorf("função desconhecida %q", name)
+}
+
+// seriesCandles retorna os candles do intervalo (vazio = intervalo dos sinais)
+func (t *BTCTrader) seriesCandles(interval string) []Candle {
+	if interval == "" || interval == t.signalInterval {
+		return t.GetCandles()
+	}
+	t.tfMutex.RLock()
+	defer t.tfMutex.RUnlock()
+	if series, ok := t.timeframes[interval]; ok {
+		return series.Candles()
+	}
+	return nil
+}
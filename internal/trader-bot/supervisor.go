@@ -0,0 +1,63 @@
+package traderbot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/casarotto/binance-bot/internal/notify"
+)
+
+const (
+	supervisorMinBackoff = time.Second
+	supervisorMaxBackoff = time.Minute
+	// uma execução que dura mais que isso é considerada estável e zera o backoff
+	supervisorStableRun = 5 * time.Minute
+)
+
+// Run executa o trader sob um supervisor: se Start retornar (WebSocket encerrado,
+// erro ao conectar ou panic), ele é reiniciado com backoff exponencial. Retorna
+// quando o contexto é cancelado.
+func (t *BTCTrader) Run(ctx context.Context) {
+	backoff := supervisorMinBackoff
+	for {
+		started := time.Now()
+		err := t.runOnce(ctx)
+		if ctx.Err() != nil {
+			t.logImportant("🛑 Trader encerrado")
+			return
+		}
+
+		if time.Since(started) > supervisorStableRun {
+			backoff = supervisorMinBackoff
+		}
+		if err == nil {
+			err = fmt.Errorf("execução encerrada sem erro")
+		}
+		t.logWarn("🔁 Trader parou: %v - reiniciando em %s", err, backoff)
+		t.notify(notify.EventDisconnect, map[string]any{"error": err.Error(), "backoff": backoff.String()})
+
+		select {
+		case <-ctx.Done():
+			t.logImportant("🛑 Trader encerrado")
+			return
+		case <-time.After(backoff):
+		}
+		t.metrics.wsReconnects.Inc()
+
+		backoff *= 2
+		if backoff > supervisorMaxBackoff {
+			backoff = supervisorMaxBackoff
+		}
+	}
+}
+
+// runOnce chama Start convertendo um panic em erro para que o supervisor reinicie
+func (t *BTCTrader) runOnce(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return t.start(ctx.Done())
+}
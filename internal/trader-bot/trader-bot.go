@@ -6,12 +6,16 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"net/http"
 	"os"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/adshao/go-binance/v2"
+	"github.com/casarotto/binance-bot/internal/config"
+	"github.com/casarotto/binance-bot/internal/notify"
 )
 
 type Trade struct {
@@ -22,15 +26,23 @@ type Trade struct {
     ProfitLoss  float64 `json:"profit_loss,omitempty"`
     BTCBalance  float64 `json:"btc_balance"`    // Saldo de BTC após a operação
     USDTBalance float64 `json:"usdt_balance"`   // Saldo de USDT após a operação
+    Source      string  `json:"source,omitempty"`    // Origem da operação (strategy, stop_loss, webhook...)
+    SignalID    string  `json:"signal_id,omitempty"` // ID do alerta externo que gerou a operação
 }
 
 type BTCTrader struct {
     client     *binance.Client
-    prices     []float64
+    exchange   Exchange           // Corretora das ordens e dos dados de mercado (Binance ou simulada)
+    now        func() time.Time   // Relógio do trader (o da gravação durante o replay)
+    candles    *CandleSeries      // Candles do intervalo dos sinais
+    candlesMutex sync.RWMutex     // Mutex para proteger a série de candles
     positions  map[string]float64  // Preços de entrada das posições
     rsiPeriod  int
     maShort    int
     maLong     int
+    rsiBuy     float64            // Compra com RSI abaixo deste valor
+    rsiSell    float64            // Vende com RSI acima deste valor
+    minProfit  float64            // Lucro mínimo (%) para vender por sinal
     funds      float64            // Fundos disponíveis para trading
     inPosition bool
     lastTradeTime int64          // Timestamp da última operação
@@ -41,6 +53,48 @@ type BTCTrader struct {
     logger      *Logger         // Logger personalizado
     lastBuyQuantity float64    // Quantidade da última compra
     riskPerTrade   float64     // Porcentagem do capital a ser investido por trade (vem do .env)
+    dcaConfig   config.DCAConfig // Configuração do modo DCA
+    dca         *dcaCycle        // Ciclo de DCA em andamento (nil fora de posição)
+    dcaMutex    sync.Mutex       // Mutex para proteger o estado do DCA
+    gridConfig  config.GridConfig // Configuração da estratégia de grid
+    grid        gridState         // Estado dos grids
+    gridMutex   sync.Mutex        // Mutex para proteger o estado do grid
+    signalInterval string                      // Intervalo dos candles que geram os sinais
+    confirmations  []config.TimeframeRule      // Regras de confirmação em timeframes maiores
+    timeframes     map[string]*CandleSeries    // Séries de candles por intervalo
+    tfMutex        sync.RWMutex                // Mutex para proteger as séries dos timeframes
+    strategy       Strategy                    // Regras de entrada e saída do modo de sinais
+    balanceCache   balanceSnapshot             // Últimos saldos consultados (usados pelas regras)
+    balanceMutex   sync.Mutex                  // Mutex para proteger o cache de saldos
+    stopC          chan struct{}               // Fechado por Stop para encerrar a execução atual
+    runMutex       sync.Mutex                  // Mutex para proteger stopC
+    tradeMutex     sync.Mutex                  // Serializa o processamento dos ticks e os comandos externos
+    signalsPaused  atomic.Bool                 // Avaliação de sinais pausada pelo operador
+    stopLoss       float64                     // Queda máxima sobre o preço de entrada (0.02 = 2%)
+    metrics        *traderMetrics              // Métricas exportadas em /metrics
+    notifier       *notify.Notifier            // Avisos externos (webhook, e-mail, Telegram)
+    filters        symbolFilters               // Filtros de ordem do BTCUSDT (cache)
+    filtersMutex   sync.Mutex                  // Mutex para proteger o cache de filtros
+    equity         []EquityPoint               // Marcações recentes do patrimônio
+    equityFile     string                      // Arquivo onde as marcações são gravadas
+    equityInterval time.Duration               // Intervalo entre as marcações (0 = desabilitado)
+    equityMutex    sync.Mutex                  // Mutex para proteger as marcações
+    recorder       *Recorder                   // Gravação dos streams de mercado (nil = desabilitada)
+    depthConfig    config.DepthConfig          // Filtro de spread e liquidez das entradas
+    book           *OrderBook                  // Livro de ofertas local (nil com o filtro desabilitado)
+    depthLive      atomic.Bool                 // O depth stream está assinado
+    depthBlocked   string                      // Motivo do bloqueio atual das entradas pelo filtro
+    depthMutex     sync.Mutex                  // Mutex para proteger depthBlocked
+    flowConfig     config.OrderFlowConfig      // Indicadores de fluxo de ordens (aggTrade)
+    flow           *orderFlow                  // Negócios agregados recentes (nil = não usados)
+    flowLive       atomic.Bool                 // O stream de aggTrade está assinado
+    limiter        *rateLimiter                // Limitador das requisições REST à Binance (nil fora dela)
+    clockConfig    config.ClockConfig          // Sincronização com o horário da Binance
+    clock          ClockStatus                 // Estado da última sincronização
+    clockMutex     sync.Mutex                  // Mutex para proteger o estado da sincronização
+    retryConfig    config.OrderRetryConfig     // Novas tentativas das ordens com resultado desconhecido
+    orderSeq       atomic.Int64                // Sequência dos newClientOrderId
+    breaker        *circuitBreaker             // Suspende as compras após falhas seguidas no envio de ordens
 }
 
 type InitialPosition struct {
@@ -51,7 +105,7 @@ type InitialPosition struct {
 
 func (t *BTCTrader) loadCurrentPosition() error {
     // Buscar informações da conta
-    account, err := t.client.NewGetAccountService().Do(context.Background())
+    account, err := t.exchange.Account(context.Background())
     if err != nil {
         return fmt.Errorf("erro ao buscar informações da conta: %v", err)
     }
@@ -75,10 +129,8 @@ func (t *BTCTrader) loadCurrentPosition() error {
             // Se tiver BTC E a última operação não foi uma venda, estamos em posição
             if free > 0 && lastAction != "sell" {
                 // Buscar trades recentes para encontrar o preço médio
-                trades, err := t.client.NewListTradesService().
-                    Symbol("BTCUSDT").
-                    Limit(1000). // Limite máximo para ter certeza de pegar o trade mais recente
-                    Do(context.Background())
+                // Limite máximo para ter certeza de pegar o trade mais recente
+                trades, err := t.exchange.ListTrades(context.Background(), 1000)
                 if err != nil {
                     return fmt.Errorf("erro ao buscar trades: %v", err)
                 }
@@ -99,6 +151,8 @@ func (t *BTCTrader) loadCurrentPosition() error {
                 if lastBuyPrice > 0 {
                     t.inPosition = true
                     t.positions["BTC"] = lastBuyPrice
+                    // A posição é o saldo livre, arredondado ao passo da ordem
+                    t.lastBuyQuantity = math.Floor(free*100000) / 100000
                     log.Printf("Posição existente detectada - Quantidade: %.8f BTC, Preço de entrada: $%.2f", 
                         free, lastBuyPrice)
                 }
@@ -119,26 +173,64 @@ func (t *BTCTrader) loadCurrentPosition() error {
     return nil
 }
 
-func NewBTCTrader(apiKey, apiSecret string, testnet bool, historyFile string, riskPerTrade float64) *BTCTrader {
+func NewBTCTrader(apiKey, apiSecret string, testnet bool, historyFile string, riskPerTrade float64, candleCapacity int) *BTCTrader {
     binance.UseTestnet = testnet
     client := binance.NewClient(apiKey, apiSecret)
-    
+    return newBTCTrader(client, NewBinanceExchange(client), historyFile, riskPerTrade, candleCapacity)
+}
+
+// NewBTCTraderWithExchange cria um trader que opera na Exchange informada, por
+// exemplo a corretora simulada usada no replay de gravações
+func NewBTCTraderWithExchange(exchange Exchange, historyFile string, riskPerTrade float64, candleCapacity int) *BTCTrader {
+    return newBTCTrader(nil, exchange, historyFile, riskPerTrade, candleCapacity)
+}
+
+func newBTCTrader(client *binance.Client, exchange Exchange, historyFile string, riskPerTrade float64, candleCapacity int) *BTCTrader {
     trader := &BTCTrader{
         client:      client,
-        prices:      make([]float64, 0),
+        exchange:    exchange,
+        now:         time.Now,
+        candles:     NewCandleSeries(candleCapacity),
         positions:   make(map[string]float64),
         rsiPeriod:   14,
         maShort:     9,
         maLong:      21,
+        rsiBuy:      30,
+        rsiSell:     70,
+        minProfit:   0.3,
         inPosition:  false,
         takerFee:    0.001, // 0.1% por operação
         historyFile: historyFile,
         tradeHistory: make([]Trade, 0),
         riskPerTrade: riskPerTrade,
+        stopLoss:     0.02, // 2% stop loss
+        signalInterval: "1s",
+        timeframes:   make(map[string]*CandleSeries),
+        strategy:     rsiMAStrategy{},
+        flowConfig:   config.DefaultOrderFlowConfig(),
+        retryConfig:  config.DefaultOrderRetryConfig(),
+        breaker:      newCircuitBreaker(config.BreakerConfig{}),
+    }
+    trader.metrics = newTraderMetrics(trader)
+
+    // Transporte que limita as requisições e registra o peso informado pela Binance
+    if client != nil {
+        trader.breaker = newCircuitBreaker(config.DefaultBreakerConfig())
+        trader.limiter = newRateLimiter(config.DefaultRateLimitConfig())
+        trader.limiter.onThrottle = func(p Priority, action string) {
+            trader.metrics.throttled.Inc(p.String(), action)
+        }
+        trader.limiter.onBackoff = func(status int, retryAfter time.Duration) {
+            trader.logWarn("🚦 Binance respondeu HTTP %d: requisições suspensas por %s", status, retryAfter)
+            trader.notifyError("limite de requisições da Binance", fmt.Errorf("HTTP %d, nova tentativa em %s", status, retryAfter))
+        }
+        client.HTTPClient = &http.Client{
+            Transport: &weightTransport{base: http.DefaultTransport, metrics: trader.metrics, limiter: trader.limiter},
+        }
     }
 
     // Buscar saldo inicial da conta
-    account, err := client.NewGetAccountService().Do(context.Background())
+    account, err := exchange.Account(context.Background())
     if err != nil {
         log.Printf("Erro ao buscar saldo inicial: %v", err)
         trader.funds = 0
@@ -159,8 +251,8 @@ func NewBTCTrader(apiKey, apiSecret string, testnet bool, historyFile string, ri
 
     // Carregar histórico existente se o arquivo existir
     if _, err := os.Stat(historyFile); err == nil {
-        if data, err := os.ReadFile(historyFile); err == nil {
-            json.Unmarshal(data, &trader.tradeHistory)
+        if trades, err := LoadTradeHistory(historyFile); err == nil {
+            trader.tradeHistory = trades
             log.Printf("Histórico de trades carregado: %d operações encontradas", len(trader.tradeHistory))
         }
     }
@@ -173,6 +265,19 @@ func NewBTCTrader(apiKey, apiSecret string, testnet bool, historyFile string, ri
     return trader
 }
 
+// LoadTradeHistory lê um arquivo de histórico de trades (trade_history.json)
+func LoadTradeHistory(path string) ([]Trade, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("erro ao ler histórico de trades: %v", err)
+    }
+    trades := make([]Trade, 0)
+    if err := json.Unmarshal(data, &trades); err != nil {
+        return nil, fmt.Errorf("erro ao interpretar histórico de trades: %v", err)
+    }
+    return trades, nil
+}
+
 func (t *BTCTrader) saveTradeHistory() {
     t.historyMutex.Lock()
     defer t.historyMutex.Unlock()
@@ -193,65 +298,65 @@ func (t *BTCTrader) addTradeToHistory(trade Trade) {
     t.tradeHistory = append(t.tradeHistory, trade)
     t.historyMutex.Unlock()
 
-    // Salvar histórico em uma goroutine separada
-    go t.saveTradeHistory()
+    // Salvar histórico em uma goroutine separada (sem arquivo nos backtests)
+    if t.historyFile != "" {
+        go t.saveTradeHistory()
+    }
 }
 
+// log registra detalhes de depuração (cálculos a cada tick)
 func (t *BTCTrader) log(format string, v ...interface{}) {
 	if t.logger != nil {
-		t.logger.Printf(format, v...)
+		t.logger.Debugf(format, v...)
 	}
 }
 
+// logImportant registra eventos de operação (sinais, ordens, mudanças de estado)
 func (t *BTCTrader) logImportant(format string, v ...interface{}) {
 	if t.logger != nil {
-		t.logger.LogImportant(format, v...)
+		t.logger.Infof(format, v...)
+	}
+}
+
+func (t *BTCTrader) logWarn(format string, v ...interface{}) {
+	if t.logger != nil {
+		t.logger.Warnf(format, v...)
+	}
+}
+
+func (t *BTCTrader) logError(format string, v ...interface{}) {
+	if t.logger != nil {
+		t.logger.Errorf(format, v...)
 	}
 }
 
 func (t *BTCTrader) calculateRSI() float64 {
+	closes := t.closes()
+
 	// Precisamos de pelo menos rsiPeriod + 1 preços para calcular o RSI
-	if len(t.prices) <= t.rsiPeriod+1 {
-		t.log("RSI: Dados insuficientes. Precisamos de %d preços, temos %d", t.rsiPeriod+1, len(t.prices))
+	if len(closes) <= t.rsiPeriod+1 {
+		t.log("RSI: Dados insuficientes. Precisamos de %d preços, temos %d", t.rsiPeriod+1, len(closes))
 		return 50.0 // Valor neutro até termos dados suficientes
 	}
 
-	var gains, losses float64
-	for i := 1; i < t.rsiPeriod+1; i++ {
-		if len(t.prices)-i-1 < 0 {
-			t.log("RSI: Índice inválido detectado no cálculo")
-			return 50.0 // Proteção adicional contra índices negativos
-		}
-		change := t.prices[len(t.prices)-i] - t.prices[len(t.prices)-i-1]
-		if change >= 0 {
-			gains += change
-		} else {
-			losses -= change
-		}
-	}
-
+	rsi, gains, losses := relativeStrengthIndex(closes, t.rsiPeriod)
 	if losses == 0 {
 		t.log("RSI: Nenhuma perda detectada, RSI = 100")
-		return 100.0
+		return rsi
 	}
 
-	rs := gains / losses
-	rsi := 100.0 - (100.0 / (1.0 + rs))
 	t.log("RSI calculado: %.2f (Gains: %.2f, Losses: %.2f)", rsi, gains, losses)
 	return rsi
 }
 
 func (t *BTCTrader) calculateMA(period int) float64 {
-	if len(t.prices) < period {
-		t.log("MA%d: Dados insuficientes. Precisamos de %d preços, temos %d", period, period, len(t.prices))
+	closes := t.closes()
+	if len(closes) < period {
+		t.log("MA%d: Dados insuficientes. Precisamos de %d preços, temos %d", period, period, len(closes))
 		return 0
 	}
 
-	sum := 0.0
-	for i := 0; i < period; i++ {
-		sum += t.prices[len(t.prices)-1-i]
-	}
-	ma := sum / float64(period)
+	ma := movingAverage(closes, period)
 	t.log("MA%d calculada: %.2f", period, ma)
 	return ma
 }
@@ -265,69 +370,84 @@ func (t *BTCTrader) calculateMinProfitablePrice(entryPrice float64) float64 {
     return entryPrice * (1 + totalFees + minProfitMargin)
 }
 
+// closes retorna os fechamentos da série de candles dos sinais
+func (t *BTCTrader) closes() []float64 {
+    t.candlesMutex.RLock()
+    defer t.candlesMutex.RUnlock()
+    return t.candles.Closes()
+}
+
+// candleCount retorna quantos candles dos sinais estão armazenados
+func (t *BTCTrader) candleCount() int {
+    t.candlesMutex.RLock()
+    defer t.candlesMutex.RUnlock()
+    return t.candles.Len()
+}
+
 // hasEnoughData verifica se há dados suficientes para calcular todos os indicadores
 func (t *BTCTrader) hasEnoughData() bool {
-    return len(t.prices) > t.maLong && len(t.prices) > t.rsiPeriod+1
+    n := t.candleCount()
+    return n > t.maLong && n > t.rsiPeriod+1
 }
 
-func (t *BTCTrader) shouldTrade(price float64) (string, bool) {
-    t.log("\n=== Nova análise de trading ===")
-    t.log("Preço atual: $%.2f", price)
-    
+// addCandle valida e adiciona um candle à série dos sinais. Eventos do mesmo candle
+// (intervalos maiores que 1s) atualizam o último candle em vez de adicionar um novo.
+func (t *BTCTrader) addCandle(candle Candle) bool {
+    t.candlesMutex.Lock()
+    defer t.candlesMutex.Unlock()
+
     // Validação do preço - ignorar valores muito discrepantes (±30% do último preço)
-    if len(t.prices) > 0 {
-        lastPrice := t.prices[len(t.prices)-1]
-        priceChange := math.Abs((price - lastPrice) / lastPrice * 100)
+    if last, ok := t.candles.Last(); ok {
+        priceChange := math.Abs((candle.Close - last.Close) / last.Close * 100)
         if priceChange > 30 {
-            t.logImportant("⚠️ Preço ignorado por estar muito discrepante (%.2f%% de variação)", priceChange)
-            return "", false
+            t.logWarn("⚠️ Preço ignorado por estar muito discrepante (%.2f%% de variação)", priceChange)
+            return false
         }
     }
     
-    // Adicionar novo preço ao histórico
-    t.prices = append(t.prices, price)
-    if len(t.prices) > 100 { // Manter histórico limitado
-        t.prices = t.prices[1:]
-    }
-
-    // Verificar se temos dados suficientes para todos os indicadores
-    if !t.hasEnoughData() {
-        t.log("Aguardando dados suficientes para indicadores (MA21: %d/%d, RSI: %d/%d)",
-            len(t.prices), t.maLong,
-            len(t.prices), t.rsiPeriod+1)
-        return "", false
-    }
-
-    // Calcular indicadores
-    rsi := t.calculateRSI()
-    maShort := t.calculateMA(t.maShort)
-    maLong := t.calculateMA(t.maLong)
+    return t.candles.Add(candle)
+}
 
-    // Se algum indicador retornou valor neutro/inválido, não operar
-    if rsi == 50.0 || maShort == 0 || maLong == 0 {
+func (t *BTCTrader) shouldTrade(candle Candle) (string, bool) {
+    price := candle.Close
+    t.log("\n=== Nova análise de trading ===")
+    t.log("Preço atual: $%.2f", price)
+    
+    if !t.addCandle(candle) {
         return "", false
     }
 
     // Regras de Trading
     if !t.inPosition {
-        if rsi < 30 && maShort > maLong {
-            t.logImportant("✅ Sinal de COMPRA - RSI: %.2f, MA9: %.2f, MA21: %.2f", rsi, maShort, maLong)
+        if ok, reason := t.strategy.ShouldEnter(t, price); ok {
+            if !t.higherTimeframesConfirm() {
+                t.log("Sinal de compra bloqueado pela confirmação multi-timeframe")
+                return "", false
+            }
+            if !t.orderFlowConfirms() {
+                return "", false
+            }
+            if err := t.checkLiquidity(t.GetNextTradeAmount() / price); err != nil {
+                t.log("Sinal de compra bloqueado pelo filtro de liquidez: %v", err)
+                return "", false
+            }
+            if t.entriesBlocked() {
+                t.log("Sinal de compra bloqueado pelo circuit breaker")
+                return "", false
+            }
+            t.logImportant("✅ Sinal de COMPRA - %s", reason)
             return "buy", true
         }
     } else {
-        entryPrice := t.positions["BTC"]
-        // Calcular lucro considerando a quantidade correta
-        entryValue := entryPrice * t.lastBuyQuantity
-        currentValue := price * t.lastBuyQuantity
-        currentProfit := (currentValue - entryValue) / entryValue * 100
-        
-        if price < t.calculateMinProfitablePrice(entryPrice) {
+        if t.dcaConfig.Enabled {
+            if t.dcaShouldSell(price) {
+                return "sell", true
+            }
             return "", false
         }
 
-        if (rsi > 70 || (maShort < maLong && rsi > 50)) && currentProfit >= 0.3 {
-            t.logImportant("✅ Sinal de VENDA - RSI: %.2f, MA9: %.2f, MA21: %.2f, Lucro: %.2f%%", 
-                rsi, maShort, maLong, currentProfit)
+        if ok, reason := t.strategy.ShouldExit(t, price); ok {
+            t.logImportant("✅ Sinal de VENDA - %s", reason)
             return "sell", true
         }
     }
@@ -340,21 +460,27 @@ func (t *BTCTrader) checkStopLoss(currentPrice float64) bool {
         return false
     }
 
+    // No modo DCA as quedas são absorvidas pelas ordens de segurança; o stop
+    // só passa a valer sobre o preço médio depois que a escada foi esgotada
+    if t.dcaConfig.Enabled && !t.dcaLadderExhausted() {
+        return false
+    }
+
     entryPrice := t.positions["BTC"]
-    stopLossPercent := 0.02 // 2% stop loss
-    stopLossPrice := entryPrice * (1 - stopLossPercent)
+    stopLossPrice := entryPrice * (1 - t.stopLoss)
 
     if currentPrice < stopLossPrice {
         loss := (currentPrice-entryPrice)/entryPrice*100
-        t.logImportant("⚠️ Stop Loss atingido! Perda: %.2f%%", loss)
+        t.logWarn("⚠️ Stop Loss atingido! Perda: %.2f%%", loss)
         return true
     }
 
     return false
 }
 
-func (t *BTCTrader) getBalances() (btcBalance, usdtBalance float64, err error) {
-    account, err := t.client.NewGetAccountService().Do(context.Background())
+// getBalances consulta os saldos livres; ctx define a prioridade da requisição
+func (t *BTCTrader) getBalances(ctx context.Context) (btcBalance, usdtBalance float64, err error) {
+    account, err := t.exchange.Account(ctx)
     if err != nil {
         return 0, 0, fmt.Errorf("erro ao buscar saldos: %v", err)
     }
@@ -377,111 +503,105 @@ func (t *BTCTrader) getBalances() (btcBalance, usdtBalance float64, err error) {
     return btcBalance, usdtBalance, nil
 }
 
-func (t *BTCTrader) executeTrade(action string, price float64) error {
+// balanceSnapshot guarda saldos consultados para evitar uma requisição por tick
+type balanceSnapshot struct {
+    btc       float64
+    usdt      float64
+    fetchedAt time.Time
+}
+
+// cachedBalances retorna os saldos consultados há menos de 5 segundos ou consulta novamente
+func (t *BTCTrader) cachedBalances(ctx context.Context) (btcBalance, usdtBalance float64, err error) {
+    t.balanceMutex.Lock()
+    defer t.balanceMutex.Unlock()
+
+    if t.now().Sub(t.balanceCache.fetchedAt) < 5*time.Second {
+        return t.balanceCache.btc, t.balanceCache.usdt, nil
+    }
+
+    btcBalance, usdtBalance, err = t.getBalances(ctx)
+    if err != nil {
+        return 0, 0, err
+    }
+    t.balanceCache = balanceSnapshot{btc: btcBalance, usdt: usdtBalance, fetchedAt: t.now()}
+    return btcBalance, usdtBalance, nil
+}
+
+// executeTrade envia a ordem a mercado e retorna a operação registrada no histórico
+func (t *BTCTrader) executeTrade(action string, price float64, signal tradeSignal) (Trade, error) {
     var quantity float64
     
     if action == "buy" {
-        quantity = t.calculateTradeQuantity(price)
+        quantity = signal.quantity
+        if quantity == 0 {
+            risk := t.riskPerTrade
+            if signal.sizePct > 0 {
+                risk = signal.sizePct
+            }
+            quantity = t.calculateTradeQuantity(price, risk)
+        }
         t.lastBuyQuantity = quantity // Armazena a quantidade comprada
     } else {
         quantity = t.lastBuyQuantity // Usa a mesma quantidade da última compra
     }
     
     if quantity == 0 {
-        t.logImportant("❌ Quantidade inválida para %s", action)
-        return fmt.Errorf("quantidade inválida")
+        t.logError("❌ Quantidade inválida para %s", action)
+        return Trade{}, fmt.Errorf("quantidade inválida")
     }
     
+    var trade Trade
     if action == "buy" {
-        order, err := t.client.NewCreateOrderService().
-            Symbol("BTCUSDT").
-            Side(binance.SideTypeBuy).
-            Type(binance.OrderTypeMarket).
-            Quantity(fmt.Sprintf("%.5f", quantity)).
-            Do(context.Background())
-            
+        fill, err := t.placeMarketOrder(binance.SideTypeBuy, quantity, price)
         if err != nil {
-            t.logImportant("❌ Erro ao executar compra: %v", err)
-            return err
+            t.logError("❌ Erro ao executar compra: %v", err)
+            return Trade{}, err
         }
         
         t.inPosition = true
-        t.positions["BTC"] = price
-
-        // Buscar saldos atualizados
-        btcBalance, usdtBalance, err := t.getBalances()
-        if err != nil {
-            t.log("Aviso: Não foi possível obter saldos atualizados: %v", err)
+        t.positions["BTC"] = fill.Price
+        t.lastBuyQuantity = fill.Quantity
+        if t.dcaConfig.Enabled {
+            t.startDCACycle(fill.Price, fill.Quantity)
         }
 
         // Registrar trade no histórico
-        trade := Trade{
-            Timestamp:   time.Now().Unix(),
-            Action:      "buy",
-            Price:       price,
-            Quantity:    quantity,
-            BTCBalance:  btcBalance,
-            USDTBalance: usdtBalance,
-        }
-        t.addTradeToHistory(trade)
+        trade = t.recordFill(fill, 0, signal)
         
-        t.logImportant("💰 Compra executada - Preço: $%.2f, Quantidade: %.5f BTC", price, quantity)
-        t.log("Saldos após compra - BTC: %.8f, USDT: %.2f", btcBalance, usdtBalance)
-        t.log("Ordem: %+v", order)
+        t.logImportant("💰 Compra executada - Preço: $%.2f, Quantidade: %.5f BTC", fill.Price, fill.Quantity)
         
     } else if action == "sell" {
-        order, err := t.client.NewCreateOrderService().
-            Symbol("BTCUSDT").
-            Side(binance.SideTypeSell).
-            Type(binance.OrderTypeMarket).
-            Quantity(fmt.Sprintf("%.5f", quantity)).
-            Do(context.Background())
-            
+        fill, err := t.placeMarketOrder(binance.SideTypeSell, quantity, price)
         if err != nil {
-            t.logImportant("❌ Erro ao executar venda: %v", err)
-            return err
+            t.logError("❌ Erro ao executar venda: %v", err)
+            return Trade{}, err
         }
         
         // Calcular lucro/prejuízo antes de limpar a posição
         entryPrice := t.positions["BTC"]
-        profitLoss := (price - entryPrice) / entryPrice * 100
+        profitLoss := (fill.Price - entryPrice) / entryPrice * 100
 
         t.inPosition = false
         delete(t.positions, "BTC")
-
-        // Buscar saldos atualizados
-        btcBalance, usdtBalance, err := t.getBalances()
-        if err != nil {
-            t.log("Aviso: Não foi possível obter saldos atualizados: %v", err)
-        }
+        t.endDCACycle()
 
         // Registrar trade no histórico
-        trade := Trade{
-            Timestamp:   time.Now().Unix(),
-            Action:      "sell",
-            Price:       price,
-            Quantity:    quantity,
-            ProfitLoss:  profitLoss,
-            BTCBalance:  btcBalance,
-            USDTBalance: usdtBalance,
-        }
-        t.addTradeToHistory(trade)
+        trade = t.recordFill(fill, profitLoss, signal)
         
         t.logImportant("💰 Venda executada - Preço: $%.2f, Quantidade: %.5f BTC, Lucro: %.2f%%", 
-            price, quantity, profitLoss)
-        t.log("Saldos após venda - BTC: %.8f, USDT: %.2f", btcBalance, usdtBalance)
-        t.log("Ordem: %+v", order)
+            fill.Price, fill.Quantity, profitLoss)
     }
     
-    return nil
+    return trade, nil
 }
 
-func (t *BTCTrader) calculateTradeQuantity(price float64) float64 {
+// calculateTradeQuantity converte a fração do capital (riskPerTrade) em quantidade de BTC
+func (t *BTCTrader) calculateTradeQuantity(price, riskPerTrade float64) float64 {
     // Valor mínimo da ordem na Binance (11 USDT para garantir)
     minOrderValue := 11.0
 
     // Calcular quantidade baseada no risco definido no .env
-    tradeAmount := t.funds * t.riskPerTrade
+    tradeAmount := t.capDCABaseAmount(t.funds * riskPerTrade)
 
     // Garantir que o valor da ordem seja pelo menos o mínimo
     if tradeAmount < minOrderValue {
@@ -489,14 +609,14 @@ func (t *BTCTrader) calculateTradeQuantity(price float64) float64 {
     }
 
     // Se o saldo disponível for menor que o valor mínimo, usar todo o saldo
-    _, usdtBalance, err := t.getBalances()
+    _, usdtBalance, err := t.getBalances(WithPriority(context.Background(), PriorityCritical))
     if err == nil && !t.inPosition && usdtBalance < tradeAmount {
         tradeAmount = usdtBalance
     }
 
     // Se mesmo assim o valor for menor que o mínimo, não executar
     if tradeAmount < minOrderValue {
-        t.logImportant("⚠️ Saldo insuficiente para atingir o valor mínimo de ordem (%.2f USDT)", minOrderValue)
+        t.logWarn("⚠️ Saldo insuficiente para atingir o valor mínimo de ordem (%.2f USDT)", minOrderValue)
         return 0
     }
 
@@ -508,63 +628,255 @@ func (t *BTCTrader) calculateTradeQuantity(price float64) float64 {
 
     // Verificar se o valor total da ordem atende ao mínimo
     if quantity * price < minOrderValue {
-        t.logImportant("⚠️ Valor total da ordem (%.2f USDT) abaixo do mínimo permitido (%.2f USDT)", quantity * price, minOrderValue)
+        t.logWarn("⚠️ Valor total da ordem (%.2f USDT) abaixo do mínimo permitido (%.2f USDT)", quantity * price, minOrderValue)
         return 0
     }
 
     return quantity
 }
 
+// Start conecta os WebSockets e opera até Stop ser chamado ou até um WebSocket ser
+// encerrado, caso em que retorna um erro. Para reconectar automaticamente use Run.
 func (t *BTCTrader) Start() error {
+    return t.start(nil)
+}
+
+// start é o Start que também retorna quando done é fechado
+func (t *BTCTrader) start(done <-chan struct{}) error {
     wsHandler := func(event *binance.WsKlineEvent) {
-        price, _ := strconv.ParseFloat(event.Kline.Close, 64)
+        candle, err := CandleFromWsKline(event.Kline)
+        if err != nil {
+            t.log("Kline inválido ignorado: %v", err)
+            return
+        }
+        price := candle.Close
+        t.metrics.recordEventTime(event.Time)
+        t.updateTimeframe(event.Kline.Interval, candle)
+
+        t.tradeMutex.Lock()
+        defer t.tradeMutex.Unlock()
+        
+        // Estratégia de grid: as ordens limitadas ficam no livro e só acompanhamos as execuções
+        if t.gridConfig.Enabled {
+            if t.addCandle(candle) && !t.signalsPaused.Load() {
+                t.onGridTick(price)
+            }
+            return
+        }
         
         // Verificar stop loss
         if t.checkStopLoss(price) {
-            t.logImportant("Stop Loss atingido! Executando venda...")
-            t.executeTrade("sell", price)
+            t.logWarn("Stop Loss atingido! Executando venda...")
+            trade, err := t.executeTrade("sell", price, tradeSignal{source: SourceStopLoss})
+            if err != nil {
+                t.notifyError("erro ao vender no stop loss", err)
+                return
+            }
+            t.notifyTrade(notify.EventStop, trade, nil)
             return
         }
         
+        // Com os sinais pausados os candles continuam sendo coletados e o stop loss continua valendo
+        if t.signalsPaused.Load() {
+            t.addCandle(candle)
+            return
+        }
+
         // Verificar sinais de trading
-        action, shouldTrade := t.shouldTrade(price)
+        action, shouldTrade := t.shouldTrade(candle)
         if shouldTrade {
             t.logImportant("Executando %s...", action)
-            err := t.executeTrade(action, price)
+            trade, err := t.executeTrade(action, price, tradeSignal{source: SourceStrategy})
             if err != nil {
-                t.logImportant("❌ Erro ao executar %s: %v", action, err)
+                t.logError("❌ Erro ao executar %s: %v", action, err)
+                t.notifyError("erro ao executar "+action, err)
+                return
             }
+            if action == "buy" {
+                t.notifyTrade(notify.EventEntry, trade, nil)
+            } else {
+                t.notifyTrade(notify.EventExit, trade, nil)
+            }
+            return
         }
+
+        // Verificar ordens de segurança do ciclo DCA
+        t.checkSafetyOrders(price)
     }
 
     errHandler := func(err error) {
-        t.logImportant("❌ Erro no WebSocket: %v", err)
+        t.logError("❌ Erro no WebSocket: %v", err)
     }
 
-    // Iniciar WebSocket para BTCUSDT com intervalo de 1 minuto
-    _, _, err := binance.WsKlineServe("BTCUSDT", "1s", wsHandler, errHandler)
+    stopC := make(chan struct{})
+    t.runMutex.Lock()
+    t.stopC = stopC
+    t.runMutex.Unlock()
+
+    // Iniciar os WebSockets dos timeframes de confirmação
+    streams, err := t.startTimeframeStreams(errHandler)
     if err != nil {
+        stopStreams(streams)
+        return err
+    }
+
+    // Assinar o livro de ofertas do filtro de liquidez, se habilitado
+    streams = append(streams, t.startDepthStream(errHandler)...)
+
+    // Assinar os negócios agregados dos indicadores de fluxo de ordens, se usados
+    streams = append(streams, t.startOrderFlowStream(errHandler)...)
+
+    // Iniciar WebSocket para BTCUSDT no intervalo dos sinais
+    doneC, wsStopC, err := t.klineStream(t.signalInterval, wsHandler, errHandler)
+    if err != nil {
+        stopStreams(streams)
         return fmt.Errorf("erro ao iniciar WebSocket: %v", err)
     }
+    streams = append(streams, wsStream{interval: t.signalInterval, done: doneC, stop: wsStopC})
+
+    // Manter o bot rodando até Stop ou até algum WebSocket ser encerrado
+    closed := make(chan string, len(streams))
+    for _, s := range streams {
+        go func(s wsStream) {
+            <-s.done
+            closed <- s.interval
+        }(s)
+    }
+
+    select {
+    case <-stopC:
+        stopStreams(streams)
+        return nil
+    case <-done:
+        stopStreams(streams)
+        return nil
+    case interval := <-closed:
+        stopStreams(streams)
+        return fmt.Errorf("WebSocket de %s encerrado", interval)
+    }
+}
+
+// Stop encerra a execução atual de Start
+func (t *BTCTrader) Stop() {
+    t.runMutex.Lock()
+    defer t.runMutex.Unlock()
+    if t.stopC != nil {
+        close(t.stopC)
+        t.stopC = nil
+    }
+}
 
-    // Manter o bot rodando
-    select {}
+// wsStream guarda os canais de um WebSocket de klines aberto
+type wsStream struct {
+    interval string
+    done     chan struct{}
+    stop     chan struct{}
+}
+
+// stopStreams pede o encerramento dos WebSockets que ainda estão abertos
+func stopStreams(streams []wsStream) {
+    for _, s := range streams {
+        select {
+        case <-s.done:
+        default:
+            close(s.stop)
+        }
+    }
 }
 
-// SetInitialPosition configura a posição inicial do trader
+// SetInitialPosition configura a posição inicial do trader. Em posição, a quantidade
+// é o saldo livre de BTC da conta.
 func (t *BTCTrader) SetInitialPosition(inPosition bool, entryPrice float64) {
+    t.setInitialPosition(inPosition, entryPrice, 0)
+}
+
+// setInitialPosition é o SetInitialPosition com a quantidade da posição (0 = saldo livre
+// de BTC). Pode ser chamado com o trader em execução (TUI remota), então segura o
+// tradeMutex como as operações.
+func (t *BTCTrader) setInitialPosition(inPosition bool, entryPrice, quantity float64) {
+    t.tradeMutex.Lock()
+    defer t.tradeMutex.Unlock()
+
     t.inPosition = inPosition
     if inPosition {
         t.positions["BTC"] = entryPrice
-        t.logImportant("Posição inicial configurada - Em posição com entrada em $%.2f", entryPrice)
+        if quantity <= 0 {
+            quantity = t.heldQuantity()
+        }
+        t.lastBuyQuantity = quantity
+        if t.dcaConfig.Enabled {
+            t.startDCACycle(entryPrice, t.lastBuyQuantity)
+        }
+        t.logImportant("Posição inicial configurada - Em posição com %.5f BTC e entrada em $%.2f", t.lastBuyQuantity, entryPrice)
     } else {
         delete(t.positions, "BTC")
+        t.endDCACycle()
         t.logImportant("Posição inicial configurada - Fora do mercado")
     }
 }
 
-// GetClient retorna o cliente da Binance
+// heldQuantity retorna o saldo livre de BTC arredondado para 5 casas decimais
+// (0 se a consulta falhar)
+func (t *BTCTrader) heldQuantity() float64 {
+    btc, _, err := t.getBalances(WithPriority(context.Background(), PriorityCritical))
+    if err != nil {
+        t.logWarn("⚠️ Não foi possível consultar o saldo de BTC da posição: %v", err)
+        return 0
+    }
+    return math.Floor(btc*100000) / 100000
+}
+
+// ApplyInitialPosition decide a posição inicial sem interação (modo headless).
+// "auto" mantém a posição reconciliada com a conta na criação do trader, "flat"
+// começa fora do mercado e "long" começa em posição com entryPrice ou, se zero,
+// com o preço da última compra do histórico. A quantidade de "long" é quantity ou,
+// se zero, o saldo livre de BTC.
+func (t *BTCTrader) ApplyInitialPosition(mode string, entryPrice, quantity float64) error {
+    switch mode {
+    case "auto":
+        if t.inPosition {
+            t.setInitialPosition(true, t.positions["BTC"], t.lastBuyQuantity)
+        } else {
+            t.SetInitialPosition(false, 0)
+        }
+    case "flat":
+        t.SetInitialPosition(false, 0)
+    case "long":
+        if entryPrice <= 0 {
+            entryPrice = t.lastBuyPrice()
+        }
+        if entryPrice <= 0 {
+            return fmt.Errorf("INITIAL_POSITION=long sem INITIAL_ENTRY_PRICE e sem compras no histórico")
+        }
+        t.setInitialPosition(true, entryPrice, quantity)
+    default:
+        return fmt.Errorf("modo de posição inicial inválido: %s", mode)
+    }
+    // Sem a quantidade o bot não conseguiria vender sozinho
+    if t.inPosition && t.lastBuyQuantity == 0 {
+        return fmt.Errorf("posição inicial sem quantidade: sem saldo de BTC e sem INITIAL_QUANTITY")
+    }
+    return nil
+}
+
+// lastBuyPrice retorna o preço da última compra do histórico (0 se não houver)
+func (t *BTCTrader) lastBuyPrice() float64 {
+    t.historyMutex.Lock()
+    defer t.historyMutex.Unlock()
+    for i := len(t.tradeHistory) - 1; i >= 0; i-- {
+        if t.tradeHistory[i].Action == "buy" {
+            return t.tradeHistory[i].Price
+        }
+    }
+    return 0
+}
+
+// GetClient retorna o cliente da Binance (nil com uma Exchange simulada), já com o
+// TimeOffset da última sincronização do relógio
 func (t *BTCTrader) GetClient() *binance.Client {
+    if exchange, ok := t.exchange.(*binanceExchange); ok {
+        return exchange.api()
+    }
     return t.client
 }
 
@@ -580,7 +892,7 @@ func (t *BTCTrader) GetTotalFunds() float64 {
 
 // UpdateTotalFunds atualiza o total de fundos disponíveis
 func (t *BTCTrader) UpdateTotalFunds() error {
-    _, usdtBalance, err := t.getBalances()
+    _, usdtBalance, err := t.getBalances(WithPriority(context.Background(), PriorityUI))
     if err != nil {
         return err
     }
@@ -590,5 +902,5 @@ func (t *BTCTrader) UpdateTotalFunds() error {
 
 // GetNextTradeAmount retorna o valor que será usado na próxima operação
 func (t *BTCTrader) GetNextTradeAmount() float64 {
-    return t.funds * t.riskPerTrade
+    return t.capDCABaseAmount(t.funds * t.riskPerTrade)
 }
\ No newline at end of file
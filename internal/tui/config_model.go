@@ -69,8 +69,8 @@ func (m ConfigModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	case tickMsg:
 		// Atualizar preço atual
-		if len(m.trader.GetPrices()) > 0 {
-			m.lastPrice = m.trader.GetPrices()[len(m.trader.GetPrices())-1]
+		if candle, ok := m.trader.GetLastCandle(); ok {
+			m.lastPrice = candle.Close
 		}
 		return m, tickCmd()
 	}
@@ -3,11 +3,13 @@ package tui
 import (
 	"fmt"
 	"image"
+	"log/slog"
 	"os"
 	"strings"
 	"time"
 
-	"github.com/adshao/go-binance/v2"
+	"github.com/casarotto/binance-bot/internal/config"
+	"github.com/casarotto/binance-bot/internal/report"
 	traderbot "github.com/casarotto/binance-bot/internal/trader-bot"
 	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
@@ -72,10 +74,15 @@ var (
 // Mensagem para atualizar os dados
 type tickMsg time.Time
 
+// Abas da tela principal
+var tabNames = []string{"Principal", "Histórico", "Desempenho"}
+
 // Modelo principal do TUI
 type Model struct {
-	trader      *traderbot.BTCTrader
+	trader      Trader
 	lastPrice   float64
+	lastCandle  traderbot.Candle
+	candleCount int
 	rsi         float64
 	maShort     float64
 	maLong      float64
@@ -89,15 +96,17 @@ type Model struct {
 	showConfig  bool   // Controla se está mostrando a tela de configuração
 	ready       bool
 	lastUpdate  time.Time
+	logLevel    slog.Level // nível mínimo exibido no painel de logs
 }
 
-func New(trader *traderbot.BTCTrader) *Model {
+func New(trader Trader) *Model {
 	columns := []table.Column{
 		{Title: "Timestamp", Width: 20},
 		{Title: "Ação", Width: 10},
 		{Title: "Preço", Width: 15},
 		{Title: "Quantidade", Width: 15},
 		{Title: "Lucro/Perda", Width: 15},
+		{Title: "Origem", Width: 12},
 	}
 
 	t := table.New(
@@ -126,6 +135,14 @@ func New(trader *traderbot.BTCTrader) *Model {
 	}
 }
 
+// SetLogLevel define o nível mínimo do painel de logs (debug, info, warn ou error)
+func (m *Model) SetLogLevel(name string) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err == nil {
+		m.logLevel = level
+	}
+}
+
 func (m Model) Init() tea.Cmd {
 	return tea.Batch(
 		tickCmd(),
@@ -148,11 +165,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		case "tab", "right", "l":
 			if !m.showConfig {
-				m.currentTab = (m.currentTab + 1) % 2
+				m.currentTab = (m.currentTab + 1) % len(tabNames)
 			}
 		case "shift+tab", "left", "h":
 			if !m.showConfig {
-				m.currentTab = (m.currentTab - 1 + 2) % 2
+				m.currentTab = (m.currentTab - 1 + len(tabNames)) % len(tabNames)
 			}
 		case "c":
 			if !m.showConfig {
@@ -172,6 +189,25 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.showConfig {
 				m.showConfig = false
 			}
+		case "p":
+			if m.trader.IsSignalsPaused() {
+				m.trader.ResumeSignals()
+			} else {
+				m.trader.PauseSignals()
+			}
+		case "v":
+			// Alterna o nível do painel de logs: DEBUG -> INFO -> WARN -> ERROR
+			m.logLevel += 4
+			if m.logLevel > slog.LevelError {
+				m.logLevel = slog.LevelDebug
+			}
+		case "X":
+			// Maiúsculo para evitar fechamentos acidentais
+			m.err = m.trader.ForceClose()
+		case "b":
+			if m.trader.GetBreaker().Open {
+				m.trader.ResetBreaker()
+			}
 		}
 
 	case tickMsg:
@@ -193,9 +229,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m *Model) updateData() {
+	// O cliente remoto informa quando a conexão com o bot cai
+	if remote, ok := m.trader.(interface{ Err() error }); ok && remote.Err() != nil {
+		m.err = remote.Err()
+	}
+
 	// Atualizar preço e indicadores
-	if len(m.trader.GetPrices()) > 0 {
-		m.lastPrice = m.trader.GetPrices()[len(m.trader.GetPrices())-1]
+	if candle, ok := m.trader.GetLastCandle(); ok {
+		m.lastCandle = candle
+		m.lastPrice = candle.Close
+		m.candleCount = m.trader.GetCandleCount()
 		m.rsi = m.trader.CalculateRSI()
 		m.maShort = m.trader.CalculateMA(m.trader.GetMAShortPeriod())
 		m.maLong = m.trader.CalculateMA(m.trader.GetMALongPeriod())
@@ -204,7 +247,10 @@ func (m *Model) updateData() {
 	// Atualizar posição e saldos
 	m.inPosition = m.trader.IsInPosition()
 	m.entryPrice = m.trader.GetEntryPrice()
-	m.btcBalance, m.usdtBalance, _ = m.trader.GetBalances()
+	// Perto do limite de requisições a consulta é descartada; mantém os últimos saldos
+	if btc, usdt, err := m.trader.GetBalances(); err == nil {
+		m.btcBalance, m.usdtBalance = btc, usdt
+	}
 
 	// Atualizar histórico de trades
 	trades := m.trader.GetTradeHistory()
@@ -216,11 +262,20 @@ func (m *Model) updateData() {
 			fmt.Sprintf("$%.2f", trade.Price),
 			fmt.Sprintf("%.8f", trade.Quantity),
 			fmt.Sprintf("$%.2f", trade.ProfitLoss),
+			tradeSource(trade),
 		}
 	}
 	m.table.SetRows(rows)
 }
 
+// tradeSource retorna a origem da operação; trades antigos não têm origem registrada
+func tradeSource(trade traderbot.Trade) string {
+	if trade.Source == "" {
+		return "-"
+	}
+	return trade.Source
+}
+
 func (m Model) updateFunds() tea.Msg {
 	m.trader.UpdateTotalFunds()
 	return nil
@@ -256,6 +311,270 @@ func (m Model) formatMA(period int) string {
 	return fmt.Sprintf("%.2f", value)
 }
 
+// formatEquity mostra o gráfico das marcações do patrimônio com o valor atual e a
+// queda em relação ao pico, visível mesmo com a posição aberta
+func (m Model) formatEquity(width int) string {
+	curve := m.trader.GetEquityCurve()
+	if len(curve) < 2 {
+		return loadingStyle.Render("Aguardando marcações do patrimônio...")
+	}
+
+	// O pico considera todas as marcações carregadas, não só as que cabem no gráfico
+	var peak float64
+	for _, point := range curve {
+		if point.Equity > peak {
+			peak = point.Equity
+		}
+	}
+	if len(curve) > width {
+		curve = curve[len(curve)-width:]
+	}
+	values := make([]float64, len(curve))
+	for i, point := range curve {
+		values[i] = point.Equity
+	}
+	current := values[len(values)-1]
+	drawdown := 0.0
+	if peak > 0 {
+		drawdown = (peak - current) / peak * 100
+	}
+
+	drawdownText := positiveStyle.Render("0.00%")
+	if drawdown > 0 {
+		drawdownText = negativeStyle.Render(fmt.Sprintf("-%.2f%%", drawdown))
+	}
+	return fmt.Sprintf("Atual: %s | Drawdown: %s\n%s",
+		priceStyle.Render(fmt.Sprintf("$%.2f", current)),
+		drawdownText,
+		createPriceChart(values, width, 1),
+	)
+}
+
+func chartOrLoading(chart string) string {
+	if chart == "" {
+		return loadingStyle.Render("Carregando...")
+	}
+	return chart
+}
+
+// formatReport calcula as estatísticas de desempenho de todo o histórico de trades
+func (m Model) formatReport() string {
+	r, err := report.Compute(m.trader.GetTradeHistory(), report.DefaultOptions())
+	if err != nil {
+		return warningStyle.Render(err.Error())
+	}
+	if r.Trades == 0 {
+		return infoStyle.Render("Nenhum trade registrado ainda...")
+	}
+	return r.String()
+}
+
+// formatTimeframes formata o estado de cada timeframe e suas regras de confirmação
+// formatRules mostra a regra relevante para o estado atual (entrada sem posição,
+// saída com posição) e se ela está satisfeita no preço atual
+func (m Model) formatRules(entry, exit traderbot.RuleCheck) string {
+	check, label := entry, "Entrada"
+	if m.inPosition {
+		check, label = exit, "Saída"
+	}
+
+	status := negativeStyle.Render("✗")
+	if check.Passed {
+		status = positiveStyle.Render("✓")
+	}
+	line := fmt.Sprintf("%s %s", status, check.Source)
+	if check.Err != nil {
+		line = fmt.Sprintf("… %s (%v)", check.Source, check.Err)
+	}
+	return fmt.Sprintf("Regra de %s:\n%s", label, line)
+}
+
+func formatTimeframes(statuses []traderbot.TimeframeStatus) string {
+	var b strings.Builder
+	for _, status := range statuses {
+		b.WriteString(fmt.Sprintf("%s  %s  %s\n",
+			sectionHeaderStyle.Render(fmt.Sprintf("%-4s", status.Interval)),
+			priceStyle.Render(fmt.Sprintf("$%.2f", status.Close)),
+			infoStyle.Render(fmt.Sprintf("%d candles", status.Candles)),
+		))
+		for _, rule := range status.Rules {
+			check := negativeStyle.Render("✗")
+			if !rule.Ready {
+				check = loadingStyle.Render("…")
+			} else if rule.Passed {
+				check = positiveStyle.Render("✓")
+			}
+			b.WriteString(fmt.Sprintf("  %s %s (%s)\n", check, rule.Rule, rule.Detail))
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// formatDepth formata a escada compacta do livro de ofertas (vendas em cima, compras
+// embaixo) com barras proporcionais à quantidade e o estado do filtro de liquidez
+func formatDepth(depth traderbot.DepthStatus) string {
+	if !depth.Live {
+		return warningStyle.Render("Livro de ofertas indisponível - filtro de liquidez desligado")
+	}
+	if len(depth.Bids) == 0 || len(depth.Asks) == 0 {
+		return loadingStyle.Render("Aguardando o livro de ofertas...")
+	}
+
+	const barWidth = 20
+	largest := 0.0
+	for _, level := range append(append([]traderbot.BookLevel(nil), depth.Bids...), depth.Asks...) {
+		largest = max(largest, level.Quantity)
+	}
+	row := func(level traderbot.BookLevel, style lipgloss.Style) string {
+		bar := strings.Repeat("█", max(1, int(level.Quantity/largest*barWidth)))
+		return fmt.Sprintf("%s %10.5f %s\n", style.Render(fmt.Sprintf("$%.2f", level.Price)), level.Quantity, style.Render(bar))
+	}
+
+	var b strings.Builder
+	for i := len(depth.Asks) - 1; i >= 0; i-- {
+		b.WriteString(row(depth.Asks[i], negativeStyle))
+	}
+	b.WriteString(infoStyle.Render(fmt.Sprintf("── spread %.1f bps | deslizamento da compra %.1f bps ──", depth.SpreadBps, depth.SlippageBps)) + "\n")
+	for _, level := range depth.Bids {
+		b.WriteString(row(level, positiveStyle))
+	}
+
+	if depth.Blocked != "" {
+		b.WriteString(warningStyle.Render("🚧 Entradas bloqueadas: " + depth.Blocked))
+	} else {
+		b.WriteString(positiveStyle.Render("✓ Entradas liberadas"))
+	}
+	return b.String()
+}
+
+// formatOrderFlow formata os indicadores de fluxo de ordens da janela configurada
+func formatOrderFlow(flow traderbot.OrderFlowStatus) string {
+	if !flow.Live {
+		return warningStyle.Render("Negócios agregados indisponíveis - fluxo de ordens desligado")
+	}
+	delta := positiveStyle.Render(fmt.Sprintf("%+.5f BTC", flow.Delta))
+	if flow.Delta < 0 {
+		delta = negativeStyle.Render(fmt.Sprintf("%+.5f BTC", flow.Delta))
+	}
+	window := flow.Window.String()
+	if !flow.Ready {
+		window += loadingStyle.Render(" (coletando)")
+	}
+	return fmt.Sprintf("Janela: %s | Delta: %s | CVD: %+.5f BTC\n"+
+		"Compras: %.5f BTC | Vendas: %.5f BTC | Intensidade: %.1f negócios/s | Grandes: %d (%+.5f BTC)",
+		window, delta, flow.CVD,
+		flow.BuyVolume, flow.SellVolume, flow.Intensity, flow.LargeTrades, flow.LargeDelta,
+	)
+}
+
+// formatClock retorna o aviso de relógio dessincronizado com a Binance (vazio se estiver em dia)
+func formatClock(clock traderbot.ClockStatus) string {
+	switch {
+	case !clock.Enabled:
+		return ""
+	case clock.Drift:
+		direction := "adiantado"
+		if clock.Offset < 0 {
+			direction = "atrasado"
+		}
+		return "\n" + warningStyle.Render(fmt.Sprintf("⏰ Relógio %s %s em relação à Binance",
+			clock.Offset.Abs().Round(time.Millisecond), direction))
+	case clock.Synced.IsZero() && clock.Error != "":
+		return "\n" + warningStyle.Render("⏰ Relógio não sincronizado: "+clock.Error)
+	}
+	return ""
+}
+
+// formatBreaker retorna o aviso do circuit breaker aberto (vazio com ele fechado)
+func formatBreaker(breaker traderbot.BreakerStatus) string {
+	if !breaker.Open {
+		return ""
+	}
+	until := "rearme com [b]"
+	if !breaker.RetryAt.IsZero() {
+		until = fmt.Sprintf("nova tentativa às %s ou rearme com [b]", breaker.RetryAt.Format("15:04:05"))
+	}
+	return "\n" + negativeStyle.Render(fmt.Sprintf("🔌 Compras suspensas: %d falha(s), última %s", breaker.Failures, breaker.Category)) +
+		"\n" + infoStyle.Render(until)
+}
+
+// formatDCALadder formata a escada de ordens de segurança do ciclo atual
+func (m Model) formatDCALadder() string {
+	ladder := m.trader.GetDCALadder()
+	if len(ladder) == 0 {
+		return infoStyle.Render("Nenhuma ordem de segurança configurada")
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%-4s %-9s %-12s %-12s %s\n", "#", "Desvio", "Disparo", "Valor", "Status"))
+	for _, so := range ladder {
+		status := so.Status
+		switch so.Status {
+		case traderbot.SafetyOrderFilled:
+			status = positiveStyle.Render(fmt.Sprintf("✓ %s a $%.2f", so.Status, so.FillPrice))
+		case traderbot.SafetyOrderBlocked:
+			status = warningStyle.Render("✗ " + so.Status)
+		}
+		b.WriteString(fmt.Sprintf("%-4d %-9s %-12s %-12s %s\n",
+			so.Level,
+			fmt.Sprintf("-%.2f%%", so.Deviation*100),
+			fmt.Sprintf("$%.2f", so.TriggerPrice),
+			fmt.Sprintf("$%.2f", so.Amount),
+			status,
+		))
+	}
+	b.WriteString(fmt.Sprintf("\nCapital comprometido: $%.2f USDT | Alvo: %s",
+		m.trader.GetDCACommitted(),
+		priceStyle.Render(fmt.Sprintf("$%.2f", m.trader.GetDCATakeProfitPrice())),
+	))
+	return b.String()
+}
+
+// formatGrid formata os grids com a ordem em aberto e o lucro de cada um
+func (m Model) formatGrid() string {
+	lower, upper := m.trader.GetGridRange()
+	status := positiveStyle.Render("Ativo")
+	if m.trader.IsGridPaused() {
+		status = warningStyle.Render("Pausado (preço fora da faixa)")
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Status: %s | Faixa: $%.2f - $%.2f | Lucro total: %s\n\n",
+		status, lower, upper,
+		priceStyle.Render(fmt.Sprintf("$%.4f", m.trader.GetGridProfit())),
+	))
+
+	levels := m.trader.GetGridLevels()
+	if len(levels) == 0 {
+		b.WriteString(loadingStyle.Render("Aguardando o primeiro preço para montar o grid..."))
+		return b.String()
+	}
+
+	b.WriteString(fmt.Sprintf("%-4s %-12s %-12s %-18s %-7s %s\n", "#", "Compra", "Venda", "Ordem", "Ciclos", "Lucro"))
+	// Grids do nível mais alto para o mais baixo, como num livro de ofertas
+	for i := len(levels) - 1; i >= 0; i-- {
+		level := levels[i]
+		// Preencher antes de aplicar o estilo para não desalinhar a coluna
+		order := loadingStyle.Render(fmt.Sprintf("%-18s", "aguardando"))
+		if level.OrderID != 0 {
+			if level.Side == "buy" {
+				order = positiveStyle.Render(fmt.Sprintf("%-18s", fmt.Sprintf("compra $%.2f", level.BuyPrice)))
+			} else {
+				order = warningStyle.Render(fmt.Sprintf("%-18s", fmt.Sprintf("venda $%.2f", level.SellPrice)))
+			}
+		}
+		b.WriteString(fmt.Sprintf("%-4d %-12s %-12s %s %-7d $%.4f\n",
+			level.Index,
+			fmt.Sprintf("$%.2f", level.BuyPrice),
+			fmt.Sprintf("$%.2f", level.SellPrice),
+			order,
+			level.Trips,
+			level.Profit,
+		))
+	}
+	return b.String()
+}
+
 // createPriceChart cria um gráfico ASCII simples dos últimos preços
 func createPriceChart(prices []float64, width, height int) string {
 	if len(prices) < 2 {
@@ -407,36 +726,36 @@ func (m Model) View() string {
 		Foreground(lipgloss.Color("0"))
 
 	// Renderizar abas
-	tab1Style := tabStyle
-	tab2Style := tabStyle
-	if m.currentTab == 0 {
-		tab1Style = activeTabStyle
-	} else {
-		tab2Style = activeTabStyle
+	renderedTabs := make([]string, len(tabNames))
+	for i, name := range tabNames {
+		if i == m.currentTab {
+			renderedTabs[i] = activeTabStyle.Render(name)
+		} else {
+			renderedTabs[i] = tabStyle.Render(name)
+		}
 	}
-
-	tabs := lipgloss.JoinHorizontal(
-		lipgloss.Top,
-		tab1Style.Render("Principal"),
-		tab2Style.Render("Histórico"),
-	)
+	tabs := lipgloss.JoinHorizontal(lipgloss.Top, renderedTabs...)
 
 	var content string
 	if m.currentTab == 0 {
 		// Aba Principal - Informações do Preço e Indicadores
 		var indicatorsContent string
-		if len(m.trader.GetPrices()) <= m.trader.GetMALongPeriod() {
+		candleInfo := infoStyle.Render(fmt.Sprintf("A %.2f  M %.2f  m %.2f  V %.5f",
+			m.lastCandle.Open, m.lastCandle.High, m.lastCandle.Low, m.lastCandle.Volume))
+		if m.candleCount <= m.trader.GetMALongPeriod() {
 			indicatorsContent = fmt.Sprintf(
-				"Preço BTC: %s\n%s\n%s\n%s",
+				"Preço BTC: %s\n%s\n%s\n%s\n%s",
 				priceStyle.Render(fmt.Sprintf("$%.2f", m.lastPrice)),
+				candleInfo,
 				loadingStyle.Render("RSI: Carregando..."),
 				loadingStyle.Render("MA(9): Carregando..."),
 				loadingStyle.Render("MA(21): Carregando..."),
 			)
 		} else {
 			indicatorsContent = fmt.Sprintf(
-				"Preço BTC: %s\nRSI: %s\nMA(9): %s\nMA(21): %s",
+				"Preço BTC: %s\n%s\nRSI: %s\nMA(9): %s\nMA(21): %s",
 				priceStyle.Render(fmt.Sprintf("$%.2f", m.lastPrice)),
+				candleInfo,
 				m.formatRSI(),
 				m.formatMA(9),
 				m.formatMA(21),
@@ -444,7 +763,7 @@ func (m Model) View() string {
 		}
 
 		priceInfo := sectionStyle.Copy().Width(mainPanelWidth/2 - 2).Render(
-			sectionHeaderStyle.Render("📊 Indicadores") + "\n" +
+			sectionHeaderStyle.Render(fmt.Sprintf("📊 Indicadores (%s)", m.trader.GetSignalInterval())) + "\n" +
 			indicatorsContent,
 		)
 
@@ -455,6 +774,9 @@ func (m Model) View() string {
 		} else {
 			positionStatus = warningStyle.Render("Fora do Mercado")
 		}
+		if m.trader.IsSignalsPaused() {
+			positionStatus += " " + warningStyle.Render("⏸️ Sinais pausados")
+		}
 
 		positionInfo := sectionStyle.Copy().Width(mainPanelWidth/2 - 2).Render(
 			sectionHeaderStyle.Render("💰 Carteira") + "\n" +
@@ -463,7 +785,7 @@ func (m Model) View() string {
 				positionStatus,
 				priceStyle.Render(fmt.Sprintf("%.8f", m.btcBalance)),
 				priceStyle.Render(fmt.Sprintf("%.2f", m.usdtBalance)),
-			),
+			) + formatClock(m.trader.GetClock()) + formatBreaker(m.trader.GetBreaker()),
 		)
 
 		// Junta os painéis de preço e status lado a lado
@@ -473,12 +795,27 @@ func (m Model) View() string {
 			positionInfo,
 		)
 
+		// Gráficos de preço e patrimônio lado a lado
+		chartWidth := mainPanelWidth/2 - 10
+		chartPanels := lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			sectionStyle.Copy().Width(mainPanelWidth/2-2).Render(
+				sectionHeaderStyle.Render("📉 Preço")+"\n"+
+					chartOrLoading(createPriceChart(m.trader.GetPrices(), chartWidth, 1)),
+			),
+			sectionStyle.Copy().Width(mainPanelWidth/2-2).Render(
+				sectionHeaderStyle.Render("💼 Patrimônio")+"\n"+
+					m.formatEquity(chartWidth),
+			),
+		)
+
 		// Condições de Trading
 		var conditions string
 		if !m.inPosition {
 			// Condições de compra
+			params := m.trader.GetSignalParams()
 			rsiCheck := "❌"
-			if m.rsi < 30 {
+			if m.rsi < params.RSIBuy {
 				rsiCheck = positiveStyle.Render("✓")
 			} else {
 				rsiCheck = negativeStyle.Render("✗")
@@ -493,15 +830,29 @@ func (m Model) View() string {
 
 			conditions = fmt.Sprintf(
 				"Condições de Compra:\n"+
-					"%s RSI < 30 (atual: %.2f)\n"+
-					"%s MA9 > MA21 (%.2f > %.2f)",
-				rsiCheck, m.rsi,
-				maCheck, m.maShort, m.maLong,
+					"%s RSI < %.0f (atual: %.2f)\n"+
+					"%s MA%d > MA%d (%.2f > %.2f)",
+				rsiCheck, params.RSIBuy, m.rsi,
+				maCheck, params.MAShort, params.MALong, m.maShort, m.maLong,
+			)
+		} else if m.trader.IsDCAEnabled() {
+			// Condições de venda no modo DCA
+			target := m.trader.GetDCATakeProfitPrice()
+			targetCheck := negativeStyle.Render("✗")
+			if target > 0 && m.lastPrice >= target {
+				targetCheck = positiveStyle.Render("✓")
+			}
+
+			conditions = fmt.Sprintf(
+				"Condições de Venda (DCA):\n"+
+					"%s Preço >= alvo $%.2f (preço médio $%.2f)",
+				targetCheck, target, m.entryPrice,
 			)
 		} else {
 			// Condições de venda
+			params := m.trader.GetSignalParams()
 			rsiHighCheck := "❌"
-			if m.rsi > 70 {
+			if m.rsi > params.RSISell {
 				rsiHighCheck = positiveStyle.Render("✓")
 			} else {
 				rsiHighCheck = negativeStyle.Render("✗")
@@ -518,19 +869,19 @@ func (m Model) View() string {
 			profitCheck := negativeStyle.Render("✗")
 			if m.lastPrice > 0 && m.entryPrice > 0 {
 				currentProfit = (m.lastPrice - m.entryPrice) / m.entryPrice * 100
-				if currentProfit >= 0.3 {
+				if currentProfit >= params.MinProfit {
 					profitCheck = positiveStyle.Render("✓")
 				}
 			}
 
 			conditions = fmt.Sprintf(
 				"Condições de Venda:\n"+
-					"%s RSI > 70\n"+
-					"%s MA9 < MA21 e RSI > 50\n"+
-					"%s Lucro > 0.3%% (atual: %.2f%%)",
-				rsiHighCheck,
-				maCrossCheck,
-				profitCheck,
+					"%s RSI > %.0f\n"+
+					"%s MA%d < MA%d e RSI > 50\n"+
+					"%s Lucro > %.1f%% (atual: %.2f%%)",
+				rsiHighCheck, params.RSISell,
+				maCrossCheck, params.MAShort, params.MALong,
+				profitCheck, params.MinProfit,
 				currentProfit,
 			)
 		}
@@ -540,48 +891,129 @@ func (m Model) View() string {
 			conditions,
 		)
 
-		// Logs Importantes
+		// Na estratégia por regras as condições exibidas são as próprias regras
+		if entry, exit, ok := m.trader.CheckRules(m.lastPrice); ok && !(m.inPosition && m.trader.IsDCAEnabled()) {
+			tradingConditions = sectionStyle.Copy().Render(
+				sectionHeaderStyle.Render("📜 Regras da Estratégia") + "\n" +
+					m.formatRules(entry, exit),
+			)
+		}
+
+		// Na estratégia de grid as condições do RSI/MA não se aplicam
+		if m.trader.IsGridEnabled() {
+			tradingConditions = sectionStyle.Copy().Render(
+				sectionHeaderStyle.Render("🔲 Grid") + "\n" +
+					m.formatGrid(),
+			)
+		}
+
+		// Status dos timeframes de confirmação
+		var timeframesPanel string
+		if statuses := m.trader.GetTimeframeStatus(); len(statuses) > 0 {
+			timeframesPanel = sectionStyle.Copy().Render(
+				sectionHeaderStyle.Render("⏱️ Confirmação Multi-Timeframe") + "\n" +
+					formatTimeframes(statuses),
+			)
+		}
+
+		// Livro de ofertas e filtro de liquidez
+		var depthPanel string
+		if depth := m.trader.GetDepth(); depth.Enabled {
+			depthPanel = sectionStyle.Copy().Render(
+				sectionHeaderStyle.Render("📚 Livro de Ofertas") + "\n" +
+					formatDepth(depth),
+			)
+		}
+
+		// Indicadores de fluxo de ordens
+		var flowPanel string
+		if flow := m.trader.GetOrderFlow(); flow.Enabled {
+			flowPanel = sectionStyle.Copy().Render(
+				sectionHeaderStyle.Render("🌊 Fluxo de Ordens") + "\n" +
+					formatOrderFlow(flow),
+			)
+		}
+
+		// Escada de ordens de segurança do DCA
+		var dcaPanel string
+		if m.trader.IsDCAEnabled() && m.inPosition {
+			dcaPanel = sectionStyle.Copy().Render(
+				sectionHeaderStyle.Render("🪜 Escada DCA") + "\n" +
+					m.formatDCALadder(),
+			)
+		}
+
+		// Logs filtrados pelo nível do painel
 		var logEntries string
-		if logger, ok := m.trader.GetLogger().(*traderbot.Logger); ok {
-			logs := logger.GetRecentLogs()
-			if len(logs) > 0 {
-				lastLogs := logs
-				if len(logs) > 5 {
-					lastLogs = logs[len(logs)-5:]
-				}
-				for _, log := range lastLogs {
-					logEntries += fmt.Sprintf("%s %s\n",
-						infoStyle.Render(log.Timestamp.Format("15:04:05")),
-						log.Message,
-					)
+		var logs []traderbot.LogEntry
+		for _, entry := range m.trader.GetRecentLogs() {
+			if entry.Level >= m.logLevel {
+				logs = append(logs, entry)
+			}
+		}
+		if len(logs) > 0 {
+			lastLogs := logs
+			if len(logs) > 5 {
+				lastLogs = logs[len(logs)-5:]
+			}
+			for _, log := range lastLogs {
+				message := log.String()
+				switch {
+				case log.Level >= slog.LevelError:
+					message = negativeStyle.Render(message)
+				case log.Level >= slog.LevelWarn:
+					message = warningStyle.Render(message)
 				}
-			} else {
-				logEntries = infoStyle.Render("Nenhum log importante ainda...")
+				logEntries += fmt.Sprintf("%s %s\n",
+					infoStyle.Render(log.Timestamp.Format("15:04:05")),
+					message,
+				)
 			}
+		} else {
+			logEntries = infoStyle.Render("Nenhum log neste nível ainda...")
 		}
 
 		logsPanel := sectionStyle.Copy().Render(
-			sectionHeaderStyle.Render("📝 Logs Importantes") + "\n\n" +
+			sectionHeaderStyle.Render(fmt.Sprintf("📝 Logs (%s ou acima)", m.logLevel)) + "\n\n" +
 			logEntries,
 		)
 
-		content = lipgloss.JoinVertical(
-			lipgloss.Left,
-			topPanels,
-			tradingConditions,
-			logsPanel,
-		)
-	} else {
+		panels := []string{topPanels, chartPanels, tradingConditions}
+		if timeframesPanel != "" {
+			panels = append(panels, timeframesPanel)
+		}
+		if depthPanel != "" {
+			panels = append(panels, depthPanel)
+		}
+		if flowPanel != "" {
+			panels = append(panels, flowPanel)
+		}
+		if dcaPanel != "" {
+			panels = append(panels, dcaPanel)
+		}
+		panels = append(panels, logsPanel)
+
+		content = lipgloss.JoinVertical(lipgloss.Left, panels...)
+	} else if m.currentTab == 1 {
 		// Aba de Histórico
 		m.table.SetHeight(height - 10) // Ajustar altura da tabela
 		content = sectionStyle.Copy().Render(
 			"Histórico de Trades\n\n" +
 				m.table.View(),
 		)
+	} else {
+		// Aba de Desempenho
+		content = sectionStyle.Copy().Render(
+			sectionHeaderStyle.Render("📈 Desempenho") + "\n" +
+				m.formatReport(),
+		)
 	}
 
 	// Rodapé
-	footer := infoStyle.Render("Pressione 'q' para sair | ←/→ ou h/l para mudar de aba | 'c' para configurar posição inicial")
+	footer := infoStyle.Render("Pressione 'q' para sair | ←/→ ou h/l para mudar de aba | 'c' para configurar posição inicial | 'p' pausar/retomar sinais | 'v' nível dos logs | 'X' fechar posição | 'b' rearmar circuit breaker")
+	if m.err != nil {
+		footer = warningStyle.Render(fmt.Sprintf("❌ %v", m.err)) + "\n" + footer
+	}
 
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -592,12 +1024,52 @@ func (m Model) View() string {
 	)
 }
 
+// Trader é o que a TUI precisa do bot. É implementado pelo *traderbot.BTCTrader
+// (TUI no mesmo processo) e pelo cliente remoto, que conecta a um bot em execução.
 type Trader interface {
-	GetTrades() []traderbot.Trade
-	SetInitialPosition(inPosition bool, entryPrice float64)
-	GetClient() *binance.Client
+	GetLastCandle() (traderbot.Candle, bool)
+	GetCandleCount() int
+	CalculateRSI() float64
+	CalculateMA(period int) float64
+	GetMAShortPeriod() int
+	GetMALongPeriod() int
+	GetSignalInterval() string
+	GetSignalParams() config.SignalParams
+
+	IsInPosition() bool
+	GetEntryPrice() float64
+	GetBalances() (btc float64, usdt float64, err error)
+	GetTradeHistory() []traderbot.Trade
+	GetPrices() []float64
+	GetEquityCurve() []traderbot.EquityPoint
+	GetRecentLogs() []traderbot.LogEntry
 	GetRiskPerTrade() float64
 	GetTotalFunds() float64
 	UpdateTotalFunds() error
 	GetNextTradeAmount() float64
-} 
\ No newline at end of file
+
+	IsDCAEnabled() bool
+	GetDCALadder() []traderbot.SafetyOrder
+	GetDCATakeProfitPrice() float64
+	GetDCACommitted() float64
+
+	IsGridEnabled() bool
+	IsGridPaused() bool
+	GetGridRange() (lower, upper float64)
+	GetGridLevels() []traderbot.GridLevel
+	GetGridProfit() float64
+
+	GetTimeframeStatus() []traderbot.TimeframeStatus
+	GetDepth() traderbot.DepthStatus
+	GetOrderFlow() traderbot.OrderFlowStatus
+	GetClock() traderbot.ClockStatus
+	GetBreaker() traderbot.BreakerStatus
+	ResetBreaker()
+	CheckRules(price float64) (entry, exit traderbot.RuleCheck, ok bool)
+
+	IsSignalsPaused() bool
+	PauseSignals()
+	ResumeSignals()
+	ForceClose() error
+	SetInitialPosition(inPosition bool, entryPrice float64)
+}
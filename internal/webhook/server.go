@@ -0,0 +1,168 @@
+// Package webhook recebe alertas de compra e venda de ferramentas externas (ex.
+// alertas do TradingView) e os executa pelo mesmo caminho dos sinais internos.
+//
+// Como o TradingView não permite cabeçalhos personalizados, o token pode vir no
+// cabeçalho "Authorization: Bearer <token>", no parâmetro ?token= ou no campo
+// "token" do JSON.
+package webhook
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	traderbot "github.com/casarotto/binance-bot/internal/trader-bot"
+)
+
+// tempo que um ID de alerta é lembrado para descartar repetições
+const dedupeWindow = 24 * time.Hour
+
+// tamanho máximo do corpo de um alerta
+const maxBodySize = 64 << 10
+
+// alert é o corpo aceito: o sinal mais o token opcional
+type alert struct {
+	traderbot.Signal
+	Token string `json:"token"`
+}
+
+// Server é o servidor HTTP do webhook de sinais
+type Server struct {
+	trader     *traderbot.BTCTrader
+	logger     *traderbot.Logger
+	token      string
+	maxSizePct float64
+	http       *http.Server
+
+	mu   sync.Mutex
+	seen map[string]time.Time // IDs recebidos e quando
+}
+
+// NewServer cria o servidor; maxSizePct é o maior size_pct aceito numa compra
+func NewServer(addr, token string, maxSizePct float64, trader *traderbot.BTCTrader, logger *traderbot.Logger) *Server {
+	s := &Server{
+		trader:     trader,
+		logger:     logger,
+		token:      token,
+		maxSizePct: maxSizePct,
+		seen:       make(map[string]time.Time),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /signal", s.handleSignal)
+
+	s.http = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s
+}
+
+// ListenAndServe atende os alertas até o servidor ser encerrado
+func (s *Server) ListenAndServe() error {
+	err := s.http.ListenAndServe()
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+func (s *Server) handleSignal(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("erro ao ler o alerta: %v", err))
+		return
+	}
+	var a alert
+	if err := json.Unmarshal(body, &a); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("JSON inválido: %v", err))
+		return
+	}
+
+	if !s.authorized(r, a.Token) {
+		writeError(w, http.StatusUnauthorized, fmt.Errorf("token inválido"))
+		return
+	}
+	if a.ID == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("o campo id é obrigatório"))
+		return
+	}
+	a.Symbol = strings.ToUpper(a.Symbol)
+	a.Action = strings.ToLower(a.Action)
+
+	// O ID é reservado antes da execução: um alerta repetido nunca gera uma segunda ordem
+	if !s.firstSeen(a.ID) {
+		s.logger.Infof("📡 Sinal externo %s repetido, ignorado", a.ID)
+		writeJSON(w, http.StatusOK, map[string]string{"status": "duplicate", "id": a.ID})
+		return
+	}
+
+	trade, err := s.trader.ExecuteSignal(a.Signal, s.maxSizePct)
+	// Sem ordem executada o ID é liberado para o reenvio do alerta; com resultado
+	// desconhecido ele continua reservado, para que o reenvio não duplique a ordem
+	if err != nil && !errors.Is(err, traderbot.ErrOrderUnknown) {
+		s.release(a.ID)
+	}
+	if err != nil {
+		if errors.Is(err, traderbot.ErrSignalRejected) {
+			s.logger.Warnf("⚠️ Sinal externo %s recusado: %v", a.ID, err)
+			writeError(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "executed", "id": a.ID, "trade": trade})
+}
+
+func (s *Server) authorized(r *http.Request, bodyToken string) bool {
+	token := bodyToken
+	if header := r.Header.Get("Authorization"); header != "" {
+		token = strings.TrimPrefix(header, "Bearer ")
+	} else if query := r.URL.Query().Get("token"); query != "" {
+		token = query
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+// firstSeen registra o ID e retorna false se ele já foi recebido na janela
+func (s *Server) firstSeen(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for seenID, at := range s.seen {
+		if now.Sub(at) > dedupeWindow {
+			delete(s.seen, seenID)
+		}
+	}
+	if _, ok := s.seen[id]; ok {
+		return false
+	}
+	s.seen[id] = now
+	return true
+}
+
+// release esquece o ID de um alerta que não gerou ordem
+func (s *Server) release(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.seen, id)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}